@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/internal/describe"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// runDescribe implements "pgmd describe -uri SOURCE schema.table", which
+// prints one table's columns, constraints, indexes, triggers and the
+// foreign keys that reference it.
+func runDescribe(args []string) {
+	fs := flag.NewFlagSet("describe", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	uri := fs.String("uri", "", "PostgreSQL connection URI, JSON snapshot from -format json, or pg_dump --schema-only .sql file (required)")
+	schemas := fs.String("schemas", "", "Comma-separated schemas to search for referencing tables (default: the table's own schema)")
+	colorMode := fs.String("color", "auto", "Colorize the output: auto, always or never")
+	var fetchOpts pg.FetchOptions
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd describe -uri URI [-schemas public,auth] [-color auto] schema.table")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyConfig(fs, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *uri == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	name := fs.Arg(0)
+	schema, _, ok := strings.Cut(name, ".")
+	if !ok {
+		name = "public." + name
+		schema = "public"
+	}
+
+	var color bool
+	switch *colorMode {
+	case "auto":
+		color = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	case "always":
+		color = true
+	case "never":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown -color %q (want auto, always or never)\n", *colorMode)
+		os.Exit(1)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if !slices.Contains(schemaList, schema) {
+		schemaList = append(schemaList, schema)
+	}
+
+	infos, err := loadSchemas(context.Background(), *uri, schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching schema info: %v\n", err)
+		os.Exit(1)
+	}
+
+	table, ok := describe.Find(infos, name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: table %s not found\n", name)
+		os.Exit(1)
+	}
+	if err := describe.Write(os.Stdout, infos, table, color); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
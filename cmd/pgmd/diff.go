@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sotirismorf/pgmd/internal/diff"
+	"github.com/sotirismorf/pgmd/internal/report"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// runDiff implements "pgmd diff OLD NEW", where each side is a connection
+// URI, a snapshot written by -format json or a pg_dump --schema-only file.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	schemas := fs.String("schemas", "public", "Comma-separated schema names to compare")
+	reportMode := fs.String("report", "", "Additionally publish the changelog: github-step-summary")
+	var fetchOpts pg.FetchOptions
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.Func("include-tables", "Only compare tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.IncludeTables))
+	fs.Func("exclude-tables", "Skip tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.ExcludeTables))
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd diff [-schemas public,auth] OLD NEW")
+		fmt.Fprintln(fs.Output(), "OLD and NEW are PostgreSQL URIs, JSON snapshots from -format json, or pg_dump --schema-only .sql files.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyConfig(fs, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *reportMode != "" && *reportMode != "github-step-summary" {
+		fmt.Fprintf(os.Stderr, "Error: unknown report mode %q\n", *reportMode)
+		os.Exit(1)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if len(schemaList) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no schemas specified")
+		os.Exit(1)
+	}
+
+	if err := fetchOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	old, err := loadSchemas(ctx, fs.Arg(0), schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+
+	new, err := loadSchemas(ctx, fs.Arg(1), schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fs.Arg(1), err)
+		os.Exit(1)
+	}
+
+	changelog := diff.Markdown(diff.Compare(old, new))
+	fmt.Print(changelog)
+
+	if *reportMode == "github-step-summary" {
+		if err := report.AppendGitHubStepSummary(changelog); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing step summary: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// loadSchemas introspects source if it is a connection URI, parses it as a
+// pg_dump file if it ends in .sql, and otherwise reads it as a JSON
+// snapshot, keeping only the requested schemas and tables.
+func loadSchemas(ctx context.Context, source string, schemas []string, opts pg.FetchOptions) ([]pg.SchemaInfo, error) {
+	if strings.HasPrefix(source, "postgres://") || strings.HasPrefix(source, "postgresql://") {
+		parseConfig := pgx.ParseConfig
+		if opts.ReadOnly {
+			parseConfig = pg.ReadOnlyConfig
+		}
+		config, err := parseConfig(source)
+		if err != nil {
+			return nil, err
+		}
+		setApplicationName(config.RuntimeParams, defaultApplicationName())
+		conn, err := pgx.ConnectConfig(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close(ctx)
+		opts.QueryComment = config.RuntimeParams["application_name"]
+		return pg.FetchSchemas(ctx, conn, schemas, opts)
+	}
+
+	if strings.HasSuffix(source, ".sql") {
+		schemaInfos, _, err := readDump(source, schemas, opts)
+		return schemaInfos, err
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	db, err := pg.UnmarshalDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []pg.SchemaInfo
+	for _, s := range db.Schemas {
+		if slices.Contains(schemas, s.Name) {
+			result = append(result, s)
+		}
+	}
+	return pg.FilterTables(result, opts)
+}
+
+// readDump builds the schemas and extensions recorded in a plain-format
+// pg_dump file.
+func readDump(path string, schemas []string, opts pg.FetchOptions) ([]pg.SchemaInfo, []pg.Extension, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	db, err := pg.ParseDump(f, schemas, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db.Schemas, db.Extensions, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sotirismorf/pgmd/internal/lint"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// runLint implements "pgmd lint SOURCE", where SOURCE is a connection URI
+// or a snapshot written by -format json. It exits 1 when a finding is at
+// least as severe as -fail-on, and 2 when the schema cannot be loaded.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	schemas := fs.String("schemas", "public", "Comma-separated schema names to check")
+	disable := fs.String("disable", "", "Comma-separated rules to skip")
+	failOn := fs.String("fail-on", "error", "Exit non-zero on findings of this severity or worse: warning or error")
+	listRules := fs.Bool("list-rules", false, "List the available rules and exit")
+	var fetchOpts pg.FetchOptions
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.Func("include-tables", "Only check tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.IncludeTables))
+	fs.Func("exclude-tables", "Skip tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.ExcludeTables))
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd lint [-schemas public,auth] [-disable rule,rule] [-fail-on error] SOURCE")
+		fmt.Fprintln(fs.Output(), "SOURCE is a PostgreSQL URI or a JSON snapshot from -format json.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyConfig(fs, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *listRules {
+		for _, rule := range lint.Rules {
+			fmt.Printf("%-24s %-8s %s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	threshold, err := lint.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	disabled := pg.ParseSchemas(*disable)
+	if err := lint.ValidateRules(disabled); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if len(schemaList) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no schemas specified")
+		os.Exit(2)
+	}
+
+	if err := fetchOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	infos, err := loadSchemas(context.Background(), fs.Arg(0), schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fs.Arg(0), err)
+		os.Exit(2)
+	}
+
+	failed := false
+	findings := lint.Run(infos, disabled)
+	for _, f := range findings {
+		fmt.Println(f)
+		if f.Severity.AtLeast(threshold) {
+			failed = true
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d finding(s)\n", len(findings))
+
+	if failed {
+		os.Exit(1)
+	}
+}
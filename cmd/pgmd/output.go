@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/internal/postprocess"
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+	"github.com/sotirismorf/pgmd/pkg/render"
+)
+
+// isDirOutput reports whether -o names a directory: one that exists or a
+// path ending in a separator.
+func isDirOutput(path string) bool {
+	if path == "" {
+		return false
+	}
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// writeOutput writes content to path in enc, or to stdout when path is
+// empty.
+func writeOutput(path, content string, enc render.Encoding) error {
+	content = enc.Apply(content)
+	if path == "" {
+		_, err := fmt.Print(content)
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// writeFiles writes each slash-separated relative path under dir in enc.
+func writeFiles(dir string, files map[string]string, enc render.Encoding) error {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(enc.Apply(content)), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputSettings are the flags that shape how a document is written.
+type outputSettings struct {
+	format      string
+	splitTables bool
+	hooks       []postprocess.Hook
+	encoding    render.Encoding
+	// maxFileSize, when positive, splits a single Markdown file larger
+	// than this many bytes into continuation files.
+	maxFileSize int
+}
+
+// writeDocument renders db, runs the post-processing hooks and writes it
+// to path: a file, stdout when empty, or a directory of Markdown pages. It
+// returns the rendered single-file document, before encoding, or "" for
+// directory output.
+func writeDocument(ctx context.Context, db pg.Database, opts markdown.Options, path string, out outputSettings) (string, error) {
+	if isDirOutput(path) {
+		files := markdown.RenderFiles(db, opts, out.splitTables)
+		for name, content := range files {
+			processed, err := postprocess.Run(ctx, out.hooks, "markdown", content)
+			if err != nil {
+				return "", fmt.Errorf("post-processing %s: %w", name, err)
+			}
+			files[name] = processed
+		}
+		return "", writeFiles(path, files, out.encoding)
+	}
+
+	renderer, ok := render.Lookup(out.format)
+	if !ok {
+		return "", fmt.Errorf("unknown format %q", out.format)
+	}
+	var buf strings.Builder
+	if err := renderer.Render(&buf, db, opts); err != nil {
+		return "", err
+	}
+	output, err := postprocess.Run(ctx, out.hooks, out.format, buf.String())
+	if err != nil {
+		return "", fmt.Errorf("post-processing: %w", err)
+	}
+
+	if out.maxFileSize > 0 && out.format == "markdown" && path != "" {
+		// Split the encoded document so the limit holds for the bytes
+		// written; encoding again is harmless.
+		files := markdown.SplitDocument(out.encoding.Apply(output), filepath.Base(path), out.maxFileSize)
+		return output, writeFiles(filepath.Dir(path), files, out.encoding)
+	}
+	return output, writeOutput(path, output, out.encoding)
+}
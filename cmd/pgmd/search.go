@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/sotirismorf/pgmd/internal/search"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// runSearch implements "pgmd search -uri SOURCE PATTERN", which prints
+// every object name, comment, default and definition matching the regular
+// expression PATTERN. Like grep, it exits 1 when nothing matches and 2
+// when the schema cannot be loaded.
+func runSearch(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	uri := fs.String("uri", "", "PostgreSQL connection URI, JSON snapshot from -format json, or pg_dump --schema-only .sql file (required)")
+	schemas := fs.String("schemas", "public", "Comma-separated schema names to search")
+	ignoreCase := fs.Bool("i", false, "Match case-insensitively")
+	fetchOpts := pg.FetchOptions{FunctionBodies: true}
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd search -uri URI [-schemas public,auth] [-i] PATTERN")
+		fmt.Fprintln(fs.Output(), "PATTERN is a Go regular expression.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyConfig(fs, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *uri == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	pattern := fs.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid pattern: %v\n", err)
+		os.Exit(2)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if len(schemaList) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no schemas specified")
+		os.Exit(2)
+	}
+
+	infos, err := loadSchemas(context.Background(), *uri, schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching schema info: %v\n", err)
+		os.Exit(2)
+	}
+
+	matches := search.Find(infos, re)
+	for _, m := range matches {
+		fmt.Println(m)
+	}
+	if len(matches) == 0 {
+		os.Exit(1)
+	}
+}
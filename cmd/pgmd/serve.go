@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sotirismorf/pgmd/internal/serve"
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// runServe implements "pgmd serve", which publishes the HTML document and
+// keeps it current.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	uri := fs.String("uri", "", "PostgreSQL connection URI (required)")
+	schemas := fs.String("schemas", "public", "Comma-separated schema names")
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+	interval := fs.Duration("refresh", 5*time.Minute, "Re-fetch the schema this often (0 to only refresh on POST /refresh, see -refresh-token)")
+	refreshToken := fs.String("refresh-token", os.Getenv("PGMD_REFRESH_TOKEN"), "Serve POST /refresh to requests sending \"Authorization: Bearer TOKEN\" (default: $PGMD_REFRESH_TOKEN; unset disables the endpoint)")
+	qualifyNames := fs.Bool("qualify-names", false, "Prefix object names with their schema in headings and references")
+	statementTimeout := fs.Duration("statement-timeout", 0, "Cancel any catalog query running longer than this (sets statement_timeout for pgmd's sessions)")
+	applicationName := fs.String("application-name", defaultApplicationName(), "application_name for pgmd's sessions, also prepended to every catalog query as a comment (a URI's application_name wins)")
+	workMem := fs.String("work-mem", "", "work_mem for pgmd's sessions, e.g. \"4MB\"")
+	var fetchOpts pg.FetchOptions
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.IntVar(&fetchOpts.MaxConcurrency, "max-concurrency", 0, "Introspect at most this many schemas at once (0 for all)")
+	fs.DurationVar(&fetchOpts.QueryDelay, "query-delay", 0, "Pause this long before every catalog query, to spread load on a busy server")
+	fs.Func("include-tables", "Only document tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.IncludeTables))
+	fs.Func("exclude-tables", "Skip tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.ExcludeTables))
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd serve -uri URI [-schemas public,auth] [-addr localhost:8080] [-refresh 5m]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyConfig(fs, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *uri == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if len(schemaList) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no schemas specified")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	if err := fetchOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	poolConfig, err := pgxpool.ParseConfig(*uri)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -uri: %v\n", err)
+		os.Exit(1)
+	}
+	if fetchOpts.ReadOnly {
+		pg.SetReadOnlyParams(poolConfig.ConnConfig.RuntimeParams)
+	}
+	setSessionParams(poolConfig.ConnConfig.RuntimeParams, *statementTimeout, *workMem)
+	setApplicationName(poolConfig.ConnConfig.RuntimeParams, *applicationName)
+	fetchOpts.QueryComment = poolConfig.ConnConfig.RuntimeParams["application_name"]
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+	fetcher := pg.NewFetcherFromPool(pool, fetchOpts)
+
+	source := func(ctx context.Context) (pg.Database, error) {
+		infos, err := fetcher.FetchSchemas(ctx, schemaList)
+		if err != nil {
+			return pg.Database{}, err
+		}
+		return pg.Database{Schemas: infos}, nil
+	}
+	server := serve.New(source, markdown.Options{QualifyNames: *qualifyNames}, *refreshToken)
+
+	if err := server.Refresh(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching schema info: %v\n", err)
+		os.Exit(1)
+	}
+	if *interval > 0 {
+		go server.Run(ctx, *interval)
+	}
+
+	log.Printf("serving %s on http://%s/", *schemas, *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sotirismorf/pgmd/internal/diff"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// runSnapshot implements "pgmd snapshot", which writes the canonical
+// schema document that "pgmd verify" later checks a database against.
+func runSnapshot(args []string) {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	uri := fs.String("uri", "", "PostgreSQL connection URI (required)")
+	schemas := fs.String("schemas", "public", "Comma-separated schema names")
+	output := fs.String("o", "", "Output file path (default: stdout)")
+	var fetchOpts pg.FetchOptions
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.BoolVar(&fetchOpts.Grants, "with-grants", false, "Include table, column and function privileges")
+	fs.Func("include-tables", "Only include tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.IncludeTables))
+	fs.Func("exclude-tables", "Skip tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.ExcludeTables))
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd snapshot -uri URI [-schemas public,auth] [-o schema.json]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	// -o names the document in the file, not the snapshot.
+	if err := applyConfig(fs, *configPath, "o"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *uri == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if len(schemaList) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no schemas specified")
+		os.Exit(1)
+	}
+
+	if err := fetchOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	infos, err := loadSchemas(context.Background(), *uri, schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching schema info: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := pg.MarshalSnapshot(infos)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Snapshot written to %s\n", *output)
+}
+
+// runVerify implements "pgmd verify", which compares a live database with
+// a snapshot from "pgmd snapshot". It prints the changes and exits 1 when
+// they differ, and exits 2 when either side cannot be loaded.
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	configPath := fs.String("config", "", "Read settings from this YAML or TOML file (default: ./pgmd.yaml, ./pgmd.yml or ./pgmd.toml if present)")
+	uri := fs.String("uri", "", "PostgreSQL connection URI (required)")
+	against := fs.String("against", "", "Snapshot written by pgmd snapshot (required)")
+	schemas := fs.String("schemas", "", "Comma-separated schema names (default: the snapshot's schemas)")
+	var fetchOpts pg.FetchOptions
+	fs.BoolVar(&fetchOpts.ReadOnly, "enforce-readonly", false, "Open read-only sessions and refuse any statement but SELECT")
+	fs.BoolVar(&fetchOpts.Grants, "with-grants", false, "Compare table, column and function privileges too; use when the snapshot was taken with -with-grants")
+	fs.Func("include-tables", "Only compare tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.IncludeTables))
+	fs.Func("exclude-tables", "Skip tables matching this glob, or regex with \"re:\" prefix (repeatable)", appendPattern(&fetchOpts.ExcludeTables))
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pgmd verify -uri URI -against schema.json [-schemas public,auth]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyConfig(fs, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *uri == "" || *against == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := fetchOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	schemaList := pg.ParseSchemas(*schemas)
+	if len(schemaList) == 0 {
+		names, err := snapshotSchemas(*against)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", *against, err)
+			os.Exit(2)
+		}
+		schemaList = names
+	}
+	if len(schemaList) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no schemas specified")
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	expected, err := loadSchemas(ctx, *against, schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", *against, err)
+		os.Exit(2)
+	}
+
+	live, err := loadSchemas(ctx, *uri, schemaList, fetchOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching schema info: %v\n", err)
+		os.Exit(2)
+	}
+
+	drifted, err := snapshotsDiffer(expected, live)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if !drifted {
+		fmt.Fprintf(os.Stderr, "Database matches %s\n", *against)
+		return
+	}
+
+	changes := diff.Compare(expected, live)
+	if len(changes) > 0 {
+		fmt.Print(diff.Markdown(changes))
+	} else {
+		fmt.Println("The database differs from the snapshot in details the changelog does not cover; run pgmd snapshot and diff the files.")
+	}
+	fmt.Fprintf(os.Stderr, "Database has drifted from %s\n", *against)
+	os.Exit(1)
+}
+
+// snapshotSchemas lists the schema names recorded in the snapshot at path.
+func snapshotSchemas(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := pg.UnmarshalDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range db.Schemas {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// snapshotsDiffer compares the canonical encodings of a and b, so the
+// check covers every field rather than only those diff.Compare reports.
+func snapshotsDiffer(a, b []pg.SchemaInfo) (bool, error) {
+	left, err := pg.MarshalSnapshot(a)
+	if err != nil {
+		return false, err
+	}
+	right, err := pg.MarshalSnapshot(b)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(left, right), nil
+}
@@ -2,11 +2,20 @@ module github.com/sotirismorf/pgmd
 
 go 1.24
 
-require github.com/jackc/pgx/v5 v5.7.2
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/jackc/pgx/v5 v5.7.2
+	github.com/pganalyze/pg_query_go/v6 v6.2.2
+	github.com/yuin/goldmark v1.7.13
+	google.golang.org/protobuf v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 )
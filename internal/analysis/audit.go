@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// DefaultAuditColumns is the convention checked when none is configured.
+var DefaultAuditColumns = []string{"created_at", "updated_at", "created_by"}
+
+// AuditCoverage records which of the conventional audit columns one table
+// has, in convention order.
+type AuditCoverage struct {
+	Schema  string
+	Table   string
+	Present []bool
+}
+
+// Missing returns the convention columns the table lacks.
+func (c AuditCoverage) Missing(convention []string) []string {
+	var missing []string
+	for i, ok := range c.Present {
+		if !ok {
+			missing = append(missing, convention[i])
+		}
+	}
+	return missing
+}
+
+// AuditColumns checks every table against the audit column convention.
+// Column names are compared case-insensitively.
+func AuditColumns(schemas []pg.SchemaInfo, convention []string) []AuditCoverage {
+	var result []AuditCoverage
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			cols := make(map[string]bool, len(t.Columns))
+			for _, col := range t.Columns {
+				cols[strings.ToLower(col.Name)] = true
+			}
+			cov := AuditCoverage{Schema: t.Schema, Table: t.Name, Present: make([]bool, len(convention))}
+			for i, name := range convention {
+				cov.Present[i] = cols[strings.ToLower(name)]
+			}
+			result = append(result, cov)
+		}
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestAuditColumns(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users", Columns: []pg.Column{
+					{Name: "id"}, {Name: "created_at"}, {Name: "Updated_At"},
+				}},
+				{Schema: "public", Name: "tags", Columns: []pg.Column{{Name: "id"}}},
+			},
+		},
+	}
+	convention := []string{"created_at", "updated_at", "deleted_at"}
+
+	result := AuditColumns(schemas, convention)
+
+	expected := []AuditCoverage{
+		{Schema: "public", Table: "users", Present: []bool{true, true, false}},
+		{Schema: "public", Table: "tags", Present: []bool{false, false, false}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("AuditColumns() = %+v, want %+v", result, expected)
+	}
+	if got := result[0].Missing(convention); !reflect.DeepEqual(got, []string{"deleted_at"}) {
+		t.Errorf("Missing() = %v", got)
+	}
+}
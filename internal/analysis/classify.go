@@ -0,0 +1,98 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Table classes recognized by Classify.
+const (
+	ClassAudit    = "audit"
+	ClassJunction = "junction"
+	ClassEvent    = "event"
+	ClassLookup   = "lookup"
+)
+
+// Classify guesses the role of a table from its name and structure. It
+// returns "" for ordinary entity tables or when nothing matches. Checks run
+// from the most to the least specific: audit, junction, event, lookup.
+func Classify(table pg.Table) string {
+	name := strings.ToLower(table.Name)
+	cols := make(map[string]bool, len(table.Columns))
+	fks := 0
+	for _, col := range table.Columns {
+		cols[strings.ToLower(col.Name)] = true
+		if col.FKRef != "" {
+			fks++
+		}
+	}
+	hasAny := func(names ...string) bool {
+		for _, n := range names {
+			if cols[n] {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasSuffix(name, "_audit", "_audits", "_log", "_logs", "_history", "_histories") ||
+		strings.HasPrefix(name, "audit_") || name == "audit" || name == "audit_log" ||
+		(hasAny("old_data", "old_values", "old_row") && hasAny("new_data", "new_values", "new_row")) {
+		return ClassAudit
+	}
+
+	if fks >= 2 && isJunction(table) {
+		return ClassJunction
+	}
+
+	if !hasAny("updated_at", "modified_at") &&
+		(hasSuffix(name, "_event", "_events") || name == "events") &&
+		hasAny("created_at", "occurred_at", "event_time", "timestamp", "recorded_at") {
+		return ClassEvent
+	}
+
+	if fks == 0 && len(table.Columns) > 0 && len(table.Columns) <= 4 &&
+		hasAny("code", "name", "label", "key", "slug", "value") &&
+		(hasSuffix(name, "_type", "_types", "_status", "_statuses", "_kind", "_kinds", "_category", "_categories", "_code", "_codes") ||
+			hasUniqueText(table)) {
+		return ClassLookup
+	}
+
+	return ""
+}
+
+// isJunction reports whether all the table's non-bookkeeping columns are
+// foreign keys, e.g. (order_id, product_id) plus an optional id and
+// timestamps.
+func isJunction(table pg.Table) bool {
+	for _, col := range table.Columns {
+		if col.FKRef != "" {
+			continue
+		}
+		switch strings.ToLower(col.Name) {
+		case "id", "created_at", "updated_at", "position", "sort_order":
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func hasUniqueText(table pg.Table) bool {
+	for _, col := range table.Columns {
+		if (col.IsUnique || col.IsPK) && (col.Type == "text" || col.Type == "character varying") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
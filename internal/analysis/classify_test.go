@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    pg.Table
+		expected string
+	}{
+		{
+			name: "audit by name",
+			table: pg.Table{Name: "orders_history", Columns: []pg.Column{
+				{Name: "id"}, {Name: "changed_at"},
+			}},
+			expected: ClassAudit,
+		},
+		{
+			name: "audit by old/new columns",
+			table: pg.Table{Name: "changes", Columns: []pg.Column{
+				{Name: "old_data"}, {Name: "new_data"},
+			}},
+			expected: ClassAudit,
+		},
+		{
+			name: "junction",
+			table: pg.Table{Name: "order_products", Columns: []pg.Column{
+				{Name: "order_id", FKRef: "public.orders.id", IsPK: true},
+				{Name: "product_id", FKRef: "public.products.id", IsPK: true},
+				{Name: "created_at"},
+			}},
+			expected: ClassJunction,
+		},
+		{
+			name: "two FKs with payload is not a junction",
+			table: pg.Table{Name: "orders", Columns: []pg.Column{
+				{Name: "customer_id", FKRef: "public.customers.id"},
+				{Name: "address_id", FKRef: "public.addresses.id"},
+				{Name: "total"},
+			}},
+			expected: "",
+		},
+		{
+			name: "event",
+			table: pg.Table{Name: "page_events", Columns: []pg.Column{
+				{Name: "id"}, {Name: "payload"}, {Name: "occurred_at"},
+			}},
+			expected: ClassEvent,
+		},
+		{
+			name: "lookup by suffix",
+			table: pg.Table{Name: "order_statuses", Columns: []pg.Column{
+				{Name: "code", Type: "text", IsPK: true}, {Name: "label", Type: "text"},
+			}},
+			expected: ClassLookup,
+		},
+		{
+			name: "lookup by unique text key",
+			table: pg.Table{Name: "countries", Columns: []pg.Column{
+				{Name: "id", Type: "integer", IsPK: true},
+				{Name: "code", Type: "text", IsUnique: true},
+				{Name: "name", Type: "text"},
+			}},
+			expected: ClassLookup,
+		},
+		{
+			name: "entity",
+			table: pg.Table{Name: "users", Columns: []pg.Column{
+				{Name: "id", Type: "uuid", IsPK: true},
+				{Name: "email", Type: "text", IsUnique: true},
+				{Name: "name", Type: "text"},
+				{Name: "created_at"},
+				{Name: "updated_at"},
+			}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.table); got != tt.expected {
+				t.Errorf("Classify() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
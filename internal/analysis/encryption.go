@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// EncryptedColumn is a column that appears to hold encrypted or hashed
+// data, with the reasons it was flagged.
+type EncryptedColumn struct {
+	Schema   string
+	Table    string
+	Column   string
+	Evidence []string
+}
+
+var (
+	// cryptoCall matches pgcrypto and pgsodium functions that encrypt or
+	// hash their input.
+	cryptoCall = regexp.MustCompile(`(?i)\b(pgp_sym_encrypt(_bytea)?|pgp_pub_encrypt(_bytea)?|encrypt(_iv)?|crypt|hmac|digest|pgsodium\.crypto_\w+|crypto_aead_\w+|crypto_secretbox\w*)\s*\(`)
+	// encryptedName matches column names that conventionally hold
+	// ciphertext or hashes.
+	encryptedName = regexp.MustCompile(`(?i)(^|_)(encrypted|enc|cipher(text)?|crypted|secret|password_hash|pw_hash|hashed)(_|$)`)
+	// cryptoFunctionName matches trigger functions named for encryption.
+	cryptoFunctionName = regexp.MustCompile(`(?i)(encrypt|crypt|hash)`)
+)
+
+// EncryptedColumns flags columns that look like they store encrypted data
+// using pgcrypto or pgsodium: defaults calling crypto functions, pgsodium
+// security labels, ciphertext-style names on bytea/text columns, and
+// tables with encryption-named triggers.
+func EncryptedColumns(schemas []pg.SchemaInfo) []EncryptedColumn {
+	var result []EncryptedColumn
+	for _, s := range schemas {
+		cryptoTriggers := make(map[string][]string)
+		for _, trig := range s.Triggers {
+			if cryptoFunctionName.MatchString(trig.Function) {
+				cryptoTriggers[trig.Table] = append(cryptoTriggers[trig.Table], trig.Function)
+			}
+		}
+
+		for _, t := range s.Tables {
+			for _, col := range t.Columns {
+				var evidence []string
+				if m := cryptoCall.FindStringSubmatch(col.Default); m != nil {
+					evidence = append(evidence, "default calls "+m[1]+"()")
+				}
+				if label, ok := col.SecurityLabels["pgsodium"]; ok {
+					evidence = append(evidence, "pgsodium label: "+label)
+				}
+				binaryish := col.Type == "bytea" || col.Type == "text"
+				if binaryish && encryptedName.MatchString(col.Name) {
+					evidence = append(evidence, "name suggests ciphertext")
+				}
+				if col.Type == "bytea" && len(cryptoTriggers[t.Name]) > 0 {
+					evidence = append(evidence, "bytea on table with trigger "+strings.Join(cryptoTriggers[t.Name], ", ")+"()")
+				}
+				if len(evidence) > 0 {
+					result = append(result, EncryptedColumn{Schema: t.Schema, Table: t.Name, Column: col.Name, Evidence: evidence})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Table < b.Table
+	})
+	return result
+}
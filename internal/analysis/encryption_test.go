@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestEncryptedColumns(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid"},
+						{Name: "password", Type: "text", Default: "crypt('changeme'::text, gen_salt('bf'::text))"},
+						{Name: "ssn_encrypted", Type: "bytea"},
+						{Name: "api_secret", Type: "text", SecurityLabels: map[string]string{"pgsodium": "ENCRYPT WITH KEY ID 123"}},
+						{Name: "secret_count", Type: "integer"},
+					},
+				},
+				{
+					Schema: "public",
+					Name:   "documents",
+					Columns: []pg.Column{
+						{Name: "body", Type: "bytea"},
+						{Name: "title", Type: "text"},
+					},
+				},
+			},
+			Triggers: []pg.Trigger{
+				{Schema: "public", Table: "documents", Name: "enc", Function: "encrypt_body"},
+			},
+		},
+	}
+
+	expected := []EncryptedColumn{
+		{Schema: "public", Table: "documents", Column: "body", Evidence: []string{"bytea on table with trigger encrypt_body()"}},
+		{Schema: "public", Table: "users", Column: "password", Evidence: []string{"default calls crypt()"}},
+		{Schema: "public", Table: "users", Column: "ssn_encrypted", Evidence: []string{"name suggests ciphertext"}},
+		{Schema: "public", Table: "users", Column: "api_secret", Evidence: []string{"pgsodium label: ENCRYPT WITH KEY ID 123", "name suggests ciphertext"}},
+	}
+
+	if got := EncryptedColumns(schemas); !reflect.DeepEqual(got, expected) {
+		t.Errorf("EncryptedColumns() = %+v\nwant %+v", got, expected)
+	}
+}
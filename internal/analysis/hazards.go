@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Hazard is an identifier that needs quoting or collides with a keyword.
+type Hazard struct {
+	// Object is a human-readable location such as "table public.users" or
+	// "column public.users.Order".
+	Object     string
+	Identifier string
+	Issue      string
+}
+
+const (
+	IssueReserved          = "reserved word in PostgreSQL"
+	IssueReservedElsewhere = "reserved word in other SQL dialects"
+	IssueNeedsQuoting      = "requires quoting"
+)
+
+// postgresReserved lists keywords PostgreSQL reserves outright (the
+// "reserved" category of the keyword appendix), which can't be used as
+// table or column names without quoting.
+var postgresReserved = wordSet(
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric",
+	"both", "case", "cast", "check", "collate", "column", "constraint", "create",
+	"current_catalog", "current_date", "current_role", "current_time",
+	"current_timestamp", "current_user", "default", "deferrable", "desc",
+	"distinct", "do", "else", "end", "except", "false", "fetch", "for", "foreign",
+	"from", "grant", "group", "having", "in", "initially", "intersect", "into",
+	"lateral", "leading", "limit", "localtime", "localtimestamp", "not", "null",
+	"offset", "on", "only", "or", "order", "placing", "primary", "references",
+	"returning", "select", "session_user", "some", "symmetric", "system_user",
+	"table", "then", "to", "trailing", "true", "union", "unique", "user", "using",
+	"variadic", "when", "where", "window", "with",
+)
+
+// postgresReservedNames lists the keywords PostgreSQL reserves except as
+// function or type names (the "reserved (can be function or type)"
+// category), so a table, column or schema named after one needs quoting
+// too.
+var postgresReservedNames = wordSet(
+	"authorization", "binary", "collation", "concurrently", "cross", "current_schema",
+	"freeze", "full", "ilike", "inner", "is", "isnull", "join", "left", "like",
+	"natural", "notnull", "outer", "overlaps", "right", "similar", "tablesample",
+	"verbose",
+)
+
+// otherReserved lists words that are fine in PostgreSQL but reserved in
+// MySQL, SQL Server or Oracle, and commonly trip up ORMs and ports.
+var otherReserved = wordSet(
+	"comment", "database", "date", "file", "function", "identity", "index", "key",
+	"keys", "level", "lock", "mode", "number", "option", "range", "rank", "read",
+	"row", "rows", "schema", "session", "size", "start", "status", "sum", "trigger",
+	"type", "uid", "update", "value", "values", "view",
+)
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// IdentifierHazards checks every schema, relation, column, function, type
+// and sequence name. Results are sorted by object.
+func IdentifierHazards(schemas []pg.SchemaInfo) []Hazard {
+	var hazards []Hazard
+	checkAs := func(object, ident string, functionOrType bool) {
+		if issue := identifierIssue(ident, functionOrType); issue != "" {
+			hazards = append(hazards, Hazard{Object: object, Identifier: ident, Issue: issue})
+		}
+	}
+	check := func(object, ident string) { checkAs(object, ident, false) }
+
+	for _, s := range schemas {
+		check("schema "+s.Name, s.Name)
+		for _, t := range s.Tables {
+			check("table "+t.Schema+"."+t.Name, t.Name)
+			for _, col := range t.Columns {
+				check("column "+t.Schema+"."+t.Name+"."+col.Name, col.Name)
+			}
+		}
+		for _, v := range s.Views {
+			check("view "+v.Schema+"."+v.Name, v.Name)
+			for _, col := range v.Columns {
+				check("column "+v.Schema+"."+v.Name+"."+col.Name, col.Name)
+			}
+		}
+		for _, mv := range s.MaterializedViews {
+			check("materialized view "+mv.Schema+"."+mv.Name, mv.Name)
+			for _, col := range mv.Columns {
+				check("column "+mv.Schema+"."+mv.Name+"."+col.Name, col.Name)
+			}
+		}
+		for _, seq := range s.Sequences {
+			check("sequence "+seq.Schema+"."+seq.Name, seq.Name)
+		}
+		for _, fn := range s.Functions {
+			checkAs(fn.ObjectType()+" "+fn.Schema+"."+fn.Name, fn.Name, true)
+		}
+		for _, t := range s.Types {
+			checkAs("type "+t.Schema+"."+t.Name, t.Name, true)
+		}
+	}
+
+	sort.SliceStable(hazards, func(i, j int) bool { return hazards[i].Object < hazards[j].Object })
+	return hazards
+}
+
+// identifierIssue checks ident as the name of a function or type, which
+// may be a keyword of the "can be function or type" category, or of any
+// other object.
+func identifierIssue(ident string, functionOrType bool) string {
+	if NeedsQuoting(ident) {
+		return IssueNeedsQuoting
+	}
+	if postgresReserved[ident] || postgresReservedNames[ident] && !functionOrType {
+		return IssueReserved
+	}
+	if otherReserved[ident] {
+		return IssueReservedElsewhere
+	}
+	return ""
+}
+
+// NeedsQuoting reports whether ident must be double-quoted to be used
+// verbatim: anything other than lowercase letters, digits, underscores and
+// dollar signs, or a leading digit or dollar sign.
+func NeedsQuoting(ident string) bool {
+	if ident == "" {
+		return true
+	}
+	for i, c := range ident {
+		switch {
+		case c >= 'a' && c <= 'z', c == '_':
+		case (c >= '0' && c <= '9') || c == '$':
+			if i == 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
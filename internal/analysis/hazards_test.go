@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestIdentifierHazards(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "order",
+					Columns: []pg.Column{
+						{Name: "id"},
+						{Name: "CustomerId"},
+						{Name: "type"},
+					},
+				},
+				{Schema: "public", Name: "users"},
+				{Schema: "public", Name: "left", Columns: []pg.Column{{Name: "join"}}},
+			},
+			Functions: []pg.Function{
+				{Schema: "public", Name: "do stuff"},
+				{Schema: "public", Name: "left"},
+			},
+		},
+	}
+
+	expected := []Hazard{
+		{Object: "column public.left.join", Identifier: "join", Issue: IssueReserved},
+		{Object: "column public.order.CustomerId", Identifier: "CustomerId", Issue: IssueNeedsQuoting},
+		{Object: "column public.order.type", Identifier: "type", Issue: IssueReservedElsewhere},
+		{Object: "function public.do stuff", Identifier: "do stuff", Issue: IssueNeedsQuoting},
+		{Object: "table public.left", Identifier: "left", Issue: IssueReserved},
+		{Object: "table public.order", Identifier: "order", Issue: IssueReserved},
+	}
+
+	if got := IdentifierHazards(schemas); !reflect.DeepEqual(got, expected) {
+		t.Errorf("IdentifierHazards() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestNeedsQuoting(t *testing.T) {
+	tests := []struct {
+		ident    string
+		expected bool
+	}{
+		{"users", false},
+		{"user_id2", false},
+		{"price$", false},
+		{"Users", true},
+		{"first name", true},
+		{"2fa_codes", true},
+		{"$x", true},
+		{"naïve", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if got := NeedsQuoting(tt.ident); got != tt.expected {
+			t.Errorf("NeedsQuoting(%q) = %v, want %v", tt.ident, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// TablePortability lists the PostgreSQL-specific features one table uses,
+// each with the columns or indexes involved.
+type TablePortability struct {
+	Schema   string
+	Table    string
+	Features []string
+}
+
+// postgresTypes maps udt names of PostgreSQL-specific built-in types to
+// the feature they belong to.
+var postgresTypes = map[string]string{
+	"json":      "json",
+	"jsonb":     "jsonb",
+	"int4range": "range types",
+	"int8range": "range types",
+	"numrange":  "range types",
+	"tsrange":   "range types",
+	"tstzrange": "range types",
+	"daterange": "range types",
+	"inet":      "network types",
+	"cidr":      "network types",
+	"macaddr":   "network types",
+	"macaddr8":  "network types",
+	"tsvector":  "full-text search",
+	"tsquery":   "full-text search",
+	"hstore":    "hstore",
+	"point":     "geometric types",
+	"line":      "geometric types",
+	"lseg":      "geometric types",
+	"box":       "geometric types",
+	"path":      "geometric types",
+	"polygon":   "geometric types",
+	"circle":    "geometric types",
+	"money":     "money",
+	"interval":  "interval",
+}
+
+// Portability reports PostgreSQL-specific types and features per table:
+// arrays, json/jsonb, ranges, enums, network and geometric types, and
+// partial indexes. Tables using none are omitted.
+func Portability(schemas []pg.SchemaInfo) []TablePortability {
+	enums := make(map[string]bool)
+	for _, s := range schemas {
+		for _, t := range s.Types {
+			if t.Kind == "enum" {
+				enums[t.Name] = true
+			}
+		}
+	}
+
+	var result []TablePortability
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			features := make(map[string][]string)
+			for _, col := range t.Columns {
+				if feature := columnFeature(col, enums); feature != "" {
+					features[feature] = append(features[feature], col.Name)
+				}
+			}
+			for _, idx := range t.Indexes {
+				if idx.Predicate != "" {
+					features["partial indexes"] = append(features["partial indexes"], idx.Name)
+				}
+			}
+			if len(features) == 0 {
+				continue
+			}
+
+			names := make([]string, 0, len(features))
+			for f := range features {
+				names = append(names, f)
+			}
+			sort.Strings(names)
+
+			tp := TablePortability{Schema: t.Schema, Table: t.Name}
+			for _, f := range names {
+				tp.Features = append(tp.Features, fmt.Sprintf("%s (%s)", f, strings.Join(features[f], ", ")))
+			}
+			result = append(result, tp)
+		}
+	}
+	return result
+}
+
+func columnFeature(col pg.Column, enums map[string]bool) string {
+	if col.Type == "ARRAY" || strings.HasPrefix(col.UDTName, "_") {
+		return "arrays"
+	}
+	if f, ok := postgresTypes[col.UDTName]; ok {
+		return f
+	}
+	if f, ok := postgresTypes[col.Type]; ok {
+		return f
+	}
+	if col.Type == "USER-DEFINED" && enums[col.UDTName] {
+		return "enums"
+	}
+	return ""
+}
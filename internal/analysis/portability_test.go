@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestPortability(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "events",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid", UDTName: "uuid"},
+						{Name: "tags", Type: "ARRAY", UDTName: "_text"},
+						{Name: "payload", Type: "jsonb", UDTName: "jsonb"},
+						{Name: "meta", Type: "jsonb", UDTName: "jsonb"},
+						{Name: "during", Type: "tstzrange", UDTName: "tstzrange"},
+						{Name: "status", Type: "USER-DEFINED", UDTName: "event_status"},
+					},
+					Indexes: []pg.Index{
+						{Name: "events_active_idx", Columns: []string{"id"}, Predicate: "(status = 'active'::event_status)"},
+						{Name: "events_pkey", Columns: []string{"id"}, IsPrimary: true},
+					},
+				},
+				{
+					Schema:  "public",
+					Name:    "plain",
+					Columns: []pg.Column{{Name: "id", Type: "integer", UDTName: "int4"}},
+				},
+			},
+			Types: []pg.CustomType{
+				{Schema: "public", Name: "event_status", Kind: "enum"},
+			},
+		},
+	}
+
+	expected := []TablePortability{
+		{
+			Schema: "public",
+			Table:  "events",
+			Features: []string{
+				"arrays (tags)",
+				"enums (status)",
+				"jsonb (payload, meta)",
+				"partial indexes (events_active_idx)",
+				"range types (during)",
+			},
+		},
+	}
+
+	if got := Portability(schemas); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Portability() = %+v, want %+v", got, expected)
+	}
+}
@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// SoftDeleteColumn returns the column a soft-delete predicate such as
+// "deleted_at IS NULL" or "NOT is_deleted" tests: the first identifier
+// that isn't a keyword.
+func SoftDeleteColumn(predicate string) string {
+	fields := strings.FieldsFunc(predicate, func(c rune) bool {
+		return !(c == '_' || c == '"' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'))
+	})
+	for _, f := range fields {
+		switch strings.ToLower(f) {
+		case "not", "is", "null", "true", "false", "and", "or", "coalesce":
+			continue
+		}
+		return strings.Trim(f, `"`)
+	}
+	return ""
+}
+
+// UsesSoftDelete reports whether table has the predicate's column.
+func UsesSoftDelete(table pg.Table, predicate string) bool {
+	col := SoftDeleteColumn(predicate)
+	if col == "" {
+		return false
+	}
+	for _, c := range table.Columns {
+		if c.Name == col {
+			return true
+		}
+	}
+	return false
+}
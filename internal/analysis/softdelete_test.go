@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestSoftDeleteColumn(t *testing.T) {
+	tests := []struct {
+		predicate string
+		expected  string
+	}{
+		{"deleted_at IS NULL", "deleted_at"},
+		{"NOT is_deleted", "is_deleted"},
+		{`"DeletedAt" is null`, "DeletedAt"},
+		{"archived = false", "archived"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := SoftDeleteColumn(tt.predicate); got != tt.expected {
+			t.Errorf("SoftDeleteColumn(%q) = %q, want %q", tt.predicate, got, tt.expected)
+		}
+	}
+}
+
+func TestUsesSoftDelete(t *testing.T) {
+	table := pg.Table{Name: "users", Columns: []pg.Column{{Name: "id"}, {Name: "deleted_at"}}}
+
+	if !UsesSoftDelete(table, "deleted_at IS NULL") {
+		t.Error("expected table with deleted_at to use soft delete")
+	}
+	if UsesSoftDelete(table, "NOT is_deleted") {
+		t.Error("unexpected soft delete for missing column")
+	}
+}
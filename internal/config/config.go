@@ -0,0 +1,302 @@
+// Package config loads pgmd.yaml / pgmd.toml files. Top-level keys are
+// flag names, so every flag can be set from the file; "tables", "enums",
+// "tags", "chapters" and "post_process" keys hold per-table overrides,
+// enum value descriptions, object tags, table chapters and output hooks.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sotirismorf/pgmd/internal/postprocess"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFiles are tried, in order, when no config file is named.
+var DefaultFiles = []string{"pgmd.yaml", "pgmd.yml", "pgmd.toml"}
+
+type Config struct {
+	// Flags maps flag names to their values; repeatable flags may have
+	// several.
+	Flags map[string][]string
+	// Tables maps "schema.table" to overrides applied after fetching.
+	Tables map[string]TableOverride
+	// Enums maps "schema.type" to descriptions of its values, keyed by
+	// label.
+	Enums map[string]map[string]string
+	// Tags maps "schema.object" to tags added to any table, view,
+	// sequence, function or type of that name.
+	Tags map[string][]string
+	// Chapters group tables under their own headings, in order.
+	Chapters []Chapter
+	// PostProcess rewrites the rendered output, in order, before it is
+	// written.
+	PostProcess []postprocess.Hook
+}
+
+// Chapter is a titled group of tables matching any of the Tables globs,
+// e.g. "billing_*".
+type Chapter struct {
+	Title  string   `yaml:"title" toml:"title"`
+	Tables []string `yaml:"tables" toml:"tables"`
+}
+
+func (c Chapter) validate() error {
+	if c.Title == "" {
+		return fmt.Errorf("missing title")
+	}
+	for _, p := range c.Tables {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", c.Title, p, err)
+		}
+	}
+	return nil
+}
+
+type TableOverride struct {
+	// Description replaces the table's comment.
+	Description string `yaml:"description" toml:"description"`
+	// HideColumns drops these columns from the document.
+	HideColumns []string `yaml:"hide_columns" toml:"hide_columns"`
+}
+
+// Load reads path, choosing the format by extension.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	var tables map[string]TableOverride
+	var enums map[string]map[string]string
+	var tags map[string][]string
+	var hooks []postprocess.Hook
+	var chapters []Chapter
+	switch filepath.Ext(path) {
+	case ".toml":
+		var doc struct {
+			Tables      map[string]TableOverride     `toml:"tables"`
+			Enums       map[string]map[string]string `toml:"enums"`
+			Tags        map[string][]string          `toml:"tags"`
+			PostProcess []postprocess.Hook           `toml:"post_process"`
+			Chapters    []Chapter                    `toml:"chapters"`
+		}
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tables, enums, tags, hooks, chapters = doc.Tables, doc.Enums, doc.Tags, doc.PostProcess, doc.Chapters
+	case ".yaml", ".yml":
+		var doc struct {
+			Tables      map[string]TableOverride     `yaml:"tables"`
+			Enums       map[string]map[string]string `yaml:"enums"`
+			Tags        map[string][]string          `yaml:"tags"`
+			PostProcess []postprocess.Hook           `yaml:"post_process"`
+			Chapters    []Chapter                    `yaml:"chapters"`
+		}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tables, enums, tags, hooks, chapters = doc.Tables, doc.Enums, doc.Tags, doc.PostProcess, doc.Chapters
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format (want .yaml, .yml or .toml)", path)
+	}
+
+	if err := postprocess.Compile(hooks); err != nil {
+		return nil, fmt.Errorf("%s: post_process: %w", path, err)
+	}
+
+	for _, c := range chapters {
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s: chapters: %w", path, err)
+		}
+	}
+
+	cfg := &Config{Flags: make(map[string][]string), Tables: tables, Enums: enums, Tags: tags, Chapters: chapters, PostProcess: hooks}
+	for key, value := range raw {
+		if key == "tables" || key == "enums" || key == "tags" || key == "chapters" || key == "post_process" {
+			continue
+		}
+		values, err := flagValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+		cfg.Flags[key] = values
+	}
+	return cfg, nil
+}
+
+// Find returns the first of DefaultFiles present in the working
+// directory, or "" if there is none.
+func Find() string {
+	for _, name := range DefaultFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+func flagValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case []any:
+		var values []string
+		for _, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	default:
+		s, err := scalar(v)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+}
+
+func scalar(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// Apply sets every flag from the file that was not given on the command
+// line, so flags always override the file. A list sets a repeatable flag
+// once per item, and is joined with commas for any other flag.
+func (c *Config) Apply(fs *flag.FlagSet, repeatable map[string]bool) error {
+	return c.apply(fs, repeatable, false)
+}
+
+// ApplyKnown is Apply for a subcommand's flag set, which defines only
+// some of the flags a file may set: settings for other flags are skipped
+// rather than rejected.
+func (c *Config) ApplyKnown(fs *flag.FlagSet, repeatable map[string]bool) error {
+	return c.apply(fs, repeatable, true)
+}
+
+func (c *Config) apply(fs *flag.FlagSet, repeatable map[string]bool, skipUnknown bool) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	names := make([]string, 0, len(c.Flags))
+	for name := range c.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			if skipUnknown {
+				continue
+			}
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		if explicit[name] {
+			continue
+		}
+		values := c.Flags[name]
+		if !repeatable[name] {
+			values = []string{strings.Join(values, ",")}
+		}
+		for _, v := range values {
+			if err := fs.Set(name, v); err != nil {
+				return fmt.Errorf("setting %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyOverrides rewrites the fetched model with the per-table
+// overrides, enum value descriptions and tags. File descriptions win over
+// those parsed from comments; tags are added to those from comments.
+func (c *Config) ApplyOverrides(schemas []pg.SchemaInfo) {
+	for i := range schemas {
+		c.applyTags(&schemas[i])
+		for j := range schemas[i].Types {
+			t := &schemas[i].Types[j]
+			values, ok := c.Enums[t.Schema+"."+t.Name]
+			if !ok || t.Kind != "enum" {
+				continue
+			}
+			if t.ValueDescriptions == nil {
+				t.ValueDescriptions = make(map[string]string)
+			}
+			for label, text := range values {
+				t.ValueDescriptions[label] = text
+			}
+		}
+		for j := range schemas[i].Tables {
+			t := &schemas[i].Tables[j]
+			override, ok := c.Tables[t.Schema+"."+t.Name]
+			if !ok {
+				continue
+			}
+			if override.Description != "" {
+				t.Description = override.Description
+			}
+			if len(override.HideColumns) > 0 {
+				var kept []pg.Column
+				for _, col := range t.Columns {
+					if !slices.Contains(override.HideColumns, col.Name) {
+						kept = append(kept, col)
+					}
+				}
+				t.Columns = kept
+			}
+		}
+	}
+}
+
+func (c *Config) applyTags(s *pg.SchemaInfo) {
+	if len(c.Tags) == 0 {
+		return
+	}
+	add := func(tags *[]string, name string) {
+		for _, tag := range c.Tags[s.Name+"."+name] {
+			if !slices.Contains(*tags, tag) {
+				*tags = append(*tags, tag)
+			}
+		}
+	}
+	for i := range s.Tables {
+		add(&s.Tables[i].Tags, s.Tables[i].Name)
+	}
+	for i := range s.Views {
+		add(&s.Views[i].Tags, s.Views[i].Name)
+	}
+	for i := range s.MaterializedViews {
+		add(&s.MaterializedViews[i].Tags, s.MaterializedViews[i].Name)
+	}
+	for i := range s.Sequences {
+		add(&s.Sequences[i].Tags, s.Sequences[i].Name)
+	}
+	for i := range s.Functions {
+		add(&s.Functions[i].Tags, s.Functions[i].Name)
+	}
+	for i := range s.Types {
+		add(&s.Types[i].Tags, s.Types[i].Name)
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "pgmd.yaml", `
+uri: postgres://localhost/app
+schemas: [public, auth]
+anchors: true
+exclude-tables: ["log_*", "tmp_*"]
+tables:
+  public.users:
+    description: Registered accounts
+    hide_columns: [password_hash]
+enums:
+  public.order_status:
+    pending: Awaiting payment
+tags:
+  public.active_users: [api]
+chapters:
+  - title: Billing
+    tables: ["billing_*", invoices]
+post_process:
+  - pattern: "(?m)^# (.+)$"
+    replace: "# $1 (internal)"
+  - command: npx prettier --parser html
+    formats: [html]
+`},
+		{"toml", "pgmd.toml", `
+uri = "postgres://localhost/app"
+schemas = ["public", "auth"]
+anchors = true
+exclude-tables = ["log_*", "tmp_*"]
+
+[tables."public.users"]
+description = "Registered accounts"
+hide_columns = ["password_hash"]
+
+[enums."public.order_status"]
+pending = "Awaiting payment"
+
+[tags]
+"public.active_users" = ["api"]
+
+[[chapters]]
+title = "Billing"
+tables = ["billing_*", "invoices"]
+
+[[post_process]]
+pattern = "(?m)^# (.+)$"
+replace = "# $1 (internal)"
+
+[[post_process]]
+command = "npx prettier --parser html"
+formats = ["html"]
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := map[string][]string{
+				"uri":            {"postgres://localhost/app"},
+				"schemas":        {"public", "auth"},
+				"anchors":        {"true"},
+				"exclude-tables": {"log_*", "tmp_*"},
+			}
+			if !reflect.DeepEqual(cfg.Flags, expected) {
+				t.Errorf("Flags = %v, want %v", cfg.Flags, expected)
+			}
+			override := TableOverride{Description: "Registered accounts", HideColumns: []string{"password_hash"}}
+			if !reflect.DeepEqual(cfg.Tables["public.users"], override) {
+				t.Errorf("Tables = %+v", cfg.Tables)
+			}
+			if cfg.Enums["public.order_status"]["pending"] != "Awaiting payment" {
+				t.Errorf("Enums = %v", cfg.Enums)
+			}
+			if !reflect.DeepEqual(cfg.Tags["public.active_users"], []string{"api"}) {
+				t.Errorf("Tags = %v", cfg.Tags)
+			}
+			if len(cfg.PostProcess) != 2 || cfg.PostProcess[0].Replace != "# $1 (internal)" ||
+				cfg.PostProcess[1].Command != "npx prettier --parser html" || !reflect.DeepEqual(cfg.PostProcess[1].Formats, []string{"html"}) {
+				t.Errorf("PostProcess = %+v", cfg.PostProcess)
+			}
+			chapters := []Chapter{{Title: "Billing", Tables: []string{"billing_*", "invoices"}}}
+			if !reflect.DeepEqual(cfg.Chapters, chapters) {
+				t.Errorf("Chapters = %+v, want %+v", cfg.Chapters, chapters)
+			}
+		})
+	}
+
+	if _, err := Load(writeConfig(t, "pgmd.yaml", "post_process:\n  - pattern: \"(\"\n")); err == nil {
+		t.Error("expected error for invalid post_process pattern")
+	}
+	if _, err := Load(writeConfig(t, "pgmd.yaml", "chapters:\n  - title: Billing\n    tables: [\"[a-\"]\n")); err == nil {
+		t.Error("expected error for invalid chapter pattern")
+	}
+
+	if _, err := Load(writeConfig(t, "pgmd.ini", "")); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
+
+func TestApply(t *testing.T) {
+	fs := flag.NewFlagSet("pgmd", flag.ContinueOnError)
+	uri := fs.String("uri", "", "")
+	schemas := fs.String("schemas", "public", "")
+	anchors := fs.Bool("anchors", false, "")
+	var excluded []string
+	fs.Func("exclude-tables", "", func(s string) error {
+		excluded = append(excluded, s)
+		return nil
+	})
+	if err := fs.Parse([]string{"-uri", "postgres://cli/db"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Flags: map[string][]string{
+		"uri":            {"postgres://file/db"},
+		"schemas":        {"public", "auth"},
+		"anchors":        {"true"},
+		"exclude-tables": {"log_*", "tmp_*"},
+	}}
+	if err := cfg.Apply(fs, map[string]bool{"exclude-tables": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if *uri != "postgres://cli/db" {
+		t.Errorf("command line should win, got uri %q", *uri)
+	}
+	if *schemas != "public,auth" {
+		t.Errorf("schemas = %q", *schemas)
+	}
+	if !*anchors {
+		t.Error("expected anchors from file")
+	}
+	if !reflect.DeepEqual(excluded, []string{"log_*", "tmp_*"}) {
+		t.Errorf("exclude-tables = %v", excluded)
+	}
+
+	bad := &Config{Flags: map[string][]string{"no-such-flag": {"x"}}}
+	if err := bad.Apply(fs, nil); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+}
+
+func TestApplyKnown(t *testing.T) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	uri := fs.String("uri", "", "")
+	schemas := fs.String("schemas", "public", "")
+	if err := fs.Parse([]string{"-schemas", "auth"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Flags: map[string][]string{
+		"uri":     {"postgres://file/db"},
+		"schemas": {"public", "billing"},
+		"format":  {"html"},
+	}}
+	if err := cfg.ApplyKnown(fs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if *uri != "postgres://file/db" {
+		t.Errorf("uri = %q, want the file's", *uri)
+	}
+	if *schemas != "auth" {
+		t.Errorf("command line should win, got schemas %q", *schemas)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Tables: []pg.Table{{
+			Schema:      "public",
+			Name:        "users",
+			Description: "from db",
+			Columns:     []pg.Column{{Name: "id"}, {Name: "password_hash"}},
+		}},
+		Types: []pg.CustomType{{
+			Schema:            "public",
+			Name:              "order_status",
+			Kind:              "enum",
+			Values:            []string{"pending", "paid"},
+			ValueDescriptions: map[string]string{"pending": "from comment", "paid": "Payment captured"},
+		}},
+		Views: []pg.View{{Schema: "public", Name: "active_users", Tags: []string{"internal"}}},
+	}}
+
+	cfg := &Config{Tables: map[string]TableOverride{
+		"public.users": {Description: "Registered accounts", HideColumns: []string{"password_hash"}},
+	}, Enums: map[string]map[string]string{
+		"public.order_status": {"pending": "Awaiting payment"},
+	}, Tags: map[string][]string{
+		"public.active_users": {"api", "internal"},
+	}}
+	cfg.ApplyOverrides(schemas)
+
+	users := schemas[0].Tables[0]
+	if users.Description != "Registered accounts" {
+		t.Errorf("Description = %q", users.Description)
+	}
+	if len(users.Columns) != 1 || users.Columns[0].Name != "id" {
+		t.Errorf("Columns = %+v", users.Columns)
+	}
+
+	expected := map[string]string{"pending": "Awaiting payment", "paid": "Payment captured"}
+	if got := schemas[0].Types[0].ValueDescriptions; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ValueDescriptions = %v, want %v", got, expected)
+	}
+
+	if got := schemas[0].Views[0].Tags; !reflect.DeepEqual(got, []string{"internal", "api"}) {
+		t.Errorf("view tags = %v", got)
+	}
+}
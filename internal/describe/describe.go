@@ -0,0 +1,305 @@
+// Package describe prints a terminal description of a single table, in
+// the spirit of psql's \d+.
+package describe
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// ANSI escape sequences used when color is on.
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	dim    = "\x1b[2m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	blue   = "\x1b[34m"
+	cyan   = "\x1b[36m"
+)
+
+// Find returns the table named "schema.name" in schemas.
+func Find(schemas []pg.SchemaInfo, qualified string) (pg.Table, bool) {
+	schema, name, ok := strings.Cut(qualified, ".")
+	if !ok {
+		return pg.Table{}, false
+	}
+	for _, s := range schemas {
+		if s.Name != schema {
+			continue
+		}
+		for _, t := range s.Tables {
+			if t.Name == name {
+				return t, true
+			}
+		}
+	}
+	return pg.Table{}, false
+}
+
+// Write describes table to w: its columns, indexes, constraints, the
+// foreign keys of other tables in schemas that reference it, and its
+// triggers. With color, headings and names are highlighted with ANSI
+// escapes.
+func Write(w io.Writer, schemas []pg.SchemaInfo, table pg.Table, color bool) error {
+	d := &describer{color: color}
+	d.table(schemas, table)
+	_, err := io.WriteString(w, d.sb.String())
+	return err
+}
+
+type describer struct {
+	sb    strings.Builder
+	color bool
+}
+
+// paint wraps s in the given escapes when color is on.
+func (d *describer) paint(s string, codes ...string) string {
+	if !d.color || s == "" {
+		return s
+	}
+	return strings.Join(codes, "") + s + reset
+}
+
+func (d *describer) heading(title string) {
+	fmt.Fprintf(&d.sb, "\n%s\n", d.paint(title+":", bold))
+}
+
+func (d *describer) item(format string, args ...any) {
+	fmt.Fprintf(&d.sb, "    "+format+"\n", args...)
+}
+
+func (d *describer) table(schemas []pg.SchemaInfo, table pg.Table) {
+	kind := "Table"
+	if table.PartitionStrategy != "" {
+		kind = "Partitioned table"
+	}
+	fmt.Fprintf(&d.sb, "%s %s\n", d.paint(kind, bold), d.paint(table.Schema+"."+table.Name, bold, cyan))
+	if desc := strings.TrimSpace(table.Description); desc != "" {
+		for _, line := range strings.Split(desc, "\n") {
+			fmt.Fprintf(&d.sb, "%s\n", d.paint(strings.TrimRight(line, " \t\r"), dim))
+		}
+	}
+	if table.Size != nil {
+		fmt.Fprintf(&d.sb, "%s\n", d.paint(fmt.Sprintf("%s total, ~%d rows", formatBytes(table.Size.TotalBytes), table.Size.EstimatedRows), dim))
+	}
+
+	d.columns(table.Columns)
+	d.indexes(table.Indexes)
+	d.checks(table.Checks)
+	d.foreignKeys(table.ForeignKeys)
+	d.referencedBy(schemas, table)
+	d.triggers(schemas, table)
+
+	if table.PartitionStrategy != "" {
+		d.heading("Partition key")
+		d.item("%s (%s)", table.PartitionStrategy, table.PartitionKey)
+	}
+	if len(table.Partitions) > 0 {
+		d.heading("Partitions")
+		for _, p := range table.Partitions {
+			d.item("%s %s", d.paint(p.Schema+"."+p.Name, cyan), p.Bound)
+		}
+	}
+}
+
+func (d *describer) columns(columns []pg.Column) {
+	header := []string{"Column", "Type", "Nullable", "Default", "Key", "Description"}
+	rows := [][]string{header}
+	for _, col := range columns {
+		nullable := ""
+		if !col.Nullable {
+			nullable = "not null"
+		}
+		def := col.Default
+		if col.Identity != "" {
+			def = "generated " + strings.ToLower(col.Identity) + " as identity"
+		}
+		rows = append(rows, []string{col.Name, col.DeclaredType(), nullable, def, columnKey(col), oneLine(col.Description)})
+	}
+
+	// Drop trailing columns no row fills, so narrow tables stay narrow.
+	width := len(header)
+	for width > 2 && slices.IndexFunc(rows[1:], func(r []string) bool { return r[width-1] != "" }) < 0 {
+		width--
+	}
+	widths := make([]int, width)
+	for _, row := range rows {
+		for i := range widths {
+			widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+		}
+	}
+
+	styles := [][]string{{bold}, {green}, {yellow}, {dim}, {blue}, nil}
+	d.sb.WriteString("\n")
+	for r, row := range rows {
+		var cells []string
+		for i := range widths {
+			cell := row[i]
+			if i < width-1 {
+				cell += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			}
+			switch {
+			case r == 0:
+				cell = d.paint(cell, bold, dim)
+			case len(styles[i]) > 0:
+				cell = d.paint(cell, styles[i]...)
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintf(&d.sb, " %s\n", strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+}
+
+// columnKey summarizes the keys a column takes part in.
+func columnKey(col pg.Column) string {
+	var parts []string
+	if col.IsPK {
+		parts = append(parts, "PK")
+	}
+	if col.IsUnique && !col.IsPK {
+		parts = append(parts, "UNIQUE")
+	}
+	if col.FKRef != "" {
+		parts = append(parts, "→ "+col.FKRef)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *describer) indexes(indexes []pg.Index) {
+	if len(indexes) == 0 {
+		return
+	}
+	d.heading("Indexes")
+	for _, idx := range indexes {
+		var kind string
+		switch {
+		case idx.IsPrimary:
+			kind = "PRIMARY KEY "
+		case idx.IsUnique:
+			kind = "UNIQUE "
+		}
+		line := fmt.Sprintf("%s %s(%s)", d.paint(quote(idx.Name), cyan), kind, strings.Join(idx.Columns, ", "))
+		if idx.Predicate != "" {
+			line += " WHERE " + idx.Predicate
+		}
+		d.item("%s", line)
+	}
+}
+
+func (d *describer) checks(checks []pg.CheckConstraint) {
+	if len(checks) == 0 {
+		return
+	}
+	d.heading("Check constraints")
+	for _, c := range checks {
+		d.item("%s %s", d.paint(quote(c.Name), cyan), c.Definition)
+	}
+}
+
+func (d *describer) foreignKeys(fks []pg.ForeignKey) {
+	if len(fks) == 0 {
+		return
+	}
+	d.heading("Foreign-key constraints")
+	for _, fk := range fks {
+		d.item("%s (%s) REFERENCES %s(%s)%s", d.paint(quote(fk.Name), cyan), strings.Join(fk.Columns, ", "),
+			d.paint(fk.RefSchema+"."+fk.RefTable, bold), strings.Join(fk.RefColumns, ", "), actions(fk))
+	}
+}
+
+// referencedBy lists the foreign keys of tables in schemas that point at
+// table, including its own self-references.
+func (d *describer) referencedBy(schemas []pg.SchemaInfo, table pg.Table) {
+	var lines []string
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			for _, fk := range t.ForeignKeys {
+				if fk.RefSchema != table.Schema || fk.RefTable != table.Name {
+					continue
+				}
+				lines = append(lines, fmt.Sprintf("%s %s (%s) REFERENCES (%s)%s",
+					d.paint(t.Schema+"."+t.Name, bold), d.paint(quote(fk.Name), cyan),
+					strings.Join(fk.Columns, ", "), strings.Join(fk.RefColumns, ", "), actions(fk)))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	d.heading("Referenced by")
+	for _, line := range lines {
+		d.item("%s", line)
+	}
+}
+
+func (d *describer) triggers(schemas []pg.SchemaInfo, table pg.Table) {
+	var triggers []pg.Trigger
+	for _, s := range schemas {
+		for _, trig := range s.Triggers {
+			if trig.Schema == table.Schema && trig.Table == table.Name {
+				triggers = append(triggers, trig)
+			}
+		}
+	}
+	if len(triggers) == 0 {
+		return
+	}
+	d.heading("Triggers")
+	for _, trig := range triggers {
+		fnSchema := trig.FunctionSchema
+		if fnSchema == "" {
+			fnSchema = trig.Schema
+		}
+		event := trig.EventClause()
+		if ref := trig.Referencing(); ref != "" {
+			event += " " + ref
+		}
+		if trig.Level != "" {
+			event += " FOR EACH " + trig.Level
+		}
+		if trig.When != "" {
+			event += " WHEN (" + trig.When + ")"
+		}
+		d.item("%s %s %s EXECUTE FUNCTION %s()", d.paint(quote(trig.Name), cyan), trig.Timing, event,
+			d.paint(fnSchema+"."+trig.Function, bold))
+	}
+}
+
+// actions formats a foreign key's non-default referential actions.
+func actions(fk pg.ForeignKey) string {
+	var s string
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	return s
+}
+
+func quote(name string) string {
+	return `"` + name + `"`
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,124 @@
+package describe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func testSchemas() []pg.SchemaInfo {
+	return []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:      "public",
+					Name:        "users",
+					Description: "People who sign in.",
+					Columns: []pg.Column{
+						{Name: "id", Type: "bigint", IsPK: true, Identity: "ALWAYS"},
+						{Name: "org_id", Type: "uuid", Nullable: true, FKRef: "public.orgs.id"},
+						{Name: "status", Type: "USER-DEFINED", UDTName: "user_status", Default: "'active'::user_status", Description: "Lifecycle\nstate."},
+						{Name: "tags", Type: "ARRAY", UDTName: "_text", Nullable: true},
+					},
+					Indexes: []pg.Index{
+						{Name: "users_pkey", Columns: []string{"id"}, IsPrimary: true, IsUnique: true},
+						{Name: "users_active_idx", Columns: []string{"org_id"}, Predicate: "(status = 'active')"},
+					},
+					Checks: []pg.CheckConstraint{
+						{Name: "users_id_check", Definition: "CHECK ((id > 0))"},
+					},
+					ForeignKeys: []pg.ForeignKey{
+						{Name: "users_org_id_fkey", Columns: []string{"org_id"}, RefSchema: "public", RefTable: "orgs", RefColumns: []string{"id"}, OnDelete: "SET NULL"},
+					},
+				},
+				{
+					Schema: "public",
+					Name:   "orders",
+					Columns: []pg.Column{
+						{Name: "user_id", Type: "bigint"},
+					},
+					ForeignKeys: []pg.ForeignKey{
+						{Name: "orders_user_id_fkey", Columns: []string{"user_id"}, RefSchema: "public", RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+					},
+				},
+			},
+			Triggers: []pg.Trigger{
+				{Schema: "public", Table: "users", Name: "users_touch", Timing: "BEFORE", Event: "UPDATE", Function: "touch", FunctionSchema: "util", Level: "ROW", Columns: []string{"email"}, When: "old.* IS DISTINCT FROM new.*"},
+				{Schema: "public", Table: "orders", Name: "orders_touch", Timing: "BEFORE", Event: "UPDATE", Function: "touch"},
+			},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	schemas := testSchemas()
+	table, ok := Find(schemas, "public.users")
+	if !ok {
+		t.Fatal("public.users not found")
+	}
+
+	var sb strings.Builder
+	if err := Write(&sb, schemas, table, false); err != nil {
+		t.Fatal(err)
+	}
+	result := sb.String()
+
+	tests := []string{
+		"Table public.users\nPeople who sign in.\n",
+		" Column  Type         Nullable  Default                       Key               Description\n",
+		" id      bigint       not null  generated always as identity  PK\n",
+		" org_id  uuid                                                 → public.orgs.id\n",
+		" status  user_status  not null  'active'::user_status                           Lifecycle state.\n",
+		" tags    text[]\n",
+		"Indexes:\n    \"users_pkey\" PRIMARY KEY (id)\n    \"users_active_idx\" (org_id) WHERE (status = 'active')\n",
+		"Check constraints:\n    \"users_id_check\" CHECK ((id > 0))\n",
+		"Foreign-key constraints:\n    \"users_org_id_fkey\" (org_id) REFERENCES public.orgs(id) ON DELETE SET NULL\n",
+		"Referenced by:\n    public.orders \"orders_user_id_fkey\" (user_id) REFERENCES (id) ON DELETE CASCADE\n",
+		"Triggers:\n    \"users_touch\" BEFORE UPDATE OF email FOR EACH ROW WHEN (old.* IS DISTINCT FROM new.*) EXECUTE FUNCTION util.touch()\n",
+	}
+	for _, want := range tests {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "orders_touch") {
+		t.Error("another table's trigger was listed")
+	}
+	if strings.Contains(result, "\x1b[") {
+		t.Error("escape sequences written without color")
+	}
+}
+
+func TestWrite_Color(t *testing.T) {
+	schemas := testSchemas()
+	table, _ := Find(schemas, "public.orders")
+
+	var sb strings.Builder
+	if err := Write(&sb, schemas, table, true); err != nil {
+		t.Fatal(err)
+	}
+	result := sb.String()
+
+	if !strings.Contains(result, bold+"Table"+reset+" "+bold+cyan+"public.orders"+reset) {
+		t.Errorf("expected a colored title:\n%q", result)
+	}
+	// Trailing empty columns are dropped, so this table has no Key or
+	// Description column.
+	if strings.Contains(result, "Description") {
+		t.Errorf("unexpected Description column:\n%q", result)
+	}
+}
+
+func TestFind(t *testing.T) {
+	schemas := testSchemas()
+	if _, ok := Find(schemas, "public.orders"); !ok {
+		t.Error("public.orders not found")
+	}
+	for _, name := range []string{"orders", "auth.orders", "public.missing"} {
+		if _, ok := Find(schemas, name); ok {
+			t.Errorf("Find(%q) found a table", name)
+		}
+	}
+}
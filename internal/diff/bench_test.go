@@ -0,0 +1,16 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/sotirismorf/pgmd/internal/synthetic"
+)
+
+func BenchmarkCompare(b *testing.B) {
+	old := synthetic.Schemas(2, 2000)
+	new := synthetic.Schemas(2, 2100)
+	b.ReportAllocs()
+	for b.Loop() {
+		Compare(old, new)
+	}
+}
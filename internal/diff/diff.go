@@ -0,0 +1,279 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+type Action string
+
+const (
+	Added   Action = "added"
+	Removed Action = "removed"
+	Altered Action = "altered"
+)
+
+// Change is one difference between two schema models. Object is the
+// qualified name, e.g. "public.users.email" for a column; Detail explains
+// an alteration.
+type Change struct {
+	Action Action
+	Kind   string
+	Object string
+	Detail string
+}
+
+// Compare lists the changes that turn old into new, in schema order.
+func Compare(old, new []pg.SchemaInfo) []Change {
+	var c changes
+	each(old, new, func(s pg.SchemaInfo) string { return s.Name },
+		func(s pg.SchemaInfo, a Action) { c.add(a, "schema", s.Name, "") },
+		c.schema)
+	return c.list
+}
+
+type changes struct {
+	list []Change
+}
+
+func (c *changes) add(action Action, kind, object, detail string) {
+	c.list = append(c.list, Change{Action: action, Kind: kind, Object: object, Detail: detail})
+}
+
+// each matches old and new items by key, reporting removals in old's
+// order and then additions and common items in new's order.
+func each[T any](old, new []T, key func(T) string, addedOrRemoved func(T, Action), both func(a, b T)) {
+	oldByKey := make(map[string]T, len(old))
+	for _, o := range old {
+		oldByKey[key(o)] = o
+	}
+	newKeys := make(map[string]bool, len(new))
+	for _, n := range new {
+		newKeys[key(n)] = true
+	}
+
+	for _, o := range old {
+		if !newKeys[key(o)] {
+			addedOrRemoved(o, Removed)
+		}
+	}
+	for _, n := range new {
+		if o, ok := oldByKey[key(n)]; ok {
+			both(o, n)
+		} else {
+			addedOrRemoved(n, Added)
+		}
+	}
+}
+
+func (c *changes) schema(old, new pg.SchemaInfo) {
+	qualified := func(name string) string { return new.Name + "." + name }
+
+	each(old.Tables, new.Tables, func(t pg.Table) string { return t.Name },
+		func(t pg.Table, a Action) { c.add(a, "table", qualified(t.Name), "") },
+		c.table)
+
+	each(old.Views, new.Views, func(v pg.View) string { return v.Name },
+		func(v pg.View, a Action) { c.add(a, "view", qualified(v.Name), "") },
+		func(o, n pg.View) {
+			c.columns("view column", qualified(n.Name), o.Columns, n.Columns)
+			c.altered("view", qualified(n.Name), definitionChanged(o.Definition, n.Definition))
+		})
+
+	each(old.MaterializedViews, new.MaterializedViews, func(v pg.MaterializedView) string { return v.Name },
+		func(v pg.MaterializedView, a Action) { c.add(a, "materialized view", qualified(v.Name), "") },
+		func(o, n pg.MaterializedView) {
+			c.columns("materialized view column", qualified(n.Name), o.Columns, n.Columns)
+			c.altered("materialized view", qualified(n.Name), definitionChanged(o.Definition, n.Definition))
+		})
+
+	each(old.Sequences, new.Sequences, func(s pg.Sequence) string { return s.Name },
+		func(s pg.Sequence, a Action) { c.add(a, "sequence", qualified(s.Name), "") },
+		func(o, n pg.Sequence) {
+			var details []string
+			details = field(details, "type", o.DataType, n.DataType)
+			details = field(details, "increment", fmt.Sprint(o.Increment), fmt.Sprint(n.Increment))
+			details = field(details, "range", fmt.Sprintf("[%d..%d]", o.Min, o.Max), fmt.Sprintf("[%d..%d]", n.Min, n.Max))
+			details = field(details, "cycle", fmt.Sprint(o.Cycle), fmt.Sprint(n.Cycle))
+			c.altered("sequence", qualified(n.Name), details)
+		})
+
+	each(old.Triggers, new.Triggers, func(t pg.Trigger) string { return t.Table + "." + t.Name },
+		func(t pg.Trigger, a Action) { c.add(a, "trigger", qualified(t.Table+"."+t.Name), "") },
+		func(o, n pg.Trigger) {
+			var details []string
+			details = field(details, "timing", o.Timing, n.Timing)
+			details = field(details, "event", o.Event, n.Event)
+			details = field(details, "function", o.Function, n.Function)
+			details = field(details, "columns", strings.Join(o.Columns, ", "), strings.Join(n.Columns, ", "))
+			details = field(details, "when", o.When, n.When)
+			c.altered("trigger", qualified(n.Table+"."+n.Name), details)
+		})
+
+	each(old.Functions, new.Functions, func(f pg.Function) string { return f.Name + "(" + f.Arguments + ")" },
+		func(f pg.Function, a Action) { c.add(a, f.ObjectType(), qualified(f.Name+"("+f.Arguments+")"), "") },
+		func(o, n pg.Function) {
+			var details []string
+			details = field(details, "returns", o.ReturnType, n.ReturnType)
+			details = field(details, "volatility", o.Volatility, n.Volatility)
+			details = field(details, "security definer", fmt.Sprint(o.SecurityDefiner), fmt.Sprint(n.SecurityDefiner))
+			c.altered(n.ObjectType(), qualified(n.Name+"("+n.Arguments+")"), details)
+		})
+
+	each(old.Types, new.Types, func(t pg.CustomType) string { return t.Name },
+		func(t pg.CustomType, a Action) { c.add(a, "type", qualified(t.Name), "") },
+		func(o, n pg.CustomType) {
+			var details []string
+			details = field(details, "kind", o.Kind, n.Kind)
+			details = field(details, "values", strings.Join(o.Values, ", "), strings.Join(n.Values, ", "))
+			c.altered("type", qualified(n.Name), details)
+		})
+}
+
+func (c *changes) table(old, new pg.Table) {
+	name := new.Schema + "." + new.Name
+	c.columns("column", name, old.Columns, new.Columns)
+
+	each(old.Indexes, new.Indexes, func(i pg.Index) string { return i.Name },
+		func(i pg.Index, a Action) { c.add(a, "index", name+"."+i.Name, "") },
+		func(o, n pg.Index) {
+			var details []string
+			details = field(details, "columns", strings.Join(o.Columns, ", "), strings.Join(n.Columns, ", "))
+			details = field(details, "unique", fmt.Sprint(o.IsUnique), fmt.Sprint(n.IsUnique))
+			details = field(details, "predicate", o.Predicate, n.Predicate)
+			c.altered("index", name+"."+n.Name, details)
+		})
+
+	each(old.ForeignKeys, new.ForeignKeys, func(fk pg.ForeignKey) string { return fk.Name },
+		func(fk pg.ForeignKey, a Action) { c.add(a, "foreign key", name+"."+fk.Name, "") },
+		func(o, n pg.ForeignKey) {
+			c.altered("foreign key", name+"."+n.Name, field(nil, "definition", foreignKeyDef(o), foreignKeyDef(n)))
+		})
+
+	each(old.Checks, new.Checks, func(ch pg.CheckConstraint) string { return ch.Name },
+		func(ch pg.CheckConstraint, a Action) { c.add(a, "check constraint", name+"."+ch.Name, "") },
+		func(o, n pg.CheckConstraint) {
+			c.altered("check constraint", name+"."+n.Name, field(nil, "definition", o.Definition, n.Definition))
+		})
+
+	c.altered("table", name, field(nil, "partitioned by", partitionDef(old), partitionDef(new)))
+	each(old.Partitions, new.Partitions, func(p pg.Partition) string { return p.Schema + "." + p.Name },
+		func(p pg.Partition, a Action) { c.add(a, "partition", name+"."+p.Name, "") },
+		func(o, n pg.Partition) {
+			c.altered("partition", name+"."+n.Name, field(nil, "bound", o.Bound, n.Bound))
+		})
+}
+
+func partitionDef(t pg.Table) string {
+	if t.PartitionStrategy == "" {
+		return ""
+	}
+	return t.PartitionStrategy + " (" + t.PartitionKey + ")"
+}
+
+func (c *changes) columns(kind, relation string, old, new []pg.Column) {
+	each(old, new, func(col pg.Column) string { return col.Name },
+		func(col pg.Column, a Action) { c.add(a, kind, relation+"."+col.Name, "") },
+		func(o, n pg.Column) {
+			var details []string
+			details = field(details, "type", columnType(o), columnType(n))
+			details = field(details, "nullable", fmt.Sprint(o.Nullable), fmt.Sprint(n.Nullable))
+			details = field(details, "default", o.Default, n.Default)
+			details = field(details, "identity", o.Identity, n.Identity)
+			details = field(details, "primary key", fmt.Sprint(o.IsPK), fmt.Sprint(n.IsPK))
+			c.altered(kind, relation+"."+n.Name, details)
+		})
+}
+
+func (c *changes) altered(kind, object string, details []string) {
+	if len(details) > 0 {
+		c.add(Altered, kind, object, strings.Join(details, "; "))
+	}
+}
+
+// field appends "name: old → new" when the values differ.
+func field(details []string, name, old, new string) []string {
+	if old == new {
+		return details
+	}
+	return append(details, fmt.Sprintf("%s: %s → %s", name, orNone(old), orNone(new)))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return "`" + s + "`"
+}
+
+// columnType names enums, domains and arrays by their underlying type,
+// which information_schema reports only as USER-DEFINED or ARRAY.
+func columnType(col pg.Column) string {
+	if (col.Type == "USER-DEFINED" || col.Type == "ARRAY") && col.UDTName != "" {
+		return col.UDTName
+	}
+	return col.Type
+}
+
+// definitionChanged notes a changed view query without quoting it, since
+// queries rarely fit on a line. Whitespace differences are ignored, as is
+// a side with no definition, e.g. a snapshot taken before they were read.
+func definitionChanged(old, new string) []string {
+	if old == "" || new == "" || strings.Join(strings.Fields(old), " ") == strings.Join(strings.Fields(new), " ") {
+		return nil
+	}
+	return []string{"definition changed"}
+}
+
+func foreignKeyDef(fk pg.ForeignKey) string {
+	def := fmt.Sprintf("(%s) → %s.%s (%s)", strings.Join(fk.Columns, ", "), fk.RefSchema, fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		def += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		def += " ON UPDATE " + fk.OnUpdate
+	}
+	return def
+}
+
+// Markdown renders changes as a changelog grouped by action.
+func Markdown(changes []Change) string {
+	var sb strings.Builder
+	sb.WriteString("# Schema Changes\n\n")
+
+	if len(changes) == 0 {
+		sb.WriteString("No changes.\n")
+		return sb.String()
+	}
+
+	for _, group := range []struct {
+		action  Action
+		heading string
+	}{
+		{Added, "Added"},
+		{Removed, "Removed"},
+		{Altered, "Changed"},
+	} {
+		i := slices.IndexFunc(changes, func(c Change) bool { return c.Action == group.action })
+		if i < 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "## %s\n\n", group.heading)
+		for _, c := range changes[i:] {
+			if c.Action != group.action {
+				continue
+			}
+			fmt.Fprintf(&sb, "- %s `%s`", c.Kind, c.Object)
+			if c.Detail != "" {
+				fmt.Fprintf(&sb, ": %s", c.Detail)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
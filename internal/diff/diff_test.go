@@ -0,0 +1,125 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestCompare(t *testing.T) {
+	old := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "integer", IsPK: true},
+						{Name: "email", Type: "text", Nullable: true},
+						{Name: "legacy", Type: "text"},
+					},
+					Indexes: []pg.Index{{Name: "users_email_idx", Columns: []string{"email"}}},
+				},
+				{Schema: "public", Name: "sessions"},
+				{Schema: "public", Name: "events", PartitionStrategy: "range", PartitionKey: "created_at",
+					Partitions: []pg.Partition{{Schema: "public", Name: "events_2024", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"}}},
+			},
+			Views: []pg.View{
+				{Schema: "public", Name: "active_users", Definition: "SELECT id FROM users WHERE active"},
+				{Schema: "public", Name: "admins", Definition: "SELECT id FROM users"},
+			},
+			Functions: []pg.Function{{Schema: "public", Name: "touch", Arguments: "id integer", ReturnType: "void"}},
+			Types:     []pg.CustomType{{Schema: "public", Name: "status", Kind: "enum", Values: []string{"active"}}},
+		},
+		{Name: "legacy"},
+	}
+	new := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "integer", IsPK: true},
+						{Name: "email", Type: "character varying"},
+						{Name: "role", Type: "USER-DEFINED", UDTName: "user_role"},
+					},
+					Indexes: []pg.Index{{Name: "users_email_idx", Columns: []string{"email"}, IsUnique: true}},
+				},
+				{Schema: "public", Name: "events", PartitionStrategy: "range", PartitionKey: "created_at",
+					Partitions: []pg.Partition{
+						{Schema: "public", Name: "events_2024", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"},
+						{Schema: "public", Name: "events_2025", Bound: "FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')"},
+					}},
+				{Schema: "public", Name: "orders"},
+			},
+			Views: []pg.View{
+				{Schema: "public", Name: "active_users", Definition: " SELECT id\n   FROM users\n  WHERE active"},
+				{Schema: "public", Name: "admins", Definition: "SELECT id FROM users WHERE is_admin"},
+			},
+			Functions: []pg.Function{{Schema: "public", Name: "touch", Arguments: "id integer", ReturnType: "trigger", SecurityDefiner: true}},
+			Types:     []pg.CustomType{{Schema: "public", Name: "status", Kind: "enum", Values: []string{"active", "banned"}}},
+		},
+	}
+
+	expected := []Change{
+		{Action: Removed, Kind: "schema", Object: "legacy"},
+		{Action: Removed, Kind: "table", Object: "public.sessions"},
+		{Action: Removed, Kind: "column", Object: "public.users.legacy"},
+		{Action: Altered, Kind: "column", Object: "public.users.email", Detail: "type: `text` → `character varying`; nullable: `true` → `false`"},
+		{Action: Added, Kind: "column", Object: "public.users.role"},
+		{Action: Altered, Kind: "index", Object: "public.users.users_email_idx", Detail: "unique: `false` → `true`"},
+		{Action: Added, Kind: "partition", Object: "public.events.events_2025"},
+		{Action: Added, Kind: "table", Object: "public.orders"},
+		{Action: Altered, Kind: "view", Object: "public.admins", Detail: "definition changed"},
+		{Action: Altered, Kind: "function", Object: "public.touch(id integer)", Detail: "returns: `void` → `trigger`; security definer: `false` → `true`"},
+		{Action: Altered, Kind: "type", Object: "public.status", Detail: "values: `active` → `active, banned`"},
+	}
+
+	if got := Compare(old, new); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", got, expected)
+	}
+
+	if got := Compare(new, new); len(got) != 0 {
+		t.Errorf("expected no changes comparing a model to itself, got %+v", got)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	changes := []Change{
+		{Action: Removed, Kind: "table", Object: "public.sessions"},
+		{Action: Added, Kind: "column", Object: "public.users.role"},
+		{Action: Altered, Kind: "column", Object: "public.users.email", Detail: "type: `text` → `citext`"},
+	}
+
+	result := Markdown(changes)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"added", "## Added\n\n- column `public.users.role`\n"},
+		{"removed", "## Removed\n\n- table `public.sessions`\n"},
+		{"changed", "## Changed\n\n- column `public.users.email`: type: `text` → `citext`\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+
+	if strings.Index(result, "## Added") > strings.Index(result, "## Removed") {
+		t.Error("expected Added before Removed")
+	}
+
+	if !strings.Contains(Markdown(nil), "No changes.") {
+		t.Error("expected no changes notice")
+	}
+}
@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/internal/synthetic"
+)
+
+func BenchmarkBuild(b *testing.B) {
+	for _, tables := range []int{1000, 5000} {
+		schemas := synthetic.Schemas(1, tables)
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Build(schemas)
+			}
+		})
+	}
+}
+
+func BenchmarkForeignKeyCycles(b *testing.B) {
+	schemas := synthetic.Schemas(1, 5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		ForeignKeyCycles(schemas)
+	}
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/internal/sqltext"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Call is a documented function or procedure whose body calls another.
+// Both are schema-qualified names; overloads are not told apart.
+type Call struct {
+	Caller string
+	Callee string
+}
+
+// callPattern matches a possibly qualified, possibly quoted name followed
+// by an opening parenthesis.
+var callPattern = regexp.MustCompile(`((?:"[^"]+"|[A-Za-z_][\w$]*)\s*\.\s*)?("[^"]+"|[A-Za-z_][\w$]*)\s*\(`)
+
+// FunctionCalls finds the calls between documented functions by scanning
+// the definitions fetched with FetchOptions.FunctionBodies for a
+// function's name followed by a parenthesis. Unqualified names are looked
+// up in the caller's schema, then in public, as a default search_path
+// would. Comments are skipped, but string literals are not, so the result
+// is a best guess; a function calling itself is left out, as its
+// definition names it in the CREATE statement. Calls are ordered by
+// caller, then callee.
+func FunctionCalls(schemas []pg.SchemaInfo) []Call {
+	documented := make(map[string]bool)
+	for _, s := range schemas {
+		for _, fn := range s.Functions {
+			documented[fn.Schema+"."+fn.Name] = true
+		}
+	}
+
+	seen := make(map[Call]bool)
+	var calls []Call
+	for _, s := range schemas {
+		for _, fn := range s.Functions {
+			if fn.Definition == "" {
+				continue
+			}
+			caller := fn.Schema + "." + fn.Name
+			body := sqltext.StripBodyComments(fn.Definition)
+			for _, m := range callPattern.FindAllStringSubmatchIndex(body, -1) {
+				// A match inside a longer name, such as a.b.c(…), is not
+				// a call.
+				if m[0] > 0 && isNamePart(body[m[0]-1]) {
+					continue
+				}
+				name := sqltext.Identifier(body[m[4]:m[5]])
+				var candidates []string
+				if m[2] >= 0 {
+					schema := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(body[m[2]:m[3]]), "."))
+					candidates = []string{sqltext.Identifier(schema) + "." + name}
+				} else {
+					candidates = []string{fn.Schema + "." + name, "public." + name}
+				}
+				for _, callee := range candidates {
+					if !documented[callee] {
+						continue
+					}
+					if c := (Call{Caller: caller, Callee: callee}); callee != caller && !seen[c] {
+						seen[c] = true
+						calls = append(calls, c)
+					}
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].Caller != calls[j].Caller {
+			return calls[i].Caller < calls[j].Caller
+		}
+		return calls[i].Callee < calls[j].Callee
+	})
+	return calls
+}
+
+func isNamePart(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.IndexByte(`._$"`, c) >= 0
+}
@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// SelfReference is a foreign key pointing back at its own table, as in
+// hierarchies like employees.manager_id.
+type SelfReference struct {
+	Table  string
+	Column string
+}
+
+// ForeignKeyCycles analyzes the table-level foreign key graph. Each cycle
+// is a list of schema-qualified tables in reference order, starting from
+// its alphabetically smallest member; the edge from the last table back to
+// the first is implied. Cycles spanning the same tables are reported once.
+func ForeignKeyCycles(schemas []pg.SchemaInfo) ([][]string, []SelfReference) {
+	adj := make(map[string][]string)
+	var selfRefs []SelfReference
+
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			source := t.Schema + "." + t.Name
+			if _, ok := adj[source]; !ok {
+				adj[source] = nil
+			}
+			for _, col := range t.Columns {
+				i := strings.LastIndex(col.FKRef, ".")
+				if i < 0 {
+					continue
+				}
+				target := col.FKRef[:i]
+				if target == source {
+					selfRefs = append(selfRefs, SelfReference{Table: source, Column: col.Name})
+					continue
+				}
+				adj[source] = append(adj[source], target)
+			}
+		}
+	}
+
+	var cycles [][]string
+	for _, scc := range stronglyConnected(adj) {
+		if len(scc) > 1 {
+			cycles = append(cycles, shortestCycle(adj, scc))
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return strings.Join(cycles[i], ",") < strings.Join(cycles[j], ",") })
+
+	return cycles, selfRefs
+}
+
+// stronglyConnected returns the strongly connected components of adj using
+// Tarjan's algorithm, visiting nodes in sorted order for stable results.
+func stronglyConnected(adj map[string][]string) [][]string {
+	nodes := make([]string, 0, len(adj))
+	for n := range adj {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var result [][]string
+	next := 0
+
+	var visit func(v string)
+	visit = func(v string) {
+		index[v] = next
+		low[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adj[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			var scc []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Strings(scc)
+			result = append(result, scc)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, seen := index[n]; !seen {
+			visit(n)
+		}
+	}
+	return result
+}
+
+// shortestCycle finds a shortest cycle through the smallest member of scc
+// by breadth-first search restricted to the component.
+func shortestCycle(adj map[string][]string, scc []string) []string {
+	in := make(map[string]bool, len(scc))
+	for _, n := range scc {
+		in[n] = true
+	}
+	start := scc[0]
+
+	prev := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		targets := append([]string(nil), adj[v]...)
+		sort.Strings(targets)
+		for _, w := range targets {
+			if w == start {
+				var path []string
+				for n := v; n != ""; n = prev[n] {
+					path = append([]string{n}, path...)
+				}
+				return path
+			}
+			if _, seen := prev[w]; !seen && in[w] {
+				prev[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return scc
+}
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// ExternalReference is a foreign key from a documented table to a table
+// in a schema that is not documented. Tables are schema-qualified.
+type ExternalReference struct {
+	Target        string
+	TargetColumns []string
+	Source        string
+	SourceColumns []string
+	Constraint    string
+}
+
+// ExternalReferences lists the foreign keys in schemas whose referenced
+// table lies outside them, ordered by target, then source and constraint.
+func ExternalReferences(schemas []pg.SchemaInfo) []ExternalReference {
+	var names []string
+	for _, s := range schemas {
+		names = append(names, s.Name)
+	}
+
+	var refs []ExternalReference
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			for _, fk := range t.ForeignKeys {
+				if slices.Contains(names, fk.RefSchema) {
+					continue
+				}
+				refs = append(refs, ExternalReference{
+					Target:        fk.RefSchema + "." + fk.RefTable,
+					TargetColumns: fk.RefColumns,
+					Source:        t.Schema + "." + t.Name,
+					SourceColumns: fk.Columns,
+					Constraint:    fk.Name,
+				})
+			}
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].Target != refs[j].Target {
+			return refs[i].Target < refs[j].Target
+		}
+		if refs[i].Source != refs[j].Source {
+			return refs[i].Source < refs[j].Source
+		}
+		return refs[i].Constraint < refs[j].Constraint
+	})
+	return refs
+}
@@ -0,0 +1,188 @@
+package graph
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Node kinds.
+const (
+	KindTable            = "table"
+	KindView             = "view"
+	KindMaterializedView = "materialized_view"
+	KindFunction         = "function"
+	KindRelation         = "relation"
+)
+
+// Edge kinds.
+const (
+	EdgeForeignKey = "foreign_key"
+	EdgeReads      = "reads"
+	EdgeTrigger    = "trigger"
+	EdgeCalls      = "calls"
+)
+
+type Node struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	// External marks relations referenced from the documented schemas but
+	// not documented themselves.
+	External bool `json:"external,omitempty"`
+}
+
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+	Label  string `json:"label,omitempty"`
+}
+
+// Graph is the object dependency graph in a plain nodes/edges shape that
+// visualization and lineage tools can import directly.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// NodeID returns the graph identifier of an object, e.g. "table:public.users".
+func NodeID(kind, schema, name string) string {
+	return kind + ":" + schema + "." + name
+}
+
+type builder struct {
+	graph     Graph
+	nodes     map[string]bool
+	relations map[string]string // qualified name -> node ID
+	edges     map[Edge]bool
+}
+
+// Build computes the dependency graph of the given schemas: foreign keys
+// between tables, relations read by views and materialized views, tables
+// firing trigger functions, and functions calling each other when their
+// definitions were fetched (see FunctionCalls).
+func Build(schemas []pg.SchemaInfo) Graph {
+	b := &builder{
+		graph:     Graph{Nodes: []Node{}, Edges: []Edge{}},
+		nodes:     make(map[string]bool),
+		relations: make(map[string]string),
+		edges:     make(map[Edge]bool),
+	}
+
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			b.addRelation(KindTable, t.Schema, t.Name)
+		}
+		for _, v := range s.Views {
+			b.addRelation(KindView, v.Schema, v.Name)
+		}
+		for _, mv := range s.MaterializedViews {
+			b.addRelation(KindMaterializedView, mv.Schema, mv.Name)
+		}
+		for _, fn := range s.Functions {
+			b.addNode(Node{ID: NodeID(KindFunction, fn.Schema, fn.Name), Kind: KindFunction, Schema: fn.Schema, Name: fn.Name})
+		}
+	}
+
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			source := NodeID(KindTable, t.Schema, t.Name)
+			for _, col := range t.Columns {
+				if col.FKRef == "" {
+					continue
+				}
+				i := strings.LastIndex(col.FKRef, ".")
+				if i < 0 {
+					continue
+				}
+				b.addEdge(Edge{Source: source, Target: b.relation(col.FKRef[:i]), Kind: EdgeForeignKey, Label: col.Name})
+			}
+		}
+		for _, v := range s.Views {
+			source := NodeID(KindView, v.Schema, v.Name)
+			for _, dep := range v.DependsOn {
+				b.addEdge(Edge{Source: source, Target: b.relation(dep), Kind: EdgeReads})
+			}
+		}
+		for _, mv := range s.MaterializedViews {
+			source := NodeID(KindMaterializedView, mv.Schema, mv.Name)
+			for _, dep := range mv.DependsOn {
+				b.addEdge(Edge{Source: source, Target: b.relation(dep), Kind: EdgeReads})
+			}
+		}
+		for _, trig := range s.Triggers {
+			source := b.relation(trig.Schema + "." + trig.Table)
+			target := NodeID(KindFunction, trig.Schema, trig.Function)
+			if !b.nodes[target] {
+				continue
+			}
+			b.addEdge(Edge{Source: source, Target: target, Kind: EdgeTrigger, Label: trig.Name})
+		}
+	}
+	for _, c := range FunctionCalls(schemas) {
+		callerSchema, caller, _ := strings.Cut(c.Caller, ".")
+		calleeSchema, callee, _ := strings.Cut(c.Callee, ".")
+		b.addEdge(Edge{Source: NodeID(KindFunction, callerSchema, caller), Target: NodeID(KindFunction, calleeSchema, callee), Kind: EdgeCalls})
+	}
+
+	sort.Slice(b.graph.Nodes, func(i, j int) bool { return b.graph.Nodes[i].ID < b.graph.Nodes[j].ID })
+	sort.Slice(b.graph.Edges, func(i, j int) bool {
+		a, c := b.graph.Edges[i], b.graph.Edges[j]
+		if a.Source != c.Source {
+			return a.Source < c.Source
+		}
+		if a.Target != c.Target {
+			return a.Target < c.Target
+		}
+		if a.Kind != c.Kind {
+			return a.Kind < c.Kind
+		}
+		return a.Label < c.Label
+	})
+
+	return b.graph
+}
+
+// JSON encodes the graph with indentation.
+func (g Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+func (b *builder) addNode(n Node) {
+	if b.nodes[n.ID] {
+		return
+	}
+	b.nodes[n.ID] = true
+	b.graph.Nodes = append(b.graph.Nodes, n)
+}
+
+func (b *builder) addRelation(kind, schema, name string) {
+	id := NodeID(kind, schema, name)
+	b.relations[schema+"."+name] = id
+	b.addNode(Node{ID: id, Kind: kind, Schema: schema, Name: name})
+}
+
+// relation resolves a qualified relation name to its node, creating an
+// external placeholder for relations outside the documented schemas.
+func (b *builder) relation(qualified string) string {
+	if id, ok := b.relations[qualified]; ok {
+		return id
+	}
+	schema, name, _ := strings.Cut(qualified, ".")
+	id := NodeID(KindRelation, schema, name)
+	b.relations[qualified] = id
+	b.addNode(Node{ID: id, Kind: KindRelation, Schema: schema, Name: name, External: true})
+	return id
+}
+
+func (b *builder) addEdge(e Edge) {
+	if b.edges[e] {
+		return
+	}
+	b.edges[e] = true
+	b.graph.Edges = append(b.graph.Edges, e)
+}
@@ -0,0 +1,193 @@
+package graph
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestBuild(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users"},
+				{
+					Schema: "public",
+					Name:   "posts",
+					Columns: []pg.Column{
+						{Name: "user_id", FKRef: "public.users.id"},
+						{Name: "org_id", FKRef: "auth.orgs.id"},
+					},
+				},
+			},
+			Views: []pg.View{
+				{Schema: "public", Name: "user_posts", DependsOn: []string{"public.posts", "public.users"}},
+			},
+			Triggers: []pg.Trigger{
+				{Schema: "public", Table: "posts", Name: "touch", Function: "set_updated_at"},
+			},
+			Functions: []pg.Function{
+				{Schema: "public", Name: "set_updated_at"},
+			},
+		},
+	}
+
+	g := Build(schemas)
+
+	nodes := make(map[string]Node)
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	if _, ok := nodes["table:public.users"]; !ok {
+		t.Error("expected table node not found")
+	}
+	if _, ok := nodes["view:public.user_posts"]; !ok {
+		t.Error("expected view node not found")
+	}
+	if n, ok := nodes["relation:auth.orgs"]; !ok || !n.External {
+		t.Error("expected external placeholder for undocumented FK target")
+	}
+
+	edges := make(map[Edge]bool)
+	for _, e := range g.Edges {
+		edges[e] = true
+	}
+	expected := []Edge{
+		{Source: "table:public.posts", Target: "table:public.users", Kind: EdgeForeignKey, Label: "user_id"},
+		{Source: "table:public.posts", Target: "relation:auth.orgs", Kind: EdgeForeignKey, Label: "org_id"},
+		{Source: "view:public.user_posts", Target: "table:public.posts", Kind: EdgeReads},
+		{Source: "view:public.user_posts", Target: "table:public.users", Kind: EdgeReads},
+		{Source: "table:public.posts", Target: "function:public.set_updated_at", Kind: EdgeTrigger, Label: "touch"},
+	}
+	for _, e := range expected {
+		if !edges[e] {
+			t.Errorf("expected edge %+v not found", e)
+		}
+	}
+	if len(g.Edges) != len(expected) {
+		t.Errorf("got %d edges, want %d", len(g.Edges), len(expected))
+	}
+}
+
+func TestGraph_JSON(t *testing.T) {
+	g := Build([]pg.SchemaInfo{{Name: "public", Tables: []pg.Table{{Schema: "public", Name: "users"}}}})
+
+	data, err := g.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+
+	var decoded map[string][]map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(decoded["nodes"]) != 1 || decoded["nodes"][0]["id"] != "table:public.users" {
+		t.Errorf("unexpected nodes: %v", decoded["nodes"])
+	}
+	if _, ok := decoded["edges"]; !ok {
+		t.Error("expected edges key in JSON output")
+	}
+}
+
+func TestForeignKeyCycles(t *testing.T) {
+	fk := func(name, ref string) pg.Column { return pg.Column{Name: name, FKRef: ref} }
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "employees", Columns: []pg.Column{fk("manager_id", "public.employees.id"), fk("dept_id", "public.departments.id")}},
+				{Schema: "public", Name: "departments", Columns: []pg.Column{fk("head_id", "public.employees.id")}},
+				{Schema: "public", Name: "a", Columns: []pg.Column{fk("b_id", "public.b.id")}},
+				{Schema: "public", Name: "b", Columns: []pg.Column{fk("c_id", "public.c.id")}},
+				{Schema: "public", Name: "c", Columns: []pg.Column{fk("a_id", "public.a.id")}},
+				{Schema: "public", Name: "leaf", Columns: []pg.Column{fk("a_id", "public.a.id")}},
+			},
+		},
+	}
+
+	cycles, selfRefs := ForeignKeyCycles(schemas)
+
+	expected := [][]string{
+		{"public.a", "public.b", "public.c"},
+		{"public.departments", "public.employees"},
+	}
+	if !reflect.DeepEqual(cycles, expected) {
+		t.Errorf("cycles = %v, want %v", cycles, expected)
+	}
+	if !reflect.DeepEqual(selfRefs, []SelfReference{{Table: "public.employees", Column: "manager_id"}}) {
+		t.Errorf("selfRefs = %v", selfRefs)
+	}
+}
+
+func TestExternalReferences(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "profiles", ForeignKeys: []pg.ForeignKey{
+					{Name: "profiles_user_id_fkey", Columns: []string{"user_id"}, RefSchema: "auth", RefTable: "users", RefColumns: []string{"id"}},
+					{Name: "profiles_org_id_fkey", Columns: []string{"org_id"}, RefSchema: "billing", RefTable: "orgs", RefColumns: []string{"id"}},
+				}},
+				{Schema: "public", Name: "orgs"},
+			},
+		},
+		{
+			Name: "billing",
+			Tables: []pg.Table{
+				{Schema: "billing", Name: "orgs"},
+				{Schema: "billing", Name: "invoices", ForeignKeys: []pg.ForeignKey{
+					{Name: "invoices_owner_fkey", Columns: []string{"owner_id"}, RefSchema: "auth", RefTable: "users", RefColumns: []string{"id"}},
+				}},
+			},
+		},
+	}
+
+	expected := []ExternalReference{
+		{Target: "auth.users", TargetColumns: []string{"id"}, Source: "billing.invoices", SourceColumns: []string{"owner_id"}, Constraint: "invoices_owner_fkey"},
+		{Target: "auth.users", TargetColumns: []string{"id"}, Source: "public.profiles", SourceColumns: []string{"user_id"}, Constraint: "profiles_user_id_fkey"},
+	}
+	if refs := ExternalReferences(schemas); !reflect.DeepEqual(refs, expected) {
+		t.Errorf("ExternalReferences() = %+v, want %+v", refs, expected)
+	}
+}
+
+func TestFunctionCalls(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{Schema: "public", Name: "touch", Definition: "CREATE OR REPLACE FUNCTION public.touch()\n RETURNS trigger\nAS $function$\nBEGIN\n  NEW.updated_at := now_utc();\n  PERFORM audit.log_change(TG_TABLE_NAME, coalesce(Normalize(NEW.name), ''));\n  -- stamp(NEW) is disabled\n  RETURN NEW;\nEND;\n$function$\n"},
+				{Schema: "public", Name: "now_utc", Definition: "CREATE FUNCTION public.now_utc() RETURNS timestamp AS $$ SELECT now() AT TIME ZONE 'utc' $$"},
+				{Schema: "public", Name: "normalize"},
+				{Schema: "public", Name: "stamp"},
+			},
+		},
+		{
+			Name: "audit",
+			Functions: []pg.Function{
+				{Schema: "audit", Name: "log_change", Definition: "CREATE PROCEDURE audit.log_change(t text, v text) AS $$ INSERT INTO audit.log VALUES (t, v, public.now_utc()) $$"},
+			},
+		},
+	}
+
+	expected := []Call{
+		{Caller: "audit.log_change", Callee: "public.now_utc"},
+		{Caller: "public.touch", Callee: "audit.log_change"},
+		{Caller: "public.touch", Callee: "public.normalize"},
+		{Caller: "public.touch", Callee: "public.now_utc"},
+	}
+	if calls := FunctionCalls(schemas); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("FunctionCalls() = %+v, want %+v", calls, expected)
+	}
+
+	edges := make(map[Edge]bool)
+	for _, e := range Build(schemas).Edges {
+		edges[e] = true
+	}
+	if !edges[Edge{Source: "function:public.touch", Target: "function:audit.log_change", Kind: EdgeCalls}] {
+		t.Errorf("expected a calls edge, got %+v", Build(schemas).Edges)
+	}
+}
@@ -0,0 +1,203 @@
+package lineage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+const (
+	producer       = "https://github.com/sotirismorf/pgmd"
+	jobEventURL    = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/JobEvent"
+	schemaFacetURL = "https://openlineage.io/spec/facets/1-1-1/SchemaDatasetFacet.json#/$defs/SchemaDatasetFacet"
+	jobTypeURL     = "https://openlineage.io/spec/facets/2-0-3/JobTypeJobFacet.json#/$defs/JobTypeJobFacet"
+	columnsURL     = "https://openlineage.io/spec/facets/1-0-2/ColumnLineageDatasetFacet.json#/$defs/ColumnLineageDatasetFacet"
+)
+
+// Source identifies the database the lineage was read from, following the
+// OpenLineage naming spec for Postgres: namespace postgres://host:port and
+// dataset names database.schema.table.
+type Source struct {
+	Host     string
+	Port     uint16
+	Database string
+}
+
+func (s Source) namespace() string {
+	return fmt.Sprintf("postgres://%s:%d", s.Host, s.Port)
+}
+
+// JobEvent is a static-lineage OpenLineage event. One is emitted per view
+// or materialized view, reading its base relations and writing itself.
+type JobEvent struct {
+	EventTime string    `json:"eventTime"`
+	Producer  string    `json:"producer"`
+	SchemaURL string    `json:"schemaURL"`
+	Job       Job       `json:"job"`
+	Inputs    []Dataset `json:"inputs"`
+	Outputs   []Dataset `json:"outputs"`
+}
+
+type Job struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Facets    map[string]any `json:"facets,omitempty"`
+}
+
+type Dataset struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Facets    map[string]any `json:"facets,omitempty"`
+}
+
+type facetBase struct {
+	Producer  string `json:"_producer"`
+	SchemaURL string `json:"_schemaURL"`
+}
+
+type schemaFacet struct {
+	facetBase
+	Fields []schemaField `json:"fields"`
+}
+
+type schemaField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// columnLineageFacet maps each output column to the table columns it is
+// derived from.
+type columnLineageFacet struct {
+	facetBase
+	Fields map[string]columnLineage `json:"fields"`
+}
+
+type columnLineage struct {
+	InputFields []inputField `json:"inputFields"`
+}
+
+type inputField struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Field     string `json:"field"`
+}
+
+type jobTypeFacet struct {
+	facetBase
+	ProcessingType string `json:"processingType"`
+	Integration    string `json:"integration"`
+	JobType        string `json:"jobType"`
+}
+
+// Events builds one JobEvent per view and materialized view that reads
+// from at least one relation.
+func Events(src Source, schemas []pg.SchemaInfo, now time.Time) []JobEvent {
+	var events []JobEvent
+	for _, s := range schemas {
+		for _, v := range s.Views {
+			if ev, ok := event(src, now, "VIEW", v.Schema, v.Name, v.Columns, v.DependsOn); ok {
+				events = append(events, ev)
+			}
+		}
+		for _, mv := range s.MaterializedViews {
+			if ev, ok := event(src, now, "MATERIALIZED_VIEW", mv.Schema, mv.Name, mv.Columns, mv.DependsOn); ok {
+				events = append(events, ev)
+			}
+		}
+	}
+	return events
+}
+
+// Write encodes events as newline-delimited JSON, the format accepted by
+// the OpenLineage file transport.
+func Write(w io.Writer, events []JobEvent) error {
+	enc := json.NewEncoder(w)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func event(src Source, now time.Time, jobType, schema, name string, columns []pg.Column, dependsOn []string) (JobEvent, bool) {
+	if len(dependsOn) == 0 {
+		return JobEvent{}, false
+	}
+
+	ns := src.namespace()
+	qualified := schema + "." + name
+
+	inputs := make([]Dataset, 0, len(dependsOn))
+	for _, dep := range dependsOn {
+		inputs = append(inputs, Dataset{Namespace: ns, Name: src.Database + "." + dep})
+	}
+
+	fields := make([]schemaField, 0, len(columns))
+	for _, col := range columns {
+		fields = append(fields, schemaField{Name: col.Name, Type: col.Type})
+	}
+	output := Dataset{
+		Namespace: ns,
+		Name:      src.Database + "." + qualified,
+		Facets: map[string]any{
+			"schema": schemaFacet{
+				facetBase: facetBase{Producer: producer, SchemaURL: schemaFacetURL},
+				Fields:    fields,
+			},
+		},
+	}
+
+	lineage := make(map[string]columnLineage)
+	for _, col := range columns {
+		var in []inputField
+		for _, from := range col.DerivedFrom {
+			relation, field, ok := cutLast(from)
+			if ok {
+				in = append(in, inputField{Namespace: ns, Name: src.Database + "." + relation, Field: field})
+			}
+		}
+		if len(in) > 0 {
+			lineage[col.Name] = columnLineage{InputFields: in}
+		}
+	}
+	if len(lineage) > 0 {
+		output.Facets["columnLineage"] = columnLineageFacet{
+			facetBase: facetBase{Producer: producer, SchemaURL: columnsURL},
+			Fields:    lineage,
+		}
+	}
+
+	return JobEvent{
+		EventTime: now.UTC().Format(time.RFC3339),
+		Producer:  producer,
+		SchemaURL: jobEventURL,
+		Job: Job{
+			Namespace: ns,
+			Name:      src.Database + "." + qualified,
+			Facets: map[string]any{
+				"jobType": jobTypeFacet{
+					facetBase:      facetBase{Producer: producer, SchemaURL: jobTypeURL},
+					ProcessingType: "BATCH",
+					Integration:    "POSTGRES",
+					JobType:        jobType,
+				},
+			},
+		},
+		Inputs:  inputs,
+		Outputs: []Dataset{output},
+	}, true
+}
+
+// cutLast splits "schema.table.column" into "schema.table" and "column".
+func cutLast(s string) (before, after string, ok bool) {
+	i := strings.LastIndexByte(s, '.')
+	if i < 0 {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
@@ -0,0 +1,96 @@
+package lineage
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestEvents(t *testing.T) {
+	src := Source{Host: "db.internal", Port: 5432, Database: "app"}
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Views: []pg.View{
+				{
+					Schema:    "public",
+					Name:      "active_users",
+					Columns:   []pg.Column{{Name: "id", Type: "uuid", DerivedFrom: []string{"public.users.id"}}},
+					DependsOn: []string{"public.users"},
+				},
+				{Schema: "public", Name: "constants"},
+			},
+			MaterializedViews: []pg.MaterializedView{
+				{Schema: "public", Name: "user_stats", DependsOn: []string{"public.posts", "public.users"}},
+			},
+		},
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	events := Events(src, schemas, now)
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2 (views without dependencies are skipped)", len(events))
+	}
+	ev := events[0]
+	if ev.Job.Namespace != "postgres://db.internal:5432" || ev.Job.Name != "app.public.active_users" {
+		t.Errorf("unexpected job %s/%s", ev.Job.Namespace, ev.Job.Name)
+	}
+	if len(ev.Inputs) != 1 || ev.Inputs[0].Name != "app.public.users" {
+		t.Errorf("unexpected inputs %+v", ev.Inputs)
+	}
+	if len(ev.Outputs) != 1 || ev.Outputs[0].Name != "app.public.active_users" {
+		t.Errorf("unexpected outputs %+v", ev.Outputs)
+	}
+	facet, ok := ev.Outputs[0].Facets["columnLineage"].(columnLineageFacet)
+	if !ok || len(facet.Fields["id"].InputFields) != 1 || facet.Fields["id"].InputFields[0] != (inputField{Namespace: "postgres://db.internal:5432", Name: "app.public.users", Field: "id"}) {
+		t.Errorf("unexpected column lineage %+v", ev.Outputs[0].Facets["columnLineage"])
+	}
+	if _, ok := events[1].Outputs[0].Facets["columnLineage"]; ok {
+		t.Error("column lineage facet without derived columns")
+	}
+	if ev.EventTime != "2024-01-02T03:04:05Z" {
+		t.Errorf("EventTime = %q", ev.EventTime)
+	}
+	if len(events[1].Inputs) != 2 {
+		t.Errorf("expected materialized view event with 2 inputs, got %+v", events[1].Inputs)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	src := Source{Host: "localhost", Port: 5432, Database: "app"}
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Views: []pg.View{
+				{Schema: "public", Name: "a", DependsOn: []string{"public.t"}},
+				{Schema: "public", Name: "b", DependsOn: []string{"public.t"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Events(src, schemas, time.Now())); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded["schemaURL"] != jobEventURL {
+		t.Errorf("schemaURL = %v", decoded["schemaURL"])
+	}
+	facets := decoded["outputs"].([]any)[0].(map[string]any)["facets"].(map[string]any)
+	if _, ok := facets["schema"].(map[string]any)["_producer"]; !ok {
+		t.Error("expected flattened facet base fields")
+	}
+}
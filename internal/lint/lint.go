@@ -0,0 +1,158 @@
+// Package lint checks a fetched schema model against hygiene rules.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+type Severity string
+
+const (
+	Warning Severity = "warning"
+	Error   Severity = "error"
+)
+
+// ParseSeverity validates a -fail-on flag value.
+func ParseSeverity(input string) (Severity, error) {
+	switch Severity(input) {
+	case Warning, Error:
+		return Severity(input), nil
+	}
+	return "", fmt.Errorf("unknown severity %q (want warning or error)", input)
+}
+
+// AtLeast reports whether s is as severe as other.
+func (s Severity) AtLeast(other Severity) bool {
+	return s == Error || other == Warning
+}
+
+// Finding is one rule violation. Object is the qualified name of the
+// offending table, column or view.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Object   string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", f.Severity, f.Object, f.Message, f.Rule)
+}
+
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	check       func(pg.SchemaInfo) []Finding
+}
+
+// Rules are all available rules, in report order.
+var Rules = []Rule{
+	{"no-primary-key", "Tables without a primary key", Error, noPrimaryKey},
+	{"unindexed-foreign-key", "Foreign keys whose columns do not lead any index", Warning, unindexedForeignKey},
+	{"naming", "Table and column names that are not lower snake_case", Warning, naming},
+	{"missing-comment", "Tables and views without a comment", Warning, missingComment},
+}
+
+// Run applies every rule not named in disabled to schemas.
+func Run(schemas []pg.SchemaInfo, disabled []string) []Finding {
+	var findings []Finding
+	for _, rule := range Rules {
+		if slices.Contains(disabled, rule.Name) {
+			continue
+		}
+		for _, s := range schemas {
+			for _, f := range rule.check(s) {
+				f.Rule, f.Severity = rule.Name, rule.Severity
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// ValidateRules reports the first name that is not a known rule.
+func ValidateRules(names []string) error {
+	for _, name := range names {
+		if !slices.ContainsFunc(Rules, func(r Rule) bool { return r.Name == name }) {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+	return nil
+}
+
+func noPrimaryKey(s pg.SchemaInfo) []Finding {
+	var findings []Finding
+	for _, t := range s.Tables {
+		if !slices.ContainsFunc(t.Columns, func(c pg.Column) bool { return c.IsPK }) {
+			findings = append(findings, Finding{Object: s.Name + "." + t.Name, Message: "table has no primary key"})
+		}
+	}
+	return findings
+}
+
+// unindexedForeignKey flags foreign keys that no index can serve, which
+// makes deletes on the referenced table scan the referencing one.
+func unindexedForeignKey(s pg.SchemaInfo) []Finding {
+	var findings []Finding
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			covered := slices.ContainsFunc(t.Indexes, func(idx pg.Index) bool {
+				return len(idx.Columns) >= len(fk.Columns) && sameSet(idx.Columns[:len(fk.Columns)], fk.Columns)
+			})
+			if !covered {
+				findings = append(findings, Finding{
+					Object:  s.Name + "." + t.Name,
+					Message: fmt.Sprintf("foreign key %s (%s) has no supporting index", fk.Name, strings.Join(fk.Columns, ", ")),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func sameSet(a, b []string) bool {
+	for _, x := range a {
+		if !slices.Contains(b, x) {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+var snakeCase = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+func naming(s pg.SchemaInfo) []Finding {
+	var findings []Finding
+	for _, t := range s.Tables {
+		if !snakeCase.MatchString(t.Name) {
+			findings = append(findings, Finding{Object: s.Name + "." + t.Name, Message: "table name is not lower snake_case"})
+		}
+		for _, c := range t.Columns {
+			if !snakeCase.MatchString(c.Name) {
+				findings = append(findings, Finding{Object: s.Name + "." + t.Name + "." + c.Name, Message: "column name is not lower snake_case"})
+			}
+		}
+	}
+	return findings
+}
+
+func missingComment(s pg.SchemaInfo) []Finding {
+	var findings []Finding
+	for _, t := range s.Tables {
+		if strings.TrimSpace(t.Description) == "" {
+			findings = append(findings, Finding{Object: s.Name + "." + t.Name, Message: "table has no comment"})
+		}
+	}
+	for _, v := range s.Views {
+		if strings.TrimSpace(v.Description) == "" {
+			findings = append(findings, Finding{Object: s.Name + "." + v.Name, Message: "view has no comment"})
+		}
+	}
+	return findings
+}
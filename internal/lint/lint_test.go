@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRun(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Tables: []pg.Table{
+			{
+				Schema:      "public",
+				Name:        "orders",
+				Description: "Customer orders",
+				Columns: []pg.Column{
+					{Name: "id", IsPK: true},
+					{Name: "customer_id"},
+					{Name: "tenant_id"},
+					{Name: "productId"},
+				},
+				ForeignKeys: []pg.ForeignKey{
+					{Name: "orders_customer_fkey", Columns: []string{"customer_id"}},
+					{Name: "orders_tenant_fkey", Columns: []string{"tenant_id"}},
+				},
+				Indexes: []pg.Index{{Name: "orders_customer_idx", Columns: []string{"customer_id", "id"}}},
+			},
+			{Schema: "public", Name: "EventLog", Columns: []pg.Column{{Name: "payload"}}},
+		},
+		Views: []pg.View{{Schema: "public", Name: "recent_orders", Description: "Last 30 days"}},
+	}}
+
+	expected := []Finding{
+		{Rule: "no-primary-key", Severity: Error, Object: "public.EventLog", Message: "table has no primary key"},
+		{Rule: "unindexed-foreign-key", Severity: Warning, Object: "public.orders", Message: "foreign key orders_tenant_fkey (tenant_id) has no supporting index"},
+		{Rule: "naming", Severity: Warning, Object: "public.orders.productId", Message: "column name is not lower snake_case"},
+		{Rule: "naming", Severity: Warning, Object: "public.EventLog", Message: "table name is not lower snake_case"},
+		{Rule: "missing-comment", Severity: Warning, Object: "public.EventLog", Message: "table has no comment"},
+	}
+
+	if got := Run(schemas, nil); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Run() =\n%+v\nwant\n%+v", got, expected)
+	}
+
+	if got := Run(schemas, []string{"naming", "missing-comment", "unindexed-foreign-key"}); len(got) != 1 {
+		t.Errorf("expected only the primary key finding with rules disabled, got %+v", got)
+	}
+
+	if err := ValidateRules([]string{"naming", "no-such-rule"}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		s, threshold Severity
+		expected     bool
+	}{
+		{Error, Error, true},
+		{Error, Warning, true},
+		{Warning, Warning, true},
+		{Warning, Error, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.s.AtLeast(tt.threshold); got != tt.expected {
+			t.Errorf("%s.AtLeast(%s) = %v, want %v", tt.s, tt.threshold, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package postprocess rewrites rendered output with the hooks listed under
+// the config file's "post_process" key, so formatting or boilerplate steps
+// run inside pgmd rather than as separate pipeline stages.
+package postprocess
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+)
+
+// Hook is one post-processing step. It either replaces every match of
+// Pattern with Replace (which may use $1-style references) or pipes the
+// output through Command, whose stdout becomes the new output.
+type Hook struct {
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	Replace string `yaml:"replace" toml:"replace"`
+	// Command is run by the shell, e.g. "npx prettier --parser html".
+	Command string `yaml:"command" toml:"command"`
+	// Formats limits the hook to these output formats (markdown, html,
+	// json); empty means every format.
+	Formats []string `yaml:"formats" toml:"formats"`
+
+	re *regexp.Regexp
+}
+
+// Compile checks each hook and prepares its pattern.
+func Compile(hooks []Hook) error {
+	for i := range hooks {
+		h := &hooks[i]
+		switch {
+		case h.Pattern != "" && h.Command != "":
+			return fmt.Errorf("hook %d: set either pattern or command, not both", i+1)
+		case h.Pattern != "":
+			re, err := regexp.Compile(h.Pattern)
+			if err != nil {
+				return fmt.Errorf("hook %d: %w", i+1, err)
+			}
+			h.re = re
+		case h.Command == "":
+			return fmt.Errorf("hook %d: needs a pattern or a command", i+1)
+		}
+	}
+	return nil
+}
+
+// Run applies the hooks for format to content in order. Hooks must have
+// been compiled.
+func Run(ctx context.Context, hooks []Hook, format, content string) (string, error) {
+	for i, h := range hooks {
+		if len(h.Formats) > 0 && !slices.Contains(h.Formats, format) {
+			continue
+		}
+		if h.re != nil {
+			content = h.re.ReplaceAllString(content, h.Replace)
+			continue
+		}
+		out, err := runCommand(ctx, h.Command, content)
+		if err != nil {
+			return "", fmt.Errorf("hook %d (%s): %w", i+1, h.Command, err)
+		}
+		content = out
+	}
+	return content, nil
+}
+
+func runCommand(ctx context.Context, command, input string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewBufferString(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,79 @@
+package postprocess
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	hooks := []Hook{
+		{Pattern: `(?m)^# (.+)$`, Replace: "# $1 (ACME internal)"},
+		{Pattern: "<body>", Replace: "<body><header>ACME</header>", Formats: []string{"html"}},
+	}
+	if err := Compile(hooks); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Run(context.Background(), hooks, "markdown", "# Schema\n<body>\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "# Schema (ACME internal)\n<body>\n" {
+		t.Errorf("markdown output = %q", got)
+	}
+
+	got, err = Run(context.Background(), hooks, "html", "<body>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "<body><header>ACME</header>" {
+		t.Errorf("html output = %q", got)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	hooks := []Hook{{Command: "tr a-z A-Z"}}
+	if err := Compile(hooks); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Run(context.Background(), hooks, "markdown", "users\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "USERS\n" {
+		t.Errorf("output = %q, want %q", got, "USERS\n")
+	}
+
+	failing := []Hook{{Command: "echo broken >&2; exit 3"}}
+	if err := Compile(failing); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(context.Background(), failing, "markdown", ""); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("error = %v, want it to include the command's stderr", err)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name string
+		hook Hook
+		want string
+	}{
+		{"empty", Hook{}, "needs a pattern or a command"},
+		{"both", Hook{Pattern: "a", Command: "cat"}, "not both"},
+		{"bad pattern", Hook{Pattern: "("}, "missing closing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Compile([]Hook{tt.hook})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GitHubStepSummaryLimit is the maximum size GitHub accepts for a single
+// step summary.
+const GitHubStepSummaryLimit = 1024 * 1024
+
+// GitHubStepSummary rewraps a rendered document for $GITHUB_STEP_SUMMARY:
+// every "## " section is collapsed into a <details> block, and whole
+// sections are dropped from the end once limit bytes would be exceeded.
+func GitHubStepSummary(doc string, limit int) string {
+	preamble, sections := splitSections(doc)
+
+	var sb strings.Builder
+	sb.WriteString(preamble)
+
+	omitted := 0
+	for i, sec := range sections {
+		block := collapse(sec)
+		// Reserve room for the truncation notice in case later sections
+		// don't fit either.
+		if sb.Len()+len(block)+len(truncationNotice(len(sections))) > limit {
+			omitted = len(sections) - i
+			break
+		}
+		sb.WriteString(block)
+	}
+
+	if omitted > 0 {
+		sb.WriteString(truncationNotice(omitted))
+	}
+
+	return sb.String()
+}
+
+// AppendGitHubStepSummary appends the summary to the file named by
+// $GITHUB_STEP_SUMMARY.
+func AppendGitHubStepSummary(doc string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return fmt.Errorf("GITHUB_STEP_SUMMARY is not set")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(GitHubStepSummary(doc, GitHubStepSummaryLimit)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type section struct {
+	title string
+	body  string
+}
+
+// splitSections cuts doc before each "## " heading. Lines in fenced code
+// blocks are never headings, and the anchor lines right before a heading
+// move into its section.
+func splitSections(doc string) (string, []section) {
+	var preamble strings.Builder
+	var sections []section
+	var anchors string
+	write := func(s string) {
+		if len(sections) == 0 {
+			preamble.WriteString(s)
+		} else {
+			sections[len(sections)-1].body += s
+		}
+	}
+
+	fence := ""
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case fence != "":
+			if strings.TrimSpace(trimmed) == fence {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"):
+			fence = strings.TrimRight(trimmed, "abcdefghijklmnopqrstuvwxyz")
+		case strings.HasPrefix(trimmed, "<a id=\"") && strings.HasSuffix(trimmed, "\"></a>"):
+			anchors += line
+			continue
+		case strings.HasPrefix(line, "## "):
+			sections = append(sections, section{title: strings.TrimSpace(strings.TrimPrefix(line, "## ")), body: anchors})
+			anchors = ""
+			continue
+		}
+		write(anchors + line)
+		anchors = ""
+	}
+	write(anchors)
+	return preamble.String(), sections
+}
+
+func collapse(sec section) string {
+	body := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sec.body), "---"))
+	return fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s\n\n</details>\n\n", sec.title, body)
+}
+
+func truncationNotice(omitted int) string {
+	return fmt.Sprintf("> **Note:** %d section(s) omitted to stay within the step summary size limit.\n", omitted)
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const doc = "# Database Schema Documentation\n\n" +
+	"## Schema: public\n\n### Tables\n\n#### users\n\n" +
+	"\n---\n\n" +
+	"## Schema: auth\n\n### Tables\n\n#### sessions\n\n"
+
+func TestGitHubStepSummary(t *testing.T) {
+	result := GitHubStepSummary(doc, GitHubStepSummaryLimit)
+
+	if !strings.HasPrefix(result, "# Database Schema Documentation\n\n<details>\n<summary>Schema: public</summary>\n\n### Tables\n\n#### users\n\n</details>") {
+		t.Errorf("unexpected summary:\n%s", result)
+	}
+	if !strings.Contains(result, "<summary>Schema: auth</summary>") {
+		t.Error("expected second schema collapsed")
+	}
+	if strings.Contains(result, "---") {
+		t.Error("expected schema separators dropped inside details blocks")
+	}
+	if strings.Contains(result, "omitted") {
+		t.Error("unexpected truncation notice")
+	}
+}
+
+func TestGitHubStepSummary_Truncates(t *testing.T) {
+	big := doc + strings.Repeat("#### table\n\n", 100)
+	full := GitHubStepSummary(big, GitHubStepSummaryLimit)
+	limit := len(full) - 10
+
+	result := GitHubStepSummary(big, limit)
+
+	if len(result) > limit {
+		t.Errorf("summary is %d bytes, limit %d", len(result), limit)
+	}
+	if !strings.Contains(result, "Schema: public") {
+		t.Error("expected first section kept")
+	}
+	if strings.Contains(result, "Schema: auth") {
+		t.Error("expected last section dropped")
+	}
+	if !strings.Contains(result, "1 section(s) omitted") {
+		t.Error("expected truncation notice")
+	}
+}
+
+func TestAppendGitHubStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(path, []byte("previous step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	if err := AppendGitHubStepSummary(doc); err != nil {
+		t.Fatalf("AppendGitHubStepSummary() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "previous step\n# Database Schema Documentation") {
+		t.Errorf("expected summary appended, got:\n%s", data)
+	}
+
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	if err := AppendGitHubStepSummary(doc); err == nil {
+		t.Error("expected error when GITHUB_STEP_SUMMARY is unset")
+	}
+}
+
+func TestGitHubStepSummary_FencesAndAnchors(t *testing.T) {
+	doc := "# Database Schema Documentation\n\n" +
+		"<a id=\"schema-public\"></a>\n## Schema: public\n\n```sql\n## not a heading\n```\n\n" +
+		"<a id=\"schema-auth\"></a>\n## Schema: auth\n\n#### sessions\n"
+
+	preamble, sections := splitSections(doc)
+	if preamble != "# Database Schema Documentation\n\n" {
+		t.Errorf("preamble = %q", preamble)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("got %d sections, want 2: %+v", len(sections), sections)
+	}
+	if want := "<a id=\"schema-public\"></a>\n\n```sql\n## not a heading\n```\n\n"; sections[0].body != want {
+		t.Errorf("first section = %q, want %q", sections[0].body, want)
+	}
+	if !strings.HasPrefix(sections[1].body, "<a id=\"schema-auth\"></a>\n") {
+		t.Errorf("second section lost its anchor: %q", sections[1].body)
+	}
+}
@@ -0,0 +1,135 @@
+// Package search finds the places in a fetched schema model where a
+// pattern occurs.
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Match is one occurrence. Object is the qualified name of the table,
+// column, view or other object, Kind says which of these it is, and Field
+// is where in it the pattern matched: its name, comment, default or
+// definition. Text is the matching line of that field.
+type Match struct {
+	Object string
+	Kind   string
+	Field  string
+	Text   string
+}
+
+func (m Match) String() string {
+	if m.Field == "name" {
+		return fmt.Sprintf("%s: %s name", m.Object, m.Kind)
+	}
+	return fmt.Sprintf("%s: %s %s: %s", m.Object, m.Kind, m.Field, m.Text)
+}
+
+// Find returns every match of re in schemas, in document order.
+func Find(schemas []pg.SchemaInfo, re *regexp.Regexp) []Match {
+	s := &searcher{re: re}
+	for _, schema := range schemas {
+		s.schema(schema)
+	}
+	return s.matches
+}
+
+type searcher struct {
+	re      *regexp.Regexp
+	matches []Match
+}
+
+// check records a match for each line of text that re matches. Names are
+// matched whole and reported once.
+func (s *searcher) check(object, kind, field, text string) {
+	if text == "" {
+		return
+	}
+	if field == "name" {
+		if s.re.MatchString(text) {
+			s.matches = append(s.matches, Match{Object: object, Kind: kind, Field: field, Text: text})
+		}
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if s.re.MatchString(line) {
+			s.matches = append(s.matches, Match{Object: object, Kind: kind, Field: field, Text: strings.Join(strings.Fields(line), " ")})
+		}
+	}
+}
+
+func (s *searcher) schema(schema pg.SchemaInfo) {
+	for _, t := range schema.Tables {
+		name := t.Schema + "." + t.Name
+		s.check(name, "table", "name", t.Name)
+		s.check(name, "table", "comment", t.Description)
+		s.columns(name, t.Columns)
+		for _, idx := range t.Indexes {
+			s.check(name+"."+idx.Name, "index", "name", idx.Name)
+			s.check(name+"."+idx.Name, "index", "predicate", idx.Predicate)
+		}
+		for _, c := range t.Checks {
+			s.check(name+"."+c.Name, "check", "name", c.Name)
+			s.check(name+"."+c.Name, "check", "definition", c.Definition)
+		}
+		for _, fk := range t.ForeignKeys {
+			s.check(name+"."+fk.Name, "foreign key", "name", fk.Name)
+		}
+	}
+	for _, v := range schema.Views {
+		name := v.Schema + "." + v.Name
+		s.check(name, "view", "name", v.Name)
+		s.check(name, "view", "comment", v.Description)
+		s.columns(name, v.Columns)
+		s.check(name, "view", "definition", v.Definition)
+	}
+	for _, mv := range schema.MaterializedViews {
+		name := mv.Schema + "." + mv.Name
+		s.check(name, "materialized view", "name", mv.Name)
+		s.check(name, "materialized view", "comment", mv.Description)
+		s.columns(name, mv.Columns)
+		s.check(name, "materialized view", "definition", mv.Definition)
+	}
+	for _, seq := range schema.Sequences {
+		s.check(seq.Schema+"."+seq.Name, "sequence", "name", seq.Name)
+	}
+	for _, trig := range schema.Triggers {
+		name := trig.Schema + "." + trig.Table + "." + trig.Name
+		s.check(name, "trigger", "name", trig.Name)
+	}
+	for _, fn := range schema.Functions {
+		name := fn.Schema + "." + fn.Name + "(" + fn.Arguments + ")"
+		kind := fn.ObjectType()
+		s.check(name, kind, "name", fn.Name)
+		s.check(name, kind, "comment", fn.Description)
+		s.check(name, kind, "definition", fn.Definition)
+		for _, arg := range fn.Args {
+			s.check(name, kind, "argument", arg.Name)
+			s.check(name, kind, "argument default", arg.Default)
+		}
+	}
+	for _, t := range schema.Types {
+		name := t.Schema + "." + t.Name
+		s.check(name, "type", "name", t.Name)
+		s.check(name, "type", "comment", t.Description)
+		if t.Kind == "enum" {
+			for _, v := range t.Values {
+				s.check(name, "enum", "value", v)
+				s.check(name+"."+v, "enum value", "comment", t.ValueDescriptions[v])
+			}
+		}
+		s.columns(name, t.Attributes)
+	}
+}
+
+func (s *searcher) columns(relation string, columns []pg.Column) {
+	for _, col := range columns {
+		name := relation + "." + col.Name
+		s.check(name, "column", "name", col.Name)
+		s.check(name, "column", "comment", col.Description)
+		s.check(name, "column", "default", col.Default)
+	}
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestFind(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:      "public",
+					Name:        "users",
+					Description: "People.\nThe email column is the login.",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid"},
+						{Name: "email", Type: "text", Default: "''::text"},
+					},
+					Indexes: []pg.Index{
+						{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+					},
+				},
+			},
+			Views: []pg.View{
+				{
+					Schema:     "public",
+					Name:       "contacts",
+					Definition: " SELECT id,\n    email\n   FROM users;",
+				},
+			},
+			Functions: []pg.Function{
+				{
+					Schema:     "public",
+					Name:       "find_user",
+					Arguments:  "p_email text",
+					Args:       []pg.Argument{{Name: "p_email", Type: "text", Mode: "IN"}},
+					Definition: "CREATE FUNCTION public.find_user(text)\n AS $$ SELECT * FROM users WHERE lower(email) = $1 $$",
+				},
+			},
+			Types: []pg.CustomType{
+				{Schema: "public", Name: "channel", Kind: "enum", Values: []string{"sms", "email"}, ValueDescriptions: map[string]string{"email": "Sent by email."}},
+			},
+		},
+	}
+
+	matches := Find(schemas, regexp.MustCompile("email"))
+	expected := []string{
+		"public.users: table comment: The email column is the login.",
+		"public.users.email: column name",
+		"public.users.users_email_key: index name",
+		"public.contacts: view definition: email",
+		"public.find_user(p_email text): function definition: AS $$ SELECT * FROM users WHERE lower(email) = $1 $$",
+		"public.find_user(p_email text): function argument: p_email",
+		"public.channel: enum value: email",
+		"public.channel.email: enum value comment: Sent by email.",
+	}
+
+	if len(matches) != len(expected) {
+		t.Errorf("got %d matches, want %d: %v", len(matches), len(expected), matches)
+	}
+	for i, m := range matches {
+		if i < len(expected) && m.String() != expected[i] {
+			t.Errorf("match %d = %q, want %q", i, m.String(), expected[i])
+		}
+	}
+
+	if matches := Find(schemas, regexp.MustCompile("^users$")); len(matches) != 1 || matches[0].Object != "public.users" {
+		t.Errorf("anchored name search = %v", matches)
+	}
+	if matches := Find(schemas, regexp.MustCompile("nothing")); len(matches) != 0 {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+}
@@ -0,0 +1,134 @@
+// Package serve publishes the HTML document over HTTP, re-fetching the
+// schema on an interval or on demand.
+package serve
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sotirismorf/pgmd/pkg/html"
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Source introspects the database to document.
+type Source func(ctx context.Context) (pg.Database, error)
+
+// Server renders the latest successful fetch. A failed refresh keeps the
+// previous page, so a brief database outage does not take the docs down.
+type Server struct {
+	source Source
+	opts   markdown.Options
+	mux    *http.ServeMux
+	// refreshToken is the bearer token POST /refresh requires.
+	refreshToken string
+
+	// refreshing serializes refreshes, so a slow fetch cannot finish
+	// after a later one and put back an older page.
+	refreshing sync.Mutex
+
+	mu      sync.RWMutex
+	page    string
+	fetched time.Time
+	err     error
+}
+
+// New returns a Server for source. POST /refresh is served only when
+// refreshToken is set, to requests that send it as a bearer token; a
+// browser cannot attach that header cross-origin, so other sites cannot
+// make the server query the database.
+func New(source Source, opts markdown.Options, refreshToken string) *Server {
+	s := &Server{source: source, opts: opts, mux: http.NewServeMux(), refreshToken: refreshToken}
+	s.mux.HandleFunc("GET /{$}", s.handlePage)
+	if refreshToken != "" {
+		s.mux.HandleFunc("POST /refresh", s.handleRefresh)
+	}
+	return s
+}
+
+// Refresh fetches and renders the schema. Concurrent calls run one after
+// another.
+func (s *Server) Refresh(ctx context.Context) error {
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+
+	db, err := s.source(ctx)
+	var page string
+	if err == nil {
+		page, err = html.Render(db, s.opts)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	if err != nil {
+		return err
+	}
+	s.page = page
+	s.fetched = time.Now()
+	return nil
+}
+
+// Run refreshes every interval until ctx is done. Errors are logged.
+func (s *Server) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				log.Printf("refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	page, fetched, err := s.page, s.fetched, s.err
+	s.mu.RUnlock()
+
+	if page == "" {
+		msg := "schema not fetched yet"
+		if err != nil {
+			msg = fmt.Sprintf("fetching schema: %v", err)
+		}
+		http.Error(w, msg, http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Last-Modified", fetched.UTC().Format(http.TimeFormat))
+	if err != nil {
+		w.Header().Set("Warning", fmt.Sprintf("110 pgmd %q", "last refresh failed: "+err.Error()))
+	}
+	fmt.Fprint(w, page)
+}
+
+// handleRefresh re-fetches on demand and sends the browser back to the
+// document.
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.refreshToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "refresh requires the bearer token", http.StatusUnauthorized)
+		return
+	}
+	if err := s.Refresh(r.Context()); err != nil {
+		http.Error(w, fmt.Sprintf("fetching schema: %v", err), http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
@@ -0,0 +1,120 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestServer(t *testing.T) {
+	table := "users"
+	var fail bool
+	source := func(ctx context.Context) (pg.Database, error) {
+		if fail {
+			return pg.Database{}, errors.New("connection refused")
+		}
+		return pg.Database{Schemas: []pg.SchemaInfo{{
+			Name:   "public",
+			Tables: []pg.Table{{Schema: "public", Name: table}},
+		}}}, nil
+	}
+	s := New(source, markdown.Options{}, "secret")
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("before first fetch: status %d, want 503", rec.Code)
+	}
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get(); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "users") {
+		t.Errorf("status %d, body:\n%s", rec.Code, rec.Body.String())
+	}
+
+	table = "accounts"
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/refresh", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh without token: status %d, want 401", rec.Code)
+	}
+	if body := get().Body.String(); strings.Contains(body, "accounts") {
+		t.Error("refresh without token re-fetched the schema")
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("refresh: status %d, want 303", rec.Code)
+	}
+	if body := get().Body.String(); !strings.Contains(body, "accounts") {
+		t.Error("expected refreshed page to document accounts")
+	}
+
+	fail = true
+	if err := s.Refresh(context.Background()); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	rec = get()
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "accounts") {
+		t.Error("expected the last good page to be kept after a failed refresh")
+	}
+	if rec.Header().Get("Warning") == "" {
+		t.Error("expected a Warning header after a failed refresh")
+	}
+}
+
+func TestServerRefreshDisabled(t *testing.T) {
+	source := func(ctx context.Context) (pg.Database, error) { return pg.Database{}, nil }
+	s := New(source, markdown.Options{}, "")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	s.ServeHTTP(rec, req)
+	if rec.Code == http.StatusSeeOther {
+		t.Error("refresh served without a token configured")
+	}
+}
+
+func TestServerRefreshSerialized(t *testing.T) {
+	var running, overlapped atomic.Int32
+	source := func(ctx context.Context) (pg.Database, error) {
+		if running.Add(1) > 1 {
+			overlapped.Store(1)
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return pg.Database{}, nil
+	}
+	s := New(source, markdown.Options{}, "")
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Refresh(context.Background())
+		}()
+	}
+	wg.Wait()
+	if overlapped.Load() != 0 {
+		t.Error("refreshes ran concurrently")
+	}
+}
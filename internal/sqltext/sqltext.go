@@ -0,0 +1,170 @@
+// Package sqltext holds the small lexical helpers that pg and the
+// analysis packages share when they read SQL text without parsing it.
+package sqltext
+
+import "strings"
+
+// Identifier folds an unquoted name to lower case and unquotes a quoted
+// one, as PostgreSQL resolves them.
+func Identifier(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, `"`) {
+		return strings.Trim(name, `"`)
+	}
+	return strings.ToLower(name)
+}
+
+// StripComments removes "--" and "/* */" comments, leaving a newline or
+// a space in their place. String literals ('…', E'…' and $tag$…$tag$)
+// and quoted identifiers are copied as they are, so a comment marker
+// inside one is kept. Block comments nest, as in PostgreSQL. Plain
+// literals are read with standard_conforming_strings on, the default.
+func StripComments(sql string) string {
+	return stripComments(sql, false)
+}
+
+// StripBodyComments is like StripComments but also removes comments
+// inside dollar-quoted strings, which in a function's definition hold
+// its body.
+func StripBodyComments(sql string) string {
+	return stripComments(sql, true)
+}
+
+func stripComments(sql string, inBodies bool) string {
+	var b strings.Builder
+	for i := 0; i < len(sql); {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end, closed := blockCommentEnd(sql, i)
+			if !closed {
+				return b.String()
+			}
+			b.WriteByte(' ')
+			i = end
+		case inBodies && sql[i] == '$':
+			b.WriteByte('$')
+			i++
+		default:
+			end := tokenEnd(sql, i)
+			b.WriteString(sql[i:end])
+			i = end
+		}
+	}
+	return b.String()
+}
+
+// Statements splits sql at the semicolons outside literals, quoted
+// identifiers and comments, and returns each non-empty statement with
+// its comments removed and surrounding space trimmed.
+func Statements(sql string) []string {
+	body := StripComments(sql)
+	var statements []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			statements = append(statements, s)
+		}
+	}
+	start := 0
+	for i := 0; i < len(body); {
+		if body[i] == ';' {
+			add(body[start:i])
+			start = i + 1
+		}
+		i = tokenEnd(body, i)
+	}
+	add(body[start:])
+	return statements
+}
+
+// blockCommentEnd returns the offset just past the block comment starting
+// at i, and whether it is closed at all.
+func blockCommentEnd(sql string, i int) (int, bool) {
+	depth := 0
+	for i < len(sql) {
+		switch {
+		case strings.HasPrefix(sql[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(sql[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, true
+			}
+		default:
+			i++
+		}
+	}
+	return len(sql), false
+}
+
+// tokenEnd returns the offset just past the literal or quoted identifier
+// starting at i, len(sql) when it is not closed, or i+1 for any other
+// character.
+func tokenEnd(sql string, i int) int {
+	switch c := sql[i]; {
+	case c == '\'':
+		escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i < 2 || !isIdentChar(sql[i-2]))
+		return quotedEnd(sql, i, '\'', escapes)
+	case c == '"':
+		return quotedEnd(sql, i, '"', false)
+	case c == '$' && (i == 0 || !isIdentChar(sql[i-1])):
+		tag := dollarTag(sql[i:])
+		if tag == "" {
+			return i + 1
+		}
+		end := strings.Index(sql[i+len(tag):], tag)
+		if end < 0 {
+			return len(sql)
+		}
+		return i + len(tag) + end + len(tag)
+	}
+	return i + 1
+}
+
+// quotedEnd finds the closing quote of the quoted text starting at i. A
+// doubled quote stands for itself, and so does a backslash-escaped one
+// when escapes is set.
+func quotedEnd(sql string, i int, quote byte, escapes bool) int {
+	for i++; i < len(sql); i++ {
+		switch {
+		case escapes && sql[i] == '\\':
+			i++
+		case sql[i] == quote && i+1 < len(sql) && sql[i+1] == quote:
+			i++
+		case sql[i] == quote:
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// dollarTag returns the $tag$ opening s, or "" if s does not open a
+// dollar-quoted string.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c >= '0' && c <= '9' && i > 1, isIdentStart(c):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9' || c == '$'
+}
@@ -0,0 +1,74 @@
+package sqltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"Orders":       "orders",
+		` "Orders" `:   "Orders",
+		`"my table"`:   "my table",
+		"order_items$": "order_items$",
+	}
+	for in, want := range tests {
+		if got := Identifier(in); got != want {
+			t.Errorf("Identifier(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		sql, want string
+	}{
+		{"SELECT 1 -- one\nFROM t", "SELECT 1 \nFROM t"},
+		{"SELECT /* a */ 1", "SELECT   1"},
+		{"SELECT 1 -- trailing", "SELECT 1 "},
+		{"SELECT /* unterminated", "SELECT "},
+	}
+	for _, tt := range tests {
+		if got := StripComments(tt.sql); got != tt.want {
+			t.Errorf("StripComments(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestStripCommentsKeepsLiterals(t *testing.T) {
+	tests := []struct {
+		sql, want, body string
+	}{
+		{"SELECT '--x' -- c", "SELECT '--x' ", "SELECT '--x' "},
+		{`SELECT "/*" /* c */`, `SELECT "/*"  `, `SELECT "/*"  `},
+		{"SELECT E'\\'--' -- c", "SELECT E'\\'--' ", "SELECT E'\\'--' "},
+		{"AS $f$ BEGIN -- c\nEND $f$", "AS $f$ BEGIN -- c\nEND $f$", "AS $f$ BEGIN \nEND $f$"},
+		{"SELECT 1 /* a /* b */ c */ + 2", "SELECT 1   + 2", "SELECT 1   + 2"},
+	}
+	for _, tt := range tests {
+		if got := StripComments(tt.sql); got != tt.want {
+			t.Errorf("StripComments(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+		if got := StripBodyComments(tt.sql); got != tt.body {
+			t.Errorf("StripBodyComments(%q) = %q, want %q", tt.sql, got, tt.body)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"SELECT 1;", []string{"SELECT 1"}},
+		{"SELECT '--'; DELETE FROM t", []string{"SELECT '--'", "DELETE FROM t"}},
+		{"SELECT ';', \"a;b\", $$;$$; ;", []string{"SELECT ';', \"a;b\", $$;$$"}},
+		{"SELECT $1; SELECT a$b$c", []string{"SELECT $1", "SELECT a$b$c"}},
+		{"-- only a comment", nil},
+	}
+	for _, tt := range tests {
+		if got := Statements(tt.sql); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Statements(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
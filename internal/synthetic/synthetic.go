@@ -0,0 +1,124 @@
+// Package synthetic generates large, realistic-looking catalogs for
+// benchmarks, either as an in-memory model or as DDL to load into a
+// scratch database.
+package synthetic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Schemas builds schemaCount schemas of tablesPerSchema tables each. Every
+// table has a handful of typed columns, a primary key, an index, a CHECK
+// constraint and a foreign key to the previous table; every tenth table
+// gets a trigger and a view over it.
+func Schemas(schemaCount, tablesPerSchema int) []pg.SchemaInfo {
+	schemas := make([]pg.SchemaInfo, 0, schemaCount)
+	for s := range schemaCount {
+		name := fmt.Sprintf("s%d", s)
+		info := pg.SchemaInfo{
+			Name:      name,
+			Functions: []pg.Function{{Schema: name, Name: "touch_updated_at", ReturnType: "trigger"}},
+			Types:     []pg.CustomType{{Schema: name, Name: "status", Kind: "enum", Values: []string{"active", "archived"}}},
+		}
+
+		for t := range tablesPerSchema {
+			table := table(name, t)
+			info.Tables = append(info.Tables, table)
+			info.Sequences = append(info.Sequences, pg.Sequence{
+				Schema: name, Name: table.Name + "_id_seq", DataType: "bigint",
+				Start: 1, Min: 1, Max: 9223372036854775807, Increment: 1,
+			})
+
+			if t%10 == 0 {
+				info.Triggers = append(info.Triggers, pg.Trigger{
+					Schema: name, Table: table.Name, Name: table.Name + "_touch",
+					Event: "UPDATE", Timing: "BEFORE", Function: "touch_updated_at",
+				})
+				info.Views = append(info.Views, pg.View{
+					Schema:    name,
+					Name:      table.Name + "_active",
+					Columns:   table.Columns[:3],
+					DependsOn: []string{name + "." + table.Name},
+				})
+			}
+		}
+
+		schemas = append(schemas, info)
+	}
+	return schemas
+}
+
+func table(schema string, n int) pg.Table {
+	name := fmt.Sprintf("t%05d", n)
+	t := pg.Table{
+		Schema:      schema,
+		Name:        name,
+		Description: "Synthetic table " + name,
+		Columns: []pg.Column{
+			{Name: "id", Position: 1, Type: "bigint", UDTName: "int8", IsPK: true, Default: fmt.Sprintf("nextval('%s_id_seq'::regclass)", name)},
+			{Name: "name", Position: 2, Type: "text", UDTName: "text"},
+			{Name: "status", Position: 3, Type: "USER-DEFINED", UDTName: "status", Default: "'active'::status"},
+			{Name: "amount", Position: 4, Type: "numeric", UDTName: "numeric", Nullable: true},
+			{Name: "payload", Position: 5, Type: "jsonb", UDTName: "jsonb", Nullable: true, Description: "Free-form attributes"},
+			{Name: "created_at", Position: 6, Type: "timestamp with time zone", UDTName: "timestamptz", Default: "now()"},
+			{Name: "updated_at", Position: 7, Type: "timestamp with time zone", UDTName: "timestamptz", Nullable: true},
+		},
+		Indexes: []pg.Index{
+			{Name: name + "_pkey", Columns: []string{"id"}, IsUnique: true, IsPrimary: true},
+			{Name: name + "_created_at_idx", Columns: []string{"created_at"}},
+		},
+		Checks: []pg.CheckConstraint{
+			{Name: name + "_amount_check", Columns: []string{"amount"}, Definition: "CHECK ((amount >= (0)::numeric))"},
+		},
+	}
+
+	if n > 0 {
+		parent := fmt.Sprintf("t%05d", n-1)
+		t.Columns = append(t.Columns, pg.Column{
+			Name: "parent_id", Position: 8, Type: "bigint", UDTName: "int8", Nullable: true,
+			FKRef: schema + "." + parent + ".id", FKOnDelete: "CASCADE",
+		})
+		t.ForeignKeys = []pg.ForeignKey{{
+			Name: name + "_parent_id_fkey", Columns: []string{"parent_id"},
+			RefSchema: schema, RefTable: parent, RefColumns: []string{"id"}, OnDelete: "CASCADE",
+		}}
+	}
+	return t
+}
+
+// DDL returns SQL creating the same tables, triggers and views as
+// Schemas(1, tables) in the given schema, for loading into a scratch
+// database.
+func DDL(schema string, tables int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE SCHEMA %s;\n", schema)
+	fmt.Fprintf(&sb, "CREATE TYPE %s.status AS ENUM ('active', 'archived');\n", schema)
+	fmt.Fprintf(&sb, "CREATE FUNCTION %s.touch_updated_at() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN NEW.updated_at := now(); RETURN NEW; END $$;\n", schema)
+
+	for n := range tables {
+		name := fmt.Sprintf("t%05d", n)
+		fmt.Fprintf(&sb, "CREATE TABLE %s.%s (\n", schema, name)
+		sb.WriteString("  id bigserial PRIMARY KEY,\n")
+		sb.WriteString("  name text NOT NULL,\n")
+		fmt.Fprintf(&sb, "  status %s.status NOT NULL DEFAULT 'active',\n", schema)
+		sb.WriteString("  amount numeric CHECK (amount >= 0),\n")
+		sb.WriteString("  payload jsonb,\n")
+		sb.WriteString("  created_at timestamptz NOT NULL DEFAULT now(),\n")
+		sb.WriteString("  updated_at timestamptz")
+		if n > 0 {
+			fmt.Fprintf(&sb, ",\n  parent_id bigint REFERENCES %s.t%05d (id) ON DELETE CASCADE", schema, n-1)
+		}
+		sb.WriteString("\n);\n")
+		fmt.Fprintf(&sb, "CREATE INDEX %s_created_at_idx ON %s.%s (created_at);\n", name, schema, name)
+		fmt.Fprintf(&sb, "COMMENT ON COLUMN %s.%s.payload IS 'Free-form attributes';\n", schema, name)
+
+		if n%10 == 0 {
+			fmt.Fprintf(&sb, "CREATE TRIGGER %s_touch BEFORE UPDATE ON %s.%s FOR EACH ROW EXECUTE FUNCTION %s.touch_updated_at();\n", name, schema, name, schema)
+			fmt.Fprintf(&sb, "CREATE VIEW %s.%s_active AS SELECT id, name, status FROM %s.%s WHERE status = 'active';\n", schema, name, schema, name)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,140 @@
+// Package html renders the Markdown document as a single self-contained
+// HTML page with embedded CSS and a navigation sidebar.
+package html
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+//go:embed page.html.tmpl
+var pageTemplate string
+
+var page = template.Must(template.New("page").Parse(pageTemplate))
+
+type navSchema struct {
+	Name    string
+	ID      string
+	Entries []navEntry
+}
+
+type navEntry struct {
+	Name string
+	ID   string
+	Kind string
+}
+
+// Render renders db with opts as HTML. Anchors are always emitted since
+// the sidebar links to them.
+func Render(db pg.Database, opts markdown.Options) (string, error) {
+	opts.Anchors = true
+	doc := markdown.RenderDatabase(db, opts)
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.Table, extension.Strikethrough),
+		goldmark.WithParserOptions(parser.WithASTTransformers(util.Prioritized(safeLinks{}, 0))),
+		// The document embeds its own anchors, <details> and <span title>;
+		// comments have their raw HTML escaped, and safeLinks stands in
+		// for the URL check unsafe rendering skips.
+		goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+	)
+	var body bytes.Buffer
+	if err := md.Convert([]byte(doc), &body); err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	err := page.Execute(&out, struct {
+		Title   string
+		Schemas []navSchema
+		Body    template.HTML
+	}{
+		Title:   "Database Schema Documentation",
+		Schemas: navigation(db.Schemas, opts),
+		Body:    template.HTML(body.String()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// safeLinks replaces links and images whose destination could run
+// script, such as javascript: URLs in an object's comment, with their
+// text.
+type safeLinks struct{}
+
+func (safeLinks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var unsafe []ast.Node
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			if dangerousURL(n.Destination) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.Image:
+			if dangerousURL(n.Destination) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.AutoLink:
+			if dangerousURL(n.URL(source)) {
+				unsafe = append(unsafe, n)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range unsafe {
+		parent := n.Parent()
+		if link, ok := n.(*ast.AutoLink); ok {
+			parent.ReplaceChild(parent, n, ast.NewString(link.Label(source)))
+			continue
+		}
+		for child := n.FirstChild(); child != nil; child = n.FirstChild() {
+			parent.InsertBefore(parent, n, child)
+		}
+		parent.RemoveChild(parent, n)
+	}
+}
+
+// dangerousURL checks url as the renderer writes it, with character
+// references resolved, and without the whitespace and control characters
+// browsers ignore before a scheme.
+func dangerousURL(url []byte) bool {
+	url = util.URLEscape(url, true)
+	return goldmarkhtml.IsDangerousURL(bytes.TrimLeftFunc(url, func(r rune) bool { return r <= ' ' }))
+}
+
+func navigation(schemas []pg.SchemaInfo, opts markdown.Options) []navSchema {
+	var nav []navSchema
+	for _, s := range schemas {
+		s = markdown.Limited(s, opts)
+		ns := navSchema{Name: s.Name, ID: markdown.AnchorID("schema", s.Name, "")}
+		for _, t := range s.Tables {
+			ns.Entries = append(ns.Entries, navEntry{Name: t.Name, ID: markdown.AnchorID("table", s.Name, t.Name), Kind: "table"})
+		}
+		for _, v := range s.Views {
+			ns.Entries = append(ns.Entries, navEntry{Name: v.Name, ID: markdown.AnchorID("view", s.Name, v.Name), Kind: "view"})
+		}
+		for _, mv := range s.MaterializedViews {
+			ns.Entries = append(ns.Entries, navEntry{Name: mv.Name, ID: markdown.AnchorID("matview", s.Name, mv.Name), Kind: "matview"})
+		}
+		nav = append(nav, ns)
+	}
+	return nav
+}
@@ -0,0 +1,79 @@
+package html
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRender(t *testing.T) {
+	db := pg.Database{Schemas: []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users", Columns: []pg.Column{{Name: "id", Type: "integer", IsPK: true}}},
+			},
+			Views: []pg.View{{Schema: "public", Name: "active_users"}},
+		},
+	}}
+
+	result, err := Render(db, markdown.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"doctype", "<!DOCTYPE html>"},
+		{"embedded css", "<style>"},
+		{"schema nav", `<h2><a href="#schema-public">public</a></h2>`},
+		{"table nav", `<li class="table"><a href="#table-public-users">users</a></li>`},
+		{"view nav", `<li class="view"><a href="#view-public-active_users">active_users</a></li>`},
+		{"anchor target", `<a id="table-public-users"></a>`},
+		{"html table", "<th>Column</th>"},
+		{"cell", "<td>PK, NOT NULL</td>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+
+	if strings.Contains(result, "| Column |") {
+		t.Error("markdown table was not converted")
+	}
+}
+
+func TestRender_UnsafeLinks(t *testing.T) {
+	db := pg.Database{Schemas: []pg.SchemaInfo{{
+		Name: "public",
+		Tables: []pg.Table{{
+			Schema:      "public",
+			Name:        "users",
+			Description: "See [docs](javascript:alert(1)), [more](&#106;avascript:alert(2)), ![x](data:text/html,hi) and [wiki](https://example.com/users).",
+			Columns:     []pg.Column{{Name: "id", Type: "integer", Description: "<script>alert(3)</script>"}},
+		}},
+	}}}
+
+	result, err := Render(db, markdown.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unexpected := range []string{"javascript:", "avascript:", "data:text/html", "<script>"} {
+		if strings.Contains(result, unexpected) {
+			t.Errorf("unexpected %q in:\n%s", unexpected, result)
+		}
+	}
+	for _, expected := range []string{"See docs, more, x and ", `<a href="https://example.com/users">wiki</a>`} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %q in:\n%s", expected, result)
+		}
+	}
+}
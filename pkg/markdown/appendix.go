@@ -0,0 +1,318 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/internal/analysis"
+	"github.com/sotirismorf/pgmd/internal/graph"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func (r *renderer) renderRoles(roles []pg.Role) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "roles", "")
+	sb.WriteString("## Roles\n\n")
+	sb.WriteString("| Role | Login | Superuser | Attributes | Conn Limit | Valid Until | Member Of |\n")
+	sb.WriteString("|------|-------|-----------|------------|------------|-------------|-----------|\n")
+
+	for _, role := range roles {
+		var attrs []string
+		if role.CreateDB {
+			attrs = append(attrs, "CREATEDB")
+		}
+		if role.CreateRole {
+			attrs = append(attrs, "CREATEROLE")
+		}
+		if role.Replication {
+			attrs = append(attrs, "REPLICATION")
+		}
+		if role.BypassRLS {
+			attrs = append(attrs, "BYPASSRLS")
+		}
+		limit := "unlimited"
+		if role.ConnLimit >= 0 {
+			limit = fmt.Sprintf("%d", role.ConnLimit)
+		}
+		writeTableRow(sb, []string{text(role.Name), yesNo(role.CanLogin), yesNo(role.Superuser), strings.Join(attrs, ", "),
+			limit, role.ValidUntil, text(strings.Join(role.MemberOf, ", "))})
+	}
+}
+
+// renderWarnings lists what each schema's document leaves out, so a
+// reader can tell a missing object from one that does not exist.
+func (r *renderer) renderWarnings(schemas []pg.SchemaInfo) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "warnings", "")
+	sb.WriteString("## Warnings\n\n")
+	sb.WriteString("| Object | Kind | Warning |\n")
+	sb.WriteString("|--------|------|---------|\n")
+
+	for _, s := range schemas {
+		for _, w := range s.Warnings {
+			writeTableRow(sb, []string{text(s.Name + "." + w.Object), w.Kind, cellProse(w.Message)})
+		}
+	}
+}
+
+// renderExtensions lists the extensions an environment must provide.
+func (r *renderer) renderExtensions(extensions []pg.Extension) {
+	sb := r.sb
+	r.writeAnchor("appendix", "extensions", "")
+	sb.WriteString("## Extensions\n\n")
+	sb.WriteString("| Extension | Version | Schema | Description |\n")
+	sb.WriteString("|-----------|---------|--------|-------------|\n")
+
+	for _, ext := range extensions {
+		writeTableRow(sb, []string{text(ext.Name), text(ext.Version), text(ext.Schema), cellProse(ext.Description)})
+	}
+	sb.WriteString("\n")
+}
+
+func (r *renderer) renderSettings(settings []pg.Setting) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "settings", "")
+	sb.WriteString("## Appendix: Settings\n\n")
+	sb.WriteString("| Setting | Value | Source |\n")
+	sb.WriteString("|---------|-------|--------|\n")
+
+	for _, st := range settings {
+		value := st.Value
+		if st.Unit != "" {
+			value += " " + st.Unit
+		}
+		writeTableRow(sb, []string{text(st.Name), text(value), st.Source})
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func (r *renderer) renderForeignKeyCycles(cycles [][]string, selfRefs []graph.SelfReference) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "fk-cycles", "")
+	sb.WriteString("## Foreign Key Cycles\n\n")
+	sb.WriteString("Rows in these tables reference each other, so deletes and bulk loads need deferred constraints or a specific order.\n\n")
+
+	if len(cycles) > 0 {
+		sb.WriteString("### Cycles\n\n")
+		for _, cycle := range cycles {
+			path := append(append([]string(nil), cycle...), cycle[0])
+			names := make([]string, len(path))
+			for i, name := range path {
+				names[i] = code(name)
+			}
+			fmt.Fprintf(sb, "- %s\n", strings.Join(names, " → "))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(selfRefs) > 0 {
+		sb.WriteString("### Self-References\n\n")
+		for _, ref := range selfRefs {
+			fmt.Fprintf(sb, "- %s.%s\n", code(ref.Table), code(ref.Column))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func (r *renderer) renderIdentifierHazards(hazards []analysis.Hazard) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "identifier-hazards", "")
+	sb.WriteString("## Identifier Hazards\n\n")
+
+	if len(hazards) == 0 {
+		sb.WriteString("No reserved words or identifiers requiring quotes.\n")
+		return
+	}
+
+	sb.WriteString("| Object | Identifier | Warning |\n")
+	sb.WriteString("|--------|------------|---------|\n")
+	for _, h := range hazards {
+		writeTableRow(sb, []string{text(h.Object), code(h.Identifier), "⚠ " + text(h.Issue)})
+	}
+}
+
+func (r *renderer) renderPortability(tables []analysis.TablePortability) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "portability", "")
+	sb.WriteString("## Portability\n\n")
+
+	if len(tables) == 0 {
+		sb.WriteString("No PostgreSQL-specific types or features in use.\n")
+		return
+	}
+
+	sb.WriteString("| Table | PostgreSQL-specific features |\n")
+	sb.WriteString("|-------|------------------------------|\n")
+	for _, t := range tables {
+		writeTableRow(sb, []string{text(t.Schema + "." + t.Table), text(strings.Join(t.Features, "; "))})
+	}
+}
+
+func (r *renderer) renderAuditColumns(coverage []analysis.AuditCoverage) {
+	sb := r.sb
+	convention := r.opts.AuditColumns
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "audit-columns", "")
+	sb.WriteString("## Audit Columns\n\n")
+
+	compliant := 0
+	for _, c := range coverage {
+		if len(c.Missing(convention)) == 0 {
+			compliant++
+		}
+	}
+	names := make([]string, len(convention))
+	for i, name := range convention {
+		names[i] = code(name)
+	}
+	fmt.Fprintf(sb, "%d of %d tables have all of: %s.\n\n", compliant, len(coverage), strings.Join(names, ", "))
+
+	if len(coverage) == 0 {
+		return
+	}
+
+	writeTableHeader(sb, append([]string{"Table"}, convention...))
+	for _, c := range coverage {
+		cells := []string{text(c.Schema + "." + c.Table)}
+		for _, ok := range c.Present {
+			if ok {
+				cells = append(cells, "✓")
+			} else {
+				cells = append(cells, "✗")
+			}
+		}
+		writeTableRow(sb, cells)
+	}
+}
+
+func (r *renderer) renderEncryptedColumns(columns []analysis.EncryptedColumn) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "encrypted-data", "")
+	sb.WriteString("## Encrypted Data\n\n")
+
+	if len(columns) == 0 {
+		sb.WriteString("No columns appear to store encrypted data.\n")
+		return
+	}
+
+	sb.WriteString("| Column | Evidence |\n")
+	sb.WriteString("|--------|----------|\n")
+	for _, c := range columns {
+		writeTableRow(sb, []string{text(c.Schema + "." + c.Table + "." + c.Column), text(strings.Join(c.Evidence, "; "))})
+	}
+}
+
+func (r *renderer) renderStorage(storage pg.Storage) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "storage", "")
+	sb.WriteString("## Appendix: Storage\n\n")
+
+	fmt.Fprintf(sb, "**Large objects:** %d\n\n", storage.LargeObjects)
+
+	if len(storage.ByteaColumns) == 0 {
+		sb.WriteString("No analyzed bytea columns.\n")
+		return
+	}
+
+	sb.WriteString("| Column | Avg Width | Null % | Est. Rows | Est. Size |\n")
+	sb.WriteString("|--------|-----------|--------|-----------|-----------|\n")
+	for _, col := range storage.ByteaColumns {
+		writeTableRow(sb, []string{text(col.Schema + "." + col.Table + "." + col.Column), formatBytes(int64(col.AvgWidth)),
+			fmt.Sprintf("%.1f", col.NullFrac*100), fmt.Sprintf("%d", col.EstimatedRows), formatBytes(col.EstimatedBytes())})
+	}
+}
+
+// sizedTables returns the tables with fetched sizes, largest first.
+func sizedTables(schemas []pg.SchemaInfo) []pg.Table {
+	var tables []pg.Table
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			if t.Size != nil {
+				tables = append(tables, t)
+			}
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Size.TotalBytes > tables[j].Size.TotalBytes })
+	return tables
+}
+
+func (r *renderer) renderTableSizes(tables []pg.Table) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "table-sizes", "")
+	sb.WriteString("## Appendix: Table Sizes\n\n")
+	sb.WriteString("Row counts are planner estimates from the last VACUUM or ANALYZE.\n\n")
+
+	sb.WriteString("| Table | Total | Table | Indexes | Est. Rows |\n")
+	sb.WriteString("|-------|-------|-------|---------|-----------|\n")
+	for _, t := range tables {
+		writeTableRow(sb, []string{text(t.Schema + "." + t.Name),
+			formatBytes(t.Size.TotalBytes), formatBytes(t.Size.TableBytes), formatBytes(t.Size.IndexBytes), formatCount(t.Size.EstimatedRows)})
+	}
+}
+
+// formatCount groups the digits of n in thousands, e.g. "1,234,567".
+func formatCount(n int64) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// renderExternalReferences lists foreign keys whose target is not in the
+// document, so readers know which other schemas these tables depend on.
+func (r *renderer) renderExternalReferences(refs []graph.ExternalReference) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "external-references", "")
+	sb.WriteString("## Appendix: External References\n\n")
+
+	if len(refs) == 0 {
+		sb.WriteString("No foreign keys reference tables outside the documented schemas.\n")
+		return
+	}
+
+	writeTableHeader(sb, []string{"Target", "Referenced By", "Constraint"})
+	for _, ref := range refs {
+		target := fmt.Sprintf("%s (%s)", text(ref.Target), text(strings.Join(ref.TargetColumns, ", ")))
+		source := fmt.Sprintf("%s (%s)", text(ref.Source), text(strings.Join(ref.SourceColumns, ", ")))
+		schema, table, _ := strings.Cut(ref.Source, ".")
+		if link := r.link("table", schema, table); link != "" {
+			source = fmt.Sprintf("[%s](%s) (%s)", text(ref.Source), link, text(strings.Join(ref.SourceColumns, ", ")))
+		}
+		writeTableRow(sb, []string{target, source, code(ref.Constraint)})
+	}
+}
@@ -0,0 +1,361 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRenderDatabase_Storage(t *testing.T) {
+	db := pg.Database{
+		Schemas: []pg.SchemaInfo{{Name: "public"}},
+		Storage: &pg.Storage{
+			LargeObjects: 3,
+			ByteaColumns: []pg.ByteaColumn{
+				{Schema: "public", Table: "files", Column: "data", AvgWidth: 2048, NullFrac: 0.5, EstimatedRows: 1000},
+			},
+		},
+	}
+
+	result := RenderDatabase(db, Options{})
+
+	if !strings.Contains(result, "## Appendix: Storage") {
+		t.Error("expected storage appendix not found")
+	}
+	if !strings.Contains(result, "**Large objects:** 3") {
+		t.Error("expected large object count not found")
+	}
+	if !strings.Contains(result, "| public.files.data | 2.0 KiB | 50.0 | 1000 | 1000.0 KiB |") {
+		t.Errorf("expected bytea column row not found:\n%s", result)
+	}
+
+	if strings.Contains(Render(db.Schemas, Options{}), "Appendix") {
+		t.Error("unexpected appendix without storage data")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.expected {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1234567, "1,234,567"},
+		{-45000, "-45,000"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCount(tt.n); got != tt.expected {
+			t.Errorf("formatCount(%d) = %q, want %q", tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderDatabase_TableSizes(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Tables: []pg.Table{
+			{Schema: "public", Name: "events", Size: &pg.TableSize{TotalBytes: 3 * 1024 * 1024, TableBytes: 2 * 1024 * 1024, IndexBytes: 1024 * 1024, EstimatedRows: 1250000}},
+			{Schema: "public", Name: "users", Size: &pg.TableSize{TotalBytes: 16384, TableBytes: 8192, IndexBytes: 8192, EstimatedRows: 42}},
+			{Schema: "public", Name: "tags"},
+		},
+	}}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "**Size:** 3.0 MiB total (table 2.0 MiB, indexes 1.0 MiB), ~1,250,000 rows") {
+		t.Errorf("expected table size line not found:\n%s", result)
+	}
+	if !strings.Contains(result, "## Appendix: Table Sizes") {
+		t.Fatal("expected table sizes appendix not found")
+	}
+	events := strings.Index(result, "| public.events | 3.0 MiB | 2.0 MiB | 1.0 MiB | 1,250,000 |")
+	users := strings.Index(result, "| public.users | 16.0 KiB | 8.0 KiB | 8.0 KiB | 42 |")
+	if events < 0 || users < events {
+		t.Errorf("expected tables largest first:\n%s", result)
+	}
+	if strings.Contains(result, "| public.tags |") {
+		t.Error("tables without sizes should be left out of the appendix")
+	}
+}
+
+func TestRenderDatabase_ForeignKeyCycles(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "employees", Columns: []pg.Column{
+					{Name: "manager_id", FKRef: "public.employees.id"},
+					{Name: "dept_id", FKRef: "public.departments.id"},
+				}},
+				{Schema: "public", Name: "departments", Columns: []pg.Column{
+					{Name: "head_id", FKRef: "public.employees.id"},
+				}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "## Foreign Key Cycles") {
+		t.Error("expected FK cycles section not found")
+	}
+	if !strings.Contains(result, "- `public.departments` → `public.employees` → `public.departments`") {
+		t.Error("expected cycle path not found")
+	}
+	if !strings.Contains(result, "- `public.employees`.`manager_id`") {
+		t.Error("expected self-reference not found")
+	}
+
+	if strings.Contains(Render([]pg.SchemaInfo{{Name: "public"}}, Options{}), "Foreign Key Cycles") {
+		t.Error("unexpected FK cycles section without cycles")
+	}
+}
+
+func TestRender_IdentifierHazards(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "order", Columns: []pg.Column{{Name: "UserId"}}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{IdentifierHazards: true})
+
+	if !strings.Contains(result, "## Identifier Hazards") {
+		t.Error("expected hazards section not found")
+	}
+	if !strings.Contains(result, "| table public.order | `order` | ⚠ reserved word in PostgreSQL |") {
+		t.Error("expected reserved word warning not found")
+	}
+	if !strings.Contains(result, "| column public.order.UserId | `UserId` | ⚠ requires quoting |") {
+		t.Error("expected quoting warning not found")
+	}
+
+	if strings.Contains(Render(schemas, Options{}), "Identifier Hazards") {
+		t.Error("unexpected hazards section without the option")
+	}
+}
+
+func TestRender_Portability(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:  "public",
+					Name:    "events",
+					Columns: []pg.Column{{Name: "payload", Type: "jsonb", UDTName: "jsonb"}},
+					Indexes: []pg.Index{{Name: "events_recent", Columns: []string{"payload"}, Predicate: "(id > 100)"}},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{Portability: true})
+
+	if !strings.Contains(result, "| public.events | jsonb (payload); partial indexes (events_recent) |") {
+		t.Errorf("expected portability row not found:\n%s", result)
+	}
+	if !strings.Contains(result, "events_recent (payload, WHERE (id > 100))") {
+		t.Error("expected partial index predicate in index list")
+	}
+}
+
+func TestRenderDatabase_Roles(t *testing.T) {
+	db := pg.Database{
+		Schemas: []pg.SchemaInfo{{Name: "public"}},
+		Roles: []pg.Role{
+			{Name: "app", CanLogin: true, ConnLimit: -1, MemberOf: []string{"readers", "writers"}},
+			{Name: "admin", Superuser: true, CreateDB: true, BypassRLS: true, ConnLimit: 5, ValidUntil: "2030-01-01 00:00:00+00"},
+		},
+	}
+
+	result := RenderDatabase(db, Options{})
+
+	if !strings.Contains(result, "## Roles") {
+		t.Error("expected Roles section not found")
+	}
+	if !strings.Contains(result, "| app | yes | no |  | unlimited |  | readers, writers |") {
+		t.Error("expected login role row not found")
+	}
+	if !strings.Contains(result, "| admin | no | yes | CREATEDB, BYPASSRLS | 5 | 2030-01-01 00:00:00+00 |  |") {
+		t.Error("expected superuser role row not found")
+	}
+}
+
+func TestRenderDatabase_Extensions(t *testing.T) {
+	db := pg.Database{
+		Schemas: []pg.SchemaInfo{{Name: "public"}},
+		Extensions: []pg.Extension{
+			{Name: "pgcrypto", Version: "1.3", Schema: "public", Description: "cryptographic functions"},
+			{Name: "postgis", Version: "3.4.2", Schema: "extensions"},
+		},
+	}
+
+	result := RenderDatabase(db, Options{})
+
+	expected := "## Extensions\n\n| Extension | Version | Schema | Description |\n|-----------|---------|--------|-------------|\n| pgcrypto | 1.3 | public | cryptographic functions |\n| postgis | 3.4.2 | extensions |  |\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected extensions table not found:\n%s", result)
+	}
+	if strings.Index(result, "## Extensions") > strings.Index(result, "## Schema: public") {
+		t.Error("expected extensions before the schemas")
+	}
+}
+
+func TestRenderDatabase_Warnings(t *testing.T) {
+	db := pg.Database{Schemas: []pg.SchemaInfo{
+		{Name: "public", Warnings: []pg.Warning{
+			{Kind: pg.WarningSkipped, Object: "archive_orders", Message: "foreign table is not documented"},
+			{Kind: pg.WarningUnsupported, Object: "accounts", Message: "row-level security policies are not documented (2)"},
+		}},
+		{Name: "auth"},
+	}}
+
+	result := RenderDatabase(db, Options{})
+
+	expected := "## Warnings\n\n| Object | Kind | Warning |\n|--------|------|---------|\n" +
+		"| public.archive_orders | skipped | foreign table is not documented |\n" +
+		"| public.accounts | unsupported | row-level security policies are not documented (2) |\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected warnings appendix not found:\n%s", result)
+	}
+
+	if strings.Contains(Render([]pg.SchemaInfo{{Name: "public"}}, Options{}), "## Warnings") {
+		t.Error("expected no warnings appendix without warnings")
+	}
+}
+
+func TestRenderDatabase_Settings(t *testing.T) {
+	db := pg.Database{
+		Schemas: []pg.SchemaInfo{{Name: "public"}},
+		Settings: []pg.Setting{
+			{Name: "search_path", Value: "app, public", Source: "database"},
+			{Name: "TimeZone", Value: "UTC", Source: "configuration file"},
+		},
+	}
+
+	result := RenderDatabase(db, Options{})
+
+	if !strings.Contains(result, "## Appendix: Settings") {
+		t.Error("expected Settings appendix not found")
+	}
+	if !strings.Contains(result, "| search_path | app, public | database |") {
+		t.Error("expected search_path row not found")
+	}
+}
+
+func TestRender_EncryptedColumns(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users", Columns: []pg.Column{
+					{Name: "id", Type: "integer"},
+					{Name: "ssn_encrypted", Type: "bytea"},
+				}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{EncryptedColumns: true})
+	if !strings.Contains(result, "## Encrypted Data\n\n| Column | Evidence |\n") {
+		t.Errorf("expected encrypted data appendix not found:\n%s", result)
+	}
+	if !strings.Contains(result, "| public.users.ssn_encrypted | name suggests ciphertext |") {
+		t.Error("expected encrypted column row not found")
+	}
+
+	if strings.Contains(Render(schemas, Options{}), "Encrypted Data") {
+		t.Error("encrypted data appendix should be opt-in")
+	}
+}
+
+func TestRender_AuditColumns(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users", Columns: []pg.Column{{Name: "created_at"}, {Name: "updated_at"}}},
+				{Schema: "public", Name: "tags", Columns: []pg.Column{{Name: "created_at"}}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{AuditColumns: []string{"created_at", "updated_at"}})
+
+	if !strings.Contains(result, "## Audit Columns\n\n1 of 2 tables have all of: `created_at`, `updated_at`.") {
+		t.Errorf("expected audit summary not found:\n%s", result)
+	}
+	if !strings.Contains(result, "| Table | created_at | updated_at |\n") {
+		t.Error("expected convention header not found")
+	}
+	if !strings.Contains(result, "| public.tags | ✓ | ✗ |") {
+		t.Error("expected non-compliant row not found")
+	}
+}
+
+func TestRenderDatabase_Glossary(t *testing.T) {
+	db := pg.Database{Schemas: []pg.SchemaInfo{{
+		Name: "public",
+		Tables: []pg.Table{
+			{
+				Schema:      "public",
+				Name:        "products",
+				Description: "Things we sell.",
+				Terms:       []pg.Term{{Name: "SKU", Definition: "stock keeping unit"}},
+			},
+			{
+				Schema:  "public",
+				Name:    "order_items",
+				Columns: []pg.Column{{Name: "sku", Type: "text", Description: "The SKU ordered."}},
+			},
+			{Schema: "public", Name: "skus_archive", Description: "Old skus_v1 rows."},
+		},
+		Functions: []pg.Function{{Schema: "public", Name: "restock", Description: "Refills one sku."}},
+	}}}
+
+	result := RenderDatabase(db, Options{Anchors: true})
+
+	for _, want := range []string{
+		"## Glossary",
+		"| **SKU** | stock keeping unit | [products](#table-public-products) | [order_items.sku](#table-public-order_items), [restock()](#function-public-restock) |",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "[skus_archive]") {
+		t.Error("terms should only match whole words")
+	}
+
+	db.Schemas[0].Tables[0].Terms = nil
+	if strings.Contains(RenderDatabase(db, Options{}), "## Glossary") {
+		t.Error("unexpected glossary without terms")
+	}
+}
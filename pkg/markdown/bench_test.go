@@ -0,0 +1,21 @@
+package markdown
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/internal/synthetic"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func BenchmarkRenderDatabase(b *testing.B) {
+	for _, tables := range []int{100, 1000, 5000} {
+		db := pg.Database{Schemas: synthetic.Schemas(1, tables)}
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				RenderDatabase(db, Options{Anchors: true, Layout: LayoutTable})
+			}
+		})
+	}
+}
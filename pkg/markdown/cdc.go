@@ -0,0 +1,83 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func (r *renderer) renderCDCOverview(schemas []pg.SchemaInfo) {
+	sb := r.sb
+	r.writeAnchor("cdc", "overview", "")
+	sb.WriteString("## CDC Overview\n\n")
+	sb.WriteString("| Table | Primary Key | Replica Identity | Publications | Status |\n")
+	sb.WriteString("|-------|-------------|------------------|--------------|--------|\n")
+
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			pubs := text(strings.Join(table.Publications, ", "))
+			if pubs == "" {
+				pubs = "—"
+			}
+			pk := text(strings.Join(primaryKeyColumns(table), ", "))
+			if pk == "" {
+				pk = "—"
+			}
+			writeTableRow(sb, []string{text(table.Schema + "." + table.Name), pk, replicaIdentityLabel(table), pubs, cdcStatus(table)})
+		}
+	}
+
+	sb.WriteString("\n---\n\n")
+}
+
+func (r *renderer) renderReplication(table pg.Table) {
+	fmt.Fprintf(r.sb, "\n**Replica identity:** %s\n", replicaIdentityLabel(table))
+	if len(table.Publications) > 0 {
+		fmt.Fprintf(r.sb, "\n**Publications:** %s\n", text(strings.Join(table.Publications, ", ")))
+	}
+}
+
+func primaryKeyColumns(table pg.Table) []string {
+	var cols []string
+	for _, col := range table.Columns {
+		if col.IsPK {
+			cols = append(cols, col.Name)
+		}
+	}
+	return cols
+}
+
+func replicaIdentityLabel(table pg.Table) string {
+	if table.ReplicaIdentity == "index" && table.ReplicaIdentityIndex != "" {
+		return fmt.Sprintf("index (%s)", text(table.ReplicaIdentityIndex))
+	}
+	if table.ReplicaIdentity == "" {
+		return "default"
+	}
+	return table.ReplicaIdentity
+}
+
+// cdcStatus summarizes whether logical decoding can emit old-row keys for
+// the table: without a usable replica identity, UPDATE and DELETE on a
+// published table fail.
+func cdcStatus(table pg.Table) string {
+	hasIdentity := true
+	switch table.ReplicaIdentity {
+	case "nothing":
+		hasIdentity = false
+	case "", "default":
+		hasIdentity = len(primaryKeyColumns(table)) > 0
+	}
+
+	switch {
+	case !hasIdentity:
+		return "⚠ no replica identity: UPDATE/DELETE fail when published"
+	case len(table.Publications) == 0:
+		return "not published"
+	case table.ReplicaIdentity == "full":
+		return "ok (full row images)"
+	default:
+		return "ok"
+	}
+}
@@ -0,0 +1,61 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRender_CDCProfile(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:          "public",
+					Name:            "orders",
+					Columns:         []pg.Column{{Name: "id", Type: "bigint", IsPK: true}},
+					ReplicaIdentity: "default",
+					Publications:    []string{"dbz"},
+				},
+				{
+					Schema:          "public",
+					Name:            "logs",
+					Columns:         []pg.Column{{Name: "msg", Type: "text", Nullable: true}},
+					ReplicaIdentity: "default",
+					Publications:    []string{"dbz"},
+				},
+				{
+					Schema:               "public",
+					Name:                 "tags",
+					ReplicaIdentity:      "index",
+					ReplicaIdentityIndex: "tags_name_key",
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{Profile: ProfileCDC})
+
+	if !strings.Contains(result, "## CDC Overview") {
+		t.Error("expected CDC overview not found")
+	}
+	if !strings.Contains(result, "| public.orders | id | default | dbz | ok |") {
+		t.Error("expected ready table row not found")
+	}
+	if !strings.Contains(result, "| public.logs | — | default | dbz | ⚠ no replica identity") {
+		t.Error("expected warning for table without identity")
+	}
+	if !strings.Contains(result, "| public.tags | — | index (tags_name_key) | — | not published |") {
+		t.Error("expected unpublished table row not found")
+	}
+	if !strings.Contains(result, "**Replica identity:** default\n\n**Publications:** dbz") {
+		t.Error("expected per-table replication details")
+	}
+
+	result = Render(schemas, Options{})
+	if strings.Contains(result, "CDC Overview") || strings.Contains(result, "**Publications:**") {
+		t.Error("unexpected CDC details without the profile")
+	}
+}
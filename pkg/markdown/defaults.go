@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	castPattern    = regexp.MustCompile(`(?i)::("[^"]+"|[a-z_][a-z0-9_.]*)( (varying|precision|with time zone|without time zone))?(\(\d+(,\s*\d+)?\))?(\[\])*`)
+	nextvalPattern = regexp.MustCompile(`^nextval\('(?:[a-zA-Z_][a-zA-Z0-9_]*\.)?("[^"]+"|[^']+)'\)$`)
+)
+
+// SimplifyDefault strips the noise PostgreSQL adds when it deparses a
+// default: type casts outside string literals, pg_catalog qualification and
+// the regclass wrapping of sequence names. The result is meant for display
+// only and is not guaranteed to be valid SQL.
+func SimplifyDefault(def string) string {
+	var sb strings.Builder
+	inQuote := false
+	start := 0
+	flush := func(end int) {
+		seg := def[start:end]
+		if !inQuote {
+			seg = castPattern.ReplaceAllString(seg, "")
+			seg = strings.ReplaceAll(seg, "pg_catalog.", "")
+		}
+		sb.WriteString(seg)
+		start = end
+	}
+	for i := 0; i < len(def); i++ {
+		if def[i] != '\'' {
+			continue
+		}
+		if inQuote {
+			// A doubled quote is an escaped quote inside the literal.
+			if i+1 < len(def) && def[i+1] == '\'' {
+				i++
+				continue
+			}
+			flush(i + 1)
+			inQuote = false
+		} else {
+			flush(i)
+			inQuote = true
+		}
+	}
+	flush(len(def))
+
+	simplified := sb.String()
+	if m := nextvalPattern.FindStringSubmatch(simplified); m != nil {
+		return "nextval(" + m[1] + ")"
+	}
+	return simplified
+}
+
+// renderDefault formats a default for the constraints column, keeping the
+// raw expression in a hover title when simplification changed it.
+func (r *renderer) renderDefault(def string) string {
+	if !r.opts.SimplifyDefaults {
+		return text(def)
+	}
+	simplified := SimplifyDefault(def)
+	if simplified == def {
+		return text(def)
+	}
+	title := strings.ReplaceAll(html.EscapeString(singleLine(def)), "|", "&#124;")
+	return `<span title="` + title + `">` + text(simplified) + `</span>`
+}
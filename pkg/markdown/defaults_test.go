@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestSimplifyDefault(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"'active'::text", "'active'"},
+		{"'draft'::character varying", "'draft'"},
+		{"'{}'::jsonb", "'{}'"},
+		{"'{}'::text[]", "'{}'"},
+		{"'a::b'::text", "'a::b'"},
+		{"'it''s'::text", "'it''s'"},
+		{"0.00::numeric(10,2)", "0.00"},
+		{"pg_catalog.now()", "now()"},
+		{"now()", "now()"},
+		{"(now() AT TIME ZONE 'utc'::text)", "(now() AT TIME ZONE 'utc')"},
+		{"'2020-01-01 00:00:00'::timestamp without time zone", "'2020-01-01 00:00:00'"},
+		{"nextval('users_id_seq'::regclass)", "nextval(users_id_seq)"},
+		{"nextval('public.users_id_seq'::regclass)", "nextval(users_id_seq)"},
+		{"'pending'::public.status", "'pending'"},
+		{"gen_random_uuid()", "gen_random_uuid()"},
+	}
+
+	for _, tt := range tests {
+		if got := SimplifyDefault(tt.input); got != tt.expected {
+			t.Errorf("SimplifyDefault(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestRender_SimplifyDefaults(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "bigint", IsPK: true, Default: "nextval('users_id_seq'::regclass)"},
+						{Name: "created_at", Type: "timestamp with time zone", Default: "now()", Nullable: true},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{SimplifyDefaults: true})
+
+	if !strings.Contains(result, `DEFAULT <span title="nextval(&#39;users_id_seq&#39;::regclass)">nextval(users_id_seq)</span>`) {
+		t.Errorf("expected simplified default with raw title not found:\n%s", result)
+	}
+	if !strings.Contains(result, "| created_at | timestamp with time zone | DEFAULT now() |") {
+		t.Error("expected unchanged default rendered plainly")
+	}
+
+	if !strings.Contains(Render(schemas, Options{}), "DEFAULT nextval('users_id_seq'::regclass)") {
+		t.Error("expected raw default without the option")
+	}
+}
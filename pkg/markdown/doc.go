@@ -0,0 +1,9 @@
+// Package markdown renders a pg schema model as Markdown.
+//
+// RenderDatabase produces a single document including database-wide
+// sections, Render does the same for schemas alone, and RenderFiles
+// splits the document into linked pages. RenderSchema, RenderTable and
+// RenderView render fragments for embedding in hand-written docs. Options
+// toggles optional sections and layout; its zero value gives the default
+// output.
+package markdown
@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Identifiers, defaults, enum values and comments come from the database
+// and may contain anything, so every one of them passes through one of
+// the helpers below before it reaches the document. Pipes are escaped by
+// writeTableRow, since a pipe ends a table cell even inside a code span.
+
+// entityPattern matches an HTML character reference such as &amp; or &#124;.
+var entityPattern = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// text escapes s for use as literal inline text, e.g. an object name in a
+// heading, list item or table cell, so that it cannot start emphasis, a
+// link or an image. Line breaks become spaces.
+func text(s string) string {
+	var b strings.Builder
+	s = singleLine(s)
+	prev := rune(-1)
+	for i, c := range s {
+		switch c {
+		case '\\', '`', '*', '#', '[', ']', '!':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case '_':
+			// An underscore inside a word such as user_id cannot start
+			// emphasis; elsewhere it might.
+			if !isWordRune(prev) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(c)
+		case '<':
+			b.WriteString("&lt;")
+		case '&':
+			if entityPattern.MatchString(s[i:]) {
+				b.WriteString("&amp;")
+			} else {
+				b.WriteRune(c)
+			}
+		default:
+			b.WriteRune(c)
+		}
+		prev = c
+	}
+	return b.String()
+}
+
+// prose makes a comment safe to embed while keeping any Markdown it was
+// written in: only raw HTML is neutralized.
+func prose(s string) string {
+	return strings.ReplaceAll(s, "<", "&lt;")
+}
+
+// cellProse is prose folded onto one line for a table cell.
+func cellProse(s string) string {
+	return prose(oneLine(s))
+}
+
+// code returns s as an inline code span, using a longer run of backticks
+// than any inside s. Line breaks become spaces.
+func code(s string) string {
+	s = singleLine(s)
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// codeFence returns a fence for a code block holding s.
+func codeFence(s string) string {
+	return strings.Repeat("`", max(3, longestRun(s, '`')+1))
+}
+
+// escapePipes makes s safe inside a table cell.
+func escapePipes(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func singleLine(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return s
+	}
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
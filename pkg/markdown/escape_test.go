@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"users", "users"},
+		{"user_id", "user_id"},
+		{"_id", `\_id`},
+		{"a__b", `a_\_b`},
+		{"*starred*", `\*starred\*`},
+		{"`tick`", "\\`tick\\`"},
+		{"<script>", "&lt;script>"},
+		{"a&amp;b", "a&amp;amp;b"},
+		{"R&D", "R&D"},
+		{"#hash", `\#hash`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", "two lines"},
+		{"text[]", `text\[\]`},
+		{"[x](https://evil)", `\[x\](https://evil)`},
+		{"![img](x)", `\!\[img\](x)`},
+	}
+
+	for _, tt := range tests {
+		if got := text(tt.input); got != tt.expected {
+			t.Errorf("text(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"now()", "`now()`"},
+		{"a`b", "``a`b``"},
+		{"`x`", "`` `x` ``"},
+		{"a``b", "```a``b```"},
+		{"one\ntwo", "`one two`"},
+	}
+
+	for _, tt := range tests {
+		if got := code(tt.input); got != tt.expected {
+			t.Errorf("code(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	if got := codeFence("SELECT 1"); got != "```" {
+		t.Errorf("codeFence() = %q, want ```", got)
+	}
+	if got := codeFence("SELECT '````'"); got != "`````" {
+		t.Errorf("codeFence() = %q, want `````", got)
+	}
+}
+
+func TestRender_NastyIdentifiers(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:      "public",
+					Name:        "odd|table",
+					Description: "Holds <b>odd</b> things.",
+					Columns: []pg.Column{
+						{Name: "a|b", Type: "text", Nullable: true, Default: "'x|y'::text", Description: "pipe | in\nthe comment"},
+						{Name: "`tick`", Type: "text", Nullable: true},
+						{Name: "_id", Type: "integer"},
+						{Name: "line\nbreak", Type: "text", Nullable: true, Description: "<script>alert(1)</script>"},
+						{Name: "[x](https://evil)", Type: "text", Nullable: true},
+					},
+					Checks: []pg.CheckConstraint{
+						{Name: "odd_check", Definition: "CHECK ((\"a|b\" <> '`'::text))"},
+					},
+				},
+			},
+			Types: []pg.CustomType{
+				{Schema: "public", Name: "mood", Kind: "enum", Values: []string{"so|so", "<meh>"}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	for _, line := range strings.Split(result, "\n") {
+		if !strings.HasPrefix(line, "| ") {
+			continue
+		}
+		if cells := strings.Count(strings.ReplaceAll(line, `\|`, ""), "|") - 1; cells != 4 {
+			t.Errorf("row has %d cells: %q", cells, line)
+		}
+	}
+
+	for _, want := range []string{
+		`#### odd|table`,
+		"Holds &lt;b>odd&lt;/b> things.",
+		`| a\|b | text | DEFAULT 'x\|y'::text | pipe \| in the comment |`,
+		"| \\`tick\\` | text |  |  |",
+		`| \_id | integer | NOT NULL |  |`,
+		"| line break | text |  | &lt;script>alert(1)&lt;/script> |",
+		`| \[x\](https://evil) | text |  |  |`,
+		"- `odd_check`: ``CHECK ((\"a|b\" <> '`'::text))``",
+		"- `mood`: 'so|so', '&lt;meh>'",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "<script>") {
+		t.Error("raw HTML from a comment reached the output")
+	}
+	if strings.Contains(result, "[x](https://evil)") {
+		t.Error("a link-shaped identifier reached the output as a link")
+	}
+}
@@ -0,0 +1,31 @@
+package markdown_test
+
+import (
+	"fmt"
+
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func ExampleRenderTable() {
+	table := pg.Table{
+		Schema:      "public",
+		Name:        "users",
+		Description: "Registered accounts",
+		Columns: []pg.Column{
+			{Name: "id", Type: "bigint", IsPK: true},
+			{Name: "email", Type: "text", IsUnique: true},
+		},
+	}
+
+	fmt.Print(markdown.RenderTable(table, markdown.Options{}))
+	// Output:
+	// #### users
+	//
+	// Registered accounts
+	//
+	// | Column | Type | Constraints |
+	// |--------|------|-------------|
+	// | id | bigint | PK, NOT NULL |
+	// | email | text | NOT NULL, UNIQUE |
+}
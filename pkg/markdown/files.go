@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// IndexFile is the entry page written by RenderFiles.
+const IndexFile = "index.md"
+
+// RenderFiles splits the document into pages keyed by slash-separated
+// path: an index holding the header, links to each schema and the
+// appendices, and one page per schema. With perTable, every table also
+// gets its own page in a directory named after its schema, linked from
+// the schema page and back.
+func RenderFiles(db pg.Database, opts Options, perTable bool) map[string]string {
+	files := make(map[string]string)
+
+	var sb strings.Builder
+	// multiPage lets appendices such as the glossary link into schema
+	// pages.
+	r := &renderer{sb: &sb, opts: opts, multiPage: true}
+	r.indexSchemas(db.Schemas)
+	if perTable {
+		r.tableLink = func(t pg.Table) string { return tableFile(t) }
+	}
+	r.renderHeader(db)
+	if len(db.Schemas) > 0 {
+		sb.WriteString("## Schemas\n\n")
+		for _, schema := range db.Schemas {
+			fmt.Fprintf(&sb, "- [%s](%s)\n", text(schema.Name), schemaFile(schema.Name))
+		}
+	}
+	r.renderAppendices(db)
+	files[IndexFile] = sb.String()
+
+	for _, schema := range db.Schemas {
+		var sb strings.Builder
+		r := &renderer{sb: &sb, opts: opts, multiPage: true}
+		r.indexFunctions(db.Schemas)
+		r.indexSchemas(db.Schemas)
+		r.indexTypes(db.Schemas)
+		fmt.Fprintf(&sb, "[← Index](%s)\n\n", IndexFile)
+		if perTable {
+			r.tableLink = func(t pg.Table) string { return tableFile(t) }
+		}
+		r.renderSchema(schema)
+		files[schemaFile(schema.Name)] = sb.String()
+
+		if !perTable {
+			continue
+		}
+		// Reuse r so tables keep what renderSchema attached to them.
+		r.pageDir = "../"
+		tables, _ := limit(schema.Tables, opts.MaxTablesPerSchema)
+		for _, table := range tables {
+			sb.Reset()
+			fmt.Fprintf(&sb, "[← %s](../%s)\n\n", text(schema.Name), schemaFile(schema.Name))
+			r.renderTable(table)
+			files[tableFile(table)] = sb.String()
+		}
+	}
+
+	return files
+}
+
+func schemaFile(schema string) string {
+	return fileSlug(schema) + ".md"
+}
+
+func tableFile(t pg.Table) string {
+	return fileSlug(t.Schema) + "/" + fileSlug(t.Name) + ".md"
+}
+
+// fileSlug turns a name into a path element. Like AnchorID, it adds a
+// hash of the exact name whenever the slug loses something, so "Order
+// Items", "order items" and "order-items" get pages of their own and a
+// name with no Latin letters or digits still gets a file name.
+func fileSlug(name string) string {
+	slug := slugify(name)
+	if plainSlug(name) {
+		return slug
+	}
+	if slug != "" {
+		slug += "-"
+	}
+	return slug + nameHash(name)
+}
@@ -0,0 +1,101 @@
+package markdown
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRenderFiles(t *testing.T) {
+	db := pg.Database{
+		Schemas: []pg.SchemaInfo{
+			{
+				Name: "public",
+				Tables: []pg.Table{
+					{Schema: "public", Name: "users", Columns: []pg.Column{{Name: "id", Type: "integer"}}},
+				},
+				Triggers: []pg.Trigger{{Schema: "public", Table: "users", Name: "audit", Timing: "AFTER", Event: "INSERT", Function: "log_change"}},
+			},
+			{Name: "Auth"},
+		},
+		Roles: []pg.Role{{Name: "app", CanLogin: true}},
+	}
+
+	t.Run("per schema", func(t *testing.T) {
+		files := RenderFiles(db, Options{}, false)
+
+		var paths []string
+		for p := range files {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		if strings.Join(paths, " ") != "auth-156bf46f.md index.md public.md" {
+			t.Errorf("unexpected files %v", paths)
+		}
+
+		index := files[IndexFile]
+		if !strings.Contains(index, "## Schemas\n\n- [public](public.md)\n- [Auth](auth-156bf46f.md)\n") {
+			t.Errorf("expected schema links in index:\n%s", index)
+		}
+		if !strings.Contains(index, "## Roles") {
+			t.Error("expected appendices in index")
+		}
+		if !strings.HasPrefix(files["public.md"], "[← Index](index.md)\n\n## Schema: public") {
+			t.Errorf("unexpected schema page:\n%s", files["public.md"])
+		}
+		if !strings.Contains(files["public.md"], "#### users") {
+			t.Error("expected table inline on schema page")
+		}
+	})
+
+	t.Run("per table", func(t *testing.T) {
+		files := RenderFiles(db, Options{Layout: LayoutTable}, true)
+
+		if !strings.Contains(files["public.md"], "### Tables\n\n- [users](public/users.md)\n") {
+			t.Errorf("expected table link on schema page:\n%s", files["public.md"])
+		}
+		page, ok := files["public/users.md"]
+		if !ok {
+			t.Fatal("expected table page")
+		}
+		if !strings.HasPrefix(page, "[← public](../public.md)\n\n#### users") {
+			t.Errorf("unexpected table page:\n%s", page)
+		}
+		if !strings.Contains(page, "**Triggers:**") {
+			t.Error("expected attached trigger on table page")
+		}
+	})
+}
+
+func TestRenderFiles_CollidingNames(t *testing.T) {
+	db := pg.Database{
+		Schemas: []pg.SchemaInfo{{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "order items"},
+				{Schema: "public", Name: "order-items"},
+				{Schema: "public", Name: "χρήστες"},
+			},
+		}},
+	}
+
+	files := RenderFiles(db, Options{}, true)
+
+	var paths []string
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	want := "index.md public.md public/039ba032.md public/order-items-5c70027a.md public/order-items-e8fb507f.md"
+	if strings.Join(paths, " ") != want {
+		t.Errorf("files = %v, want %s", paths, want)
+	}
+	for _, name := range []string{"order items", "order-items", "χρήστες"} {
+		page := files[tableFile(pg.Table{Schema: "public", Name: name})]
+		if !strings.Contains(page, "#### "+text(name)+"\n") {
+			t.Errorf("page for %q does not hold its table:\n%s", name, page)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// RenderSchema renders one schema section without the document header or
+// appendices, for embedding in other pages. To render a subset of
+// objects, pass a SchemaInfo holding only those objects.
+func RenderSchema(schema pg.SchemaInfo, opts Options) string {
+	var sb strings.Builder
+	r := &renderer{sb: &sb, opts: opts}
+	r.indexFunctions([]pg.SchemaInfo{schema})
+	r.indexTypes([]pg.SchemaInfo{schema})
+	r.renderSchema(schema)
+	return sb.String()
+}
+
+// RenderTable renders a single table section. Triggers and sequences are
+// only placed inside tables by RenderSchema with LayoutTable.
+func RenderTable(table pg.Table, opts Options) string {
+	var sb strings.Builder
+	r := &renderer{sb: &sb, opts: opts}
+	r.renderTable(table)
+	return sb.String()
+}
+
+// RenderView renders a single view section.
+func RenderView(view pg.View, opts Options) string {
+	var sb strings.Builder
+	r := &renderer{sb: &sb, opts: opts}
+	r.renderView(view)
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRenderFragments(t *testing.T) {
+	table := pg.Table{
+		Schema:  "public",
+		Name:    "users",
+		Columns: []pg.Column{{Name: "id", Type: "integer", IsPK: true}},
+	}
+	view := pg.View{Schema: "public", Name: "active_users", Columns: []pg.Column{{Name: "id", Type: "integer"}}}
+	schema := pg.SchemaInfo{Name: "public", Tables: []pg.Table{table}, Views: []pg.View{view}}
+
+	tests := []struct {
+		name     string
+		result   string
+		expected string
+	}{
+		{"table", RenderTable(table, Options{}), "#### users\n\n| Column | Type | Constraints |"},
+		{"view", RenderView(view, Options{}), "#### active_users\n\n| Column | Type |"},
+		{"schema", RenderSchema(schema, Options{}), "## Schema: public\n\n### Tables\n\n#### users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.HasPrefix(tt.result, tt.expected) {
+				t.Errorf("expected fragment to start with %q, got:\n%s", tt.expected, tt.result)
+			}
+			if strings.Contains(tt.result, "# Database Schema Documentation") {
+				t.Error("fragment should not include the document header")
+			}
+		})
+	}
+
+	if !strings.HasPrefix(RenderTable(table, Options{Anchors: true}), `<a id="table-public-users"></a>`) {
+		t.Error("expected anchor to be kept in table fragment")
+	}
+}
@@ -0,0 +1,140 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// glossaryEntry is a term with the object defining it and the other
+// objects whose comments mention it.
+type glossaryEntry struct {
+	term      pg.Term
+	definedIn glossaryRef
+	usedIn    []glossaryRef
+	mentioned *regexp.Regexp
+}
+
+// glossaryRef points at a described object; column is set for a column
+// of that object. name is the anchor name, which for functions includes
+// the arguments.
+type glossaryRef struct {
+	kind, schema, name, label, column string
+}
+
+// describedObject is one comment-bearing object walked by collectGlossary.
+type describedObject struct {
+	ref         glossaryRef
+	description string
+	terms       []pg.Term
+}
+
+// describedObjects lists every table, view, materialized view, function
+// and type in schemas, each followed by its columns.
+func describedObjects(schemas []pg.SchemaInfo) []describedObject {
+	var objects []describedObject
+	add := func(kind, schema, name, label, description string, terms []pg.Term, columns []pg.Column) {
+		ref := glossaryRef{kind: kind, schema: schema, name: name, label: label}
+		objects = append(objects, describedObject{ref, description, terms})
+		for _, col := range columns {
+			colRef := ref
+			colRef.column = col.Name
+			objects = append(objects, describedObject{colRef, col.Description, col.Terms})
+		}
+	}
+
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			add("table", t.Schema, t.Name, t.Name, t.Description, t.Terms, t.Columns)
+		}
+		for _, v := range s.Views {
+			add("view", v.Schema, v.Name, v.Name, v.Description, v.Terms, v.Columns)
+		}
+		for _, mv := range s.MaterializedViews {
+			add("matview", mv.Schema, mv.Name, mv.Name, mv.Description, mv.Terms, mv.Columns)
+		}
+		for _, fn := range s.Functions {
+			add(fn.ObjectType(), fn.Schema, functionAnchorName(fn), fn.Name+"()", fn.Description, fn.Terms, nil)
+		}
+		for _, t := range s.Types {
+			add("type", t.Schema, t.Name, t.Name, t.Description, t.Terms, t.Attributes)
+		}
+	}
+	return objects
+}
+
+// collectGlossary gathers the @term definitions in schemas, sorted by
+// term. A term defined more than once keeps its first definition, and
+// every object whose comment mentions a term as a whole word, ignoring
+// case, is listed as using it.
+func collectGlossary(schemas []pg.SchemaInfo) []*glossaryEntry {
+	objects := describedObjects(schemas)
+
+	var entries []*glossaryEntry
+	byName := make(map[string]*glossaryEntry)
+	for _, obj := range objects {
+		for _, term := range obj.terms {
+			key := strings.ToLower(term.Name)
+			if byName[key] != nil {
+				continue
+			}
+			e := &glossaryEntry{
+				term:      term,
+				definedIn: obj.ref,
+				mentioned: regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(term.Name) + `($|\W)`),
+			}
+			byName[key] = e
+			entries = append(entries, e)
+		}
+	}
+
+	for _, e := range entries {
+		for _, obj := range objects {
+			if obj.ref != e.definedIn && e.mentioned.MatchString(obj.description) {
+				e.usedIn = append(e.usedIn, obj.ref)
+			}
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b *glossaryEntry) int {
+		return strings.Compare(strings.ToLower(a.term.Name), strings.ToLower(b.term.Name))
+	})
+	return entries
+}
+
+func (r *renderer) renderGlossary(entries []*glossaryEntry) {
+	sb := r.sb
+	sb.WriteString("\n---\n\n")
+	r.writeAnchor("appendix", "glossary", "")
+	sb.WriteString("## Glossary\n\n")
+	sb.WriteString("| Term | Definition | Defined In | Used In |\n")
+	sb.WriteString("|------|------------|------------|---------|\n")
+
+	for _, e := range entries {
+		used := make([]string, len(e.usedIn))
+		for i, ref := range e.usedIn {
+			used[i] = r.glossaryLink(ref)
+		}
+		writeTableRow(sb, []string{"**" + text(e.term.Name) + "**", cellProse(e.term.Definition), r.glossaryLink(e.definedIn), strings.Join(used, ", ")})
+	}
+}
+
+// glossaryLink names ref, linking to its object when anchors are on.
+func (r *renderer) glossaryLink(ref glossaryRef) string {
+	name := r.qualify(ref.schema, ref.label)
+	if ref.column != "" {
+		name += "." + ref.column
+	}
+
+	link := r.link(ref.kind, ref.schema, ref.name)
+	if ref.kind == "table" && r.tableLink != nil {
+		link = r.tableLink(pg.Table{Schema: ref.schema, Name: ref.name})
+	}
+	if link == "" {
+		return code(name)
+	}
+	return fmt.Sprintf("[%s](%s)", text(name), link)
+}
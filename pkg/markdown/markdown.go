@@ -0,0 +1,1768 @@
+package markdown
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/internal/analysis"
+	"github.com/sotirismorf/pgmd/internal/graph"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+type Options struct {
+	// Anchors emits an explicit HTML anchor before every object so other
+	// documents can deep-link to it. IDs depend only on the object's own
+	// kind, schema and name.
+	Anchors bool
+
+	// ColumnTypeFilters hides columns of the given types in matching
+	// relations.
+	ColumnTypeFilters []ColumnTypeFilter
+
+	// SummarizeExcludedColumns replaces hidden columns with a single row
+	// per type stating how many were omitted.
+	SummarizeExcludedColumns bool
+
+	// GroupFunctionsByPrefix splits the Functions section into sub-headings
+	// by name prefix (e.g. auth_*, billing_*).
+	GroupFunctionsByPrefix bool
+
+	// TableChapters groups tables into chapters with their own heading
+	// and table of contents. A table goes in the first chapter with a
+	// matching pattern; the rest are listed under "Other Tables".
+	TableChapters []Chapter
+
+	// FunctionArgTables renders each function's parameters as a table of
+	// name, type, mode and default instead of an inline signature.
+	FunctionArgTables bool
+
+	// FunctionCalls lists under each function the documented functions
+	// its body calls and those calling it. It needs the definitions
+	// fetched with pg.FetchOptions.FunctionBodies.
+	FunctionCalls bool
+
+	// ExpandTypes lists the attributes of composite-typed table and view
+	// columns, arrays of composites included, beneath the column.
+	ExpandTypes bool
+
+	// QualifyNames prefixes object names with their schema in headings and
+	// references (auth.users instead of users).
+	QualifyNames bool
+
+	// IdentifierHazards appends a report of identifiers that are reserved
+	// words or need quoting.
+	IdentifierHazards bool
+
+	// Portability appends a per-table report of PostgreSQL-specific types
+	// and features.
+	Portability bool
+
+	// ExternalReferences appends a list of the foreign keys that reference
+	// tables outside the documented schemas.
+	ExternalReferences bool
+
+	// SimplifyDefaults shortens deparsed column defaults for display,
+	// keeping the raw expression as a hover title.
+	SimplifyDefaults bool
+
+	// ColumnPositions adds each column's ordinal position.
+	ColumnPositions bool
+
+	// ColumnOrder lists columns in physical (default) or alphabetical
+	// order.
+	ColumnOrder ColumnOrder
+
+	// ClassifyTables badges tables as lookup, junction, event or audit
+	// tables based on naming and structure heuristics.
+	ClassifyTables bool
+
+	// AuditColumns, when set, appends a compliance table showing which
+	// tables lack which of these conventional audit columns.
+	AuditColumns []string
+
+	// EncryptedColumns appends a list of columns that appear to hold
+	// pgcrypto or pgsodium ciphertext.
+	EncryptedColumns bool
+
+	// SoftDelete is a predicate selecting live rows, e.g. "deleted_at IS
+	// NULL". Tables having the column it tests are annotated.
+	SoftDelete string
+
+	// Profile tailors the document to an audience; see ParseProfile.
+	Profile Profile
+
+	// Layout controls where per-table facts such as triggers are placed.
+	Layout Layout
+
+	// MaxTablesPerSchema and SectionLimit cap how many tables, and how
+	// many entries of each other schema section, are rendered; the rest
+	// are replaced by an "N more omitted" marker. 0 means no limit.
+	MaxTablesPerSchema int
+	SectionLimit       int
+
+	// ContentHash, when set, is stamped in the document footer; see
+	// pg.Database.Hash.
+	ContentHash string
+}
+
+// Chapter is a titled group of tables, selected by glob patterns such as
+// "billing_*" matched against the bare table name.
+type Chapter struct {
+	Title    string
+	Patterns []string
+}
+
+func (c Chapter) match(table string) bool {
+	for _, p := range c.Patterns {
+		if ok, _ := path.Match(p, table); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type Layout string
+
+const (
+	// LayoutSchema lists triggers and sequences in schema-level sections.
+	LayoutSchema Layout = "schema"
+	// LayoutTable renders each table's triggers and the sequences it owns
+	// (OWNED BY) or its columns draw from inside the table's own section.
+	LayoutTable Layout = "table"
+)
+
+type ColumnOrder string
+
+const (
+	ColumnOrderPhysical     ColumnOrder = "physical"
+	ColumnOrderAlphabetical ColumnOrder = "alphabetical"
+)
+
+// ParseColumnOrder validates a -column-order flag value; empty means
+// ColumnOrderPhysical.
+func ParseColumnOrder(input string) (ColumnOrder, error) {
+	switch ColumnOrder(input) {
+	case "", ColumnOrderPhysical:
+		return ColumnOrderPhysical, nil
+	case ColumnOrderAlphabetical:
+		return ColumnOrderAlphabetical, nil
+	}
+	return "", fmt.Errorf("unknown column order %q (want physical or alphabetical)", input)
+}
+
+type Profile string
+
+const (
+	ProfileDefault Profile = ""
+	// ProfileCDC adds a change-data-capture overview and per-table replica
+	// identity and publication details.
+	ProfileCDC Profile = "cdc"
+)
+
+// ParseProfile validates a -profile flag value.
+func ParseProfile(input string) (Profile, error) {
+	switch Profile(input) {
+	case ProfileDefault, ProfileCDC:
+		return Profile(input), nil
+	}
+	return "", fmt.Errorf("unknown profile %q (want cdc)", input)
+}
+
+// ParseLayout validates a -layout flag value; empty means LayoutSchema.
+func ParseLayout(input string) (Layout, error) {
+	switch Layout(input) {
+	case "", LayoutSchema:
+		return LayoutSchema, nil
+	case LayoutTable:
+		return LayoutTable, nil
+	}
+	return "", fmt.Errorf("unknown layout %q (want schema or table)", input)
+}
+
+// ColumnTypeFilter hides columns whose type is one of Types in every table,
+// view or materialized view whose name matches TablePattern (a path.Match
+// glob; empty matches everything).
+type ColumnTypeFilter struct {
+	TablePattern string
+	Types        []string
+}
+
+// ParseColumnTypeFilter parses "type,type" or "pattern=type,type".
+func ParseColumnTypeFilter(input string) (ColumnTypeFilter, error) {
+	var f ColumnTypeFilter
+	spec := input
+	if pattern, types, ok := strings.Cut(input, "="); ok {
+		f.TablePattern = strings.TrimSpace(pattern)
+		spec = types
+		if _, err := path.Match(f.TablePattern, ""); err != nil {
+			return f, fmt.Errorf("invalid table pattern %q: %w", f.TablePattern, err)
+		}
+	}
+	for _, t := range strings.Split(spec, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			f.Types = append(f.Types, t)
+		}
+	}
+	if len(f.Types) == 0 {
+		return f, fmt.Errorf("no column types in %q", input)
+	}
+	return f, nil
+}
+
+type renderer struct {
+	sb   *strings.Builder
+	opts Options
+
+	// Per-schema objects attached to their table under LayoutTable.
+	tableTriggers  map[string][]pg.Trigger
+	tableSequences map[string][]pg.Sequence
+	// tableLink, when set, lists tables as links to their own pages
+	// instead of rendering them inline.
+	tableLink func(pg.Table) string
+	// functions holds the anchor IDs of documented functions, so
+	// references to them can become links.
+	functions map[string]bool
+	// firstOverload holds the first linkable overload of each function by
+	// "schema.name", and calls and callers the function call graph by the
+	// same key, with FunctionCalls.
+	firstOverload  map[string]pg.Function
+	calls, callers map[string][]string
+	// multiPage makes links name the target's schema page, reached from
+	// the current page through pageDir.
+	multiPage bool
+	pageDir   string
+	// documented holds the names of the schemas being rendered, so
+	// references to other schemas can be marked; nil for fragments.
+	documented map[string]bool
+	// types holds the enum and composite types the document defines by
+	// "schema.name", so columns can link to them and nested attributes
+	// can be expanded.
+	types map[string]pg.CustomType
+}
+
+func Render(schemas []pg.SchemaInfo, opts Options) string {
+	return RenderDatabase(pg.Database{Schemas: schemas}, opts)
+}
+
+// RenderDatabase renders the schemas followed by any database-wide
+// appendices present in db.
+func RenderDatabase(db pg.Database, opts Options) string {
+	var sb strings.Builder
+	r := &renderer{sb: &sb, opts: opts}
+	r.indexFunctions(db.Schemas)
+	r.indexSchemas(db.Schemas)
+	r.indexTypes(db.Schemas)
+
+	r.renderHeader(db)
+
+	for i, schema := range db.Schemas {
+		if i > 0 {
+			sb.WriteString("\n---\n\n")
+		}
+		r.renderSchema(schema)
+	}
+
+	r.renderAppendices(db)
+
+	return sb.String()
+}
+
+// renderHeader writes the title, any warnings, the extensions the
+// database needs and profile overviews.
+func (r *renderer) renderHeader(db pg.Database) {
+	sb := r.sb
+	sb.WriteString("# Database Schema Documentation\n\n")
+
+	if len(db.Warnings) > 0 {
+		sb.WriteString("> **Incomplete:** parts of the schema could not be read, so this document may be missing objects.\n>\n")
+		for _, w := range db.Warnings {
+			fmt.Fprintf(sb, "> - %s\n", cellProse(w))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(db.Extensions) > 0 {
+		r.renderExtensions(db.Extensions)
+	}
+
+	if r.opts.Profile == ProfileCDC {
+		r.renderCDCOverview(db.Schemas)
+	}
+}
+
+// renderAppendices writes the database-wide sections and footer.
+func (r *renderer) renderAppendices(db pg.Database) {
+	sb := r.sb
+	opts := r.opts
+
+	if len(db.Roles) > 0 {
+		r.renderRoles(db.Roles)
+	}
+
+	if len(db.Settings) > 0 {
+		r.renderSettings(db.Settings)
+	}
+
+	cycles, selfRefs := graph.ForeignKeyCycles(db.Schemas)
+	if len(cycles) > 0 || len(selfRefs) > 0 {
+		r.renderForeignKeyCycles(cycles, selfRefs)
+	}
+
+	if opts.ExternalReferences {
+		r.renderExternalReferences(graph.ExternalReferences(db.Schemas))
+	}
+
+	if opts.IdentifierHazards {
+		r.renderIdentifierHazards(analysis.IdentifierHazards(db.Schemas))
+	}
+
+	if opts.Portability {
+		r.renderPortability(analysis.Portability(db.Schemas))
+	}
+
+	if len(opts.AuditColumns) > 0 {
+		r.renderAuditColumns(analysis.AuditColumns(db.Schemas, opts.AuditColumns))
+	}
+
+	if opts.EncryptedColumns {
+		r.renderEncryptedColumns(analysis.EncryptedColumns(db.Schemas))
+	}
+
+	if db.Storage != nil {
+		r.renderStorage(*db.Storage)
+	}
+
+	if sized := sizedTables(db.Schemas); len(sized) > 0 {
+		r.renderTableSizes(sized)
+	}
+
+	// Only what the document shows can define or use a term.
+	shown := make([]pg.SchemaInfo, len(db.Schemas))
+	for i, s := range db.Schemas {
+		shown[i] = Limited(s, opts)
+	}
+	if glossary := collectGlossary(shown); len(glossary) > 0 {
+		r.renderGlossary(glossary)
+	}
+
+	if slices.ContainsFunc(db.Schemas, func(s pg.SchemaInfo) bool { return len(s.Warnings) > 0 }) {
+		r.renderWarnings(db.Schemas)
+	}
+
+	if opts.ContentHash != "" {
+		fmt.Fprintf(sb, "\n---\n\n_Schema hash: `%s`_\n", opts.ContentHash)
+	}
+}
+
+func (r *renderer) renderSchema(schema pg.SchemaInfo) {
+	sb := r.sb
+	r.writeAnchor("schema", schema.Name, "")
+	fmt.Fprintf(sb, "## Schema: %s\n\n", text(schema.Name))
+
+	tables, omittedTables := limit(schema.Tables, r.opts.MaxTablesPerSchema)
+	schema.Tables = tables
+
+	sequences, triggers := schema.Sequences, schema.Triggers
+	if r.opts.Layout == LayoutTable {
+		sequences, triggers = r.attachToTables(schema)
+	} else {
+		sequences = r.attachIdentitySequences(schema)
+	}
+
+	views, omittedViews := limit(schema.Views, r.opts.SectionLimit)
+	matviews, omittedMatviews := limit(schema.MaterializedViews, r.opts.SectionLimit)
+	sequences, omittedSequences := limit(sequences, r.opts.SectionLimit)
+	triggers, omittedTriggers := limit(triggers, r.opts.SectionLimit)
+	functions, procedures := splitProcedures(schema.Functions)
+	functions, omittedFunctions := limit(functions, r.opts.SectionLimit)
+	procedures, omittedProcedures := limit(procedures, r.opts.SectionLimit)
+	types, omittedTypes := limit(schema.Types, r.opts.SectionLimit)
+
+	if len(schema.Tables) > 0 {
+		rest := schema.Tables
+		for _, chapter := range r.opts.TableChapters {
+			var tables []pg.Table
+			tables, rest = partitionTables(rest, chapter.match)
+			if len(tables) > 0 {
+				fmt.Fprintf(sb, "### %s\n\n", chapter.Title)
+				r.renderChapterContents(tables)
+				r.renderTables(tables)
+			}
+		}
+		if len(rest) > 0 {
+			if len(rest) < len(schema.Tables) {
+				sb.WriteString("### Other Tables\n\n")
+			} else {
+				sb.WriteString("### Tables\n\n")
+			}
+			r.renderTables(rest)
+		}
+		r.writeOmitted(omittedTables, "tables")
+	}
+
+	if len(views) > 0 {
+		sb.WriteString("### Views\n\n")
+		for _, view := range views {
+			r.renderView(view)
+		}
+		r.writeOmitted(omittedViews, "views")
+	}
+
+	if len(matviews) > 0 {
+		sb.WriteString("### Materialized Views\n\n")
+		for _, mv := range matviews {
+			r.renderMaterializedView(mv)
+		}
+		r.writeOmitted(omittedMatviews, "materialized views")
+	}
+
+	if len(sequences) > 0 {
+		sb.WriteString("### Sequences\n\n")
+		if len(sequences) > sequenceListLimit {
+			r.renderSequenceTable(sequences)
+		} else {
+			for _, seq := range sequences {
+				r.renderSequence(seq)
+			}
+		}
+		sb.WriteString("\n")
+		r.writeOmitted(omittedSequences, "sequences")
+	}
+
+	if len(triggers) > 0 {
+		sb.WriteString("### Triggers\n\n")
+		for _, trig := range triggers {
+			r.renderTrigger(trig)
+		}
+		sb.WriteString("\n")
+		r.writeOmitted(omittedTriggers, "triggers")
+	}
+
+	if len(functions) > 0 {
+		sb.WriteString("### Functions\n\n")
+		r.renderFunctions(functions)
+		r.writeOmitted(omittedFunctions, "functions")
+	}
+
+	if len(procedures) > 0 {
+		sb.WriteString("### Procedures\n\n")
+		r.renderFunctions(procedures)
+		r.writeOmitted(omittedProcedures, "procedures")
+	}
+
+	if len(types) > 0 {
+		sb.WriteString("### Custom Types\n\n")
+		var detailed []pg.CustomType
+		for _, t := range types {
+			if (t.Kind == "composite" && len(t.Attributes) > 0) || len(t.ValueDescriptions) > 0 {
+				detailed = append(detailed, t)
+				continue
+			}
+			r.renderType(t)
+		}
+		if len(detailed) < len(types) {
+			sb.WriteString("\n")
+		}
+		for _, t := range detailed {
+			if t.Kind == "enum" {
+				r.renderEnumType(t)
+			} else {
+				r.renderCompositeType(t)
+			}
+		}
+		r.writeOmitted(omittedTypes, "types")
+	}
+}
+
+// Limited cuts schema's tables, views, materialized views, functions and
+// types down to those opts renders, for callers building their own index
+// of the document.
+func Limited(schema pg.SchemaInfo, opts Options) pg.SchemaInfo {
+	schema.Tables, _ = limit(schema.Tables, opts.MaxTablesPerSchema)
+	schema.Views, _ = limit(schema.Views, opts.SectionLimit)
+	schema.MaterializedViews, _ = limit(schema.MaterializedViews, opts.SectionLimit)
+	functions, procedures := splitProcedures(schema.Functions)
+	functions, _ = limit(functions, opts.SectionLimit)
+	procedures, _ = limit(procedures, opts.SectionLimit)
+	schema.Functions = append(functions, procedures...)
+	schema.Types, _ = limit(schema.Types, opts.SectionLimit)
+	return schema
+}
+
+// renderTables renders each table, or only links to them when tables have
+// pages of their own.
+func (r *renderer) renderTables(tables []pg.Table) {
+	for _, table := range tables {
+		if r.tableLink != nil {
+			fmt.Fprintf(r.sb, "- [%s](%s)\n", text(r.qualify(table.Schema, table.Name)), r.tableLink(table))
+			continue
+		}
+		r.renderTable(table)
+	}
+	if r.tableLink != nil {
+		r.sb.WriteString("\n")
+	}
+}
+
+// renderChapterContents lists a chapter's tables ahead of them. With table
+// pages the chapter is already such a list.
+func (r *renderer) renderChapterContents(tables []pg.Table) {
+	if r.tableLink != nil {
+		return
+	}
+	for _, table := range tables {
+		name := r.qualify(table.Schema, table.Name)
+		if link := r.link("table", table.Schema, table.Name); link != "" {
+			fmt.Fprintf(r.sb, "- [%s](%s)\n", text(name), link)
+		} else {
+			fmt.Fprintf(r.sb, "- %s\n", code(name))
+		}
+	}
+	r.sb.WriteString("\n")
+}
+
+// partitionTables splits tables into those match accepts and the rest,
+// keeping their order.
+func partitionTables(tables []pg.Table, match func(name string) bool) (matched, rest []pg.Table) {
+	for _, t := range tables {
+		if match(t.Name) {
+			matched = append(matched, t)
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	return matched, rest
+}
+
+// limit returns the first n items and how many were cut; n <= 0 keeps
+// them all.
+func limit[T any](items []T, n int) ([]T, int) {
+	if n <= 0 || len(items) <= n {
+		return items, 0
+	}
+	return items[:n], len(items) - n
+}
+
+func (r *renderer) writeOmitted(n int, what string) {
+	if n > 0 {
+		fmt.Fprintf(r.sb, "_… %d more %s omitted._\n\n", n, what)
+	}
+}
+
+func (r *renderer) renderTable(table pg.Table) {
+	sb := r.sb
+	r.writeAnchor("table", table.Schema, table.Name)
+	fmt.Fprintf(sb, "#### %s\n\n", text(r.qualify(table.Schema, table.Name)))
+	if r.opts.ClassifyTables {
+		if class := analysis.Classify(table); class != "" {
+			fmt.Fprintf(sb, "_%s table_\n\n", class)
+		}
+	}
+	r.writeDescription(table.Description)
+	if r.opts.SoftDelete != "" && analysis.UsesSoftDelete(table, r.opts.SoftDelete) {
+		fmt.Fprintf(sb, "**Soft delete:** live rows satisfy %s.\n\n", code(r.opts.SoftDelete))
+	}
+	if table.PartitionStrategy != "" {
+		fmt.Fprintf(sb, "**Partitioned by:** %s (%s)\n\n", table.PartitionStrategy, code(table.PartitionKey))
+	}
+	if size := table.Size; size != nil {
+		fmt.Fprintf(sb, "**Size:** %s total (table %s, indexes %s), ~%s rows\n\n",
+			formatBytes(size.TotalBytes), formatBytes(size.TableBytes), formatBytes(size.IndexBytes), formatCount(size.EstimatedRows))
+	}
+	r.renderColumns(table.Schema, table.Name, table.Columns, true, r.opts.ExpandTypes)
+
+	if len(table.Indexes) > 0 {
+		sb.WriteString("\n**Indexes:** ")
+		var idxStrs []string
+		for _, idx := range table.Indexes {
+			idxStr := fmt.Sprintf("%s (%s", text(idx.Name), text(strings.Join(idx.Columns, ", ")))
+			if idx.IsPrimary {
+				idxStr += ", PK"
+			} else if idx.IsUnique {
+				idxStr += ", UNIQUE"
+			}
+			if idx.Predicate != "" {
+				idxStr += ", WHERE " + text(idx.Predicate)
+			}
+			idxStr += ")"
+			idxStrs = append(idxStrs, idxStr)
+		}
+		sb.WriteString(strings.Join(idxStrs, ", "))
+		sb.WriteString("\n")
+	}
+
+	if len(table.Checks) > 0 {
+		sb.WriteString("\n**Constraints:**\n\n")
+		for _, check := range table.Checks {
+			on := ""
+			if len(check.Columns) == 1 {
+				on = fmt.Sprintf(" (on %s)", code(check.Columns[0]))
+			}
+			fmt.Fprintf(sb, "- %s%s: %s\n", code(check.Name), on, code(check.Definition))
+		}
+	}
+
+	if len(table.ForeignKeys) > 0 {
+		sb.WriteString("\n**Foreign keys:**\n\n")
+		for _, fk := range table.ForeignKeys {
+			fmt.Fprintf(sb, "- %s: (%s) → %s (%s)", code(fk.Name), text(strings.Join(fk.Columns, ", ")), r.foreignKeyTarget(table.Schema, fk), text(strings.Join(fk.RefColumns, ", ")))
+			if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+				fmt.Fprintf(sb, " ON DELETE %s", fk.OnDelete)
+			}
+			if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+				fmt.Fprintf(sb, " ON UPDATE %s", fk.OnUpdate)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if table.RowSecurity || len(table.Policies) > 0 {
+		r.renderPolicies(table)
+	}
+
+	if len(table.Partitions) > 0 {
+		fmt.Fprintf(sb, "\n**Partitions (%d):**\n\n", len(table.Partitions))
+		r.renderPartitions(table.Schema, table.Partitions, "")
+	}
+
+	if len(table.Grants) > 0 {
+		sb.WriteString("\n**Grants:**\n\n")
+		renderGrants(sb, table.Grants)
+	}
+
+	if r.opts.Profile == ProfileCDC {
+		r.renderReplication(table)
+	} else if table.ReplicaIdentity != "" && table.ReplicaIdentity != "default" {
+		fmt.Fprintf(sb, "\n**Replica identity:** %s\n", replicaIdentityLabel(table))
+	}
+
+	if seqs := r.tableSequences[table.Name]; len(seqs) > 0 {
+		sb.WriteString("\n**Sequences:**\n\n")
+		for _, seq := range seqs {
+			r.renderSequence(seq)
+		}
+	}
+
+	if trigs := r.tableTriggers[table.Name]; len(trigs) > 0 {
+		sb.WriteString("\n**Triggers:**\n\n")
+		for _, trig := range trigs {
+			r.renderTrigger(trig)
+		}
+	}
+
+	sb.WriteString("\n")
+}
+
+// renderPolicies writes whether row-level security is on and the
+// table's policies, which only take effect while it is.
+func (r *renderer) renderPolicies(table pg.Table) {
+	sb := r.sb
+	switch {
+	case table.RowSecurity && table.ForceRowSecurity:
+		sb.WriteString("\n**Row-level security:** enabled, also for the table owner\n")
+	case table.RowSecurity:
+		sb.WriteString("\n**Row-level security:** enabled\n")
+	default:
+		sb.WriteString("\n**Row-level security:** disabled, so these policies are not enforced\n")
+	}
+	if len(table.Policies) == 0 {
+		return
+	}
+
+	sb.WriteString("\n")
+	for _, pol := range table.Policies {
+		roles := make([]string, len(pol.Roles))
+		for i, role := range pol.Roles {
+			roles[i] = code(role)
+		}
+		fmt.Fprintf(sb, "- %s", code(pol.Name))
+		if pol.Restrictive {
+			sb.WriteString(" (restrictive)")
+		}
+		fmt.Fprintf(sb, ": FOR %s TO %s", pol.Command, strings.Join(roles, ", "))
+		if pol.Using != "" {
+			fmt.Fprintf(sb, " USING %s", code(pol.Using))
+		}
+		if pol.WithCheck != "" {
+			fmt.Fprintf(sb, " WITH CHECK %s", code(pol.WithCheck))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// attachIdentitySequences moves identity columns' implicit sequences into
+// their table's section, since they are part of the column definition,
+// and returns the remaining sequences.
+func (r *renderer) attachIdentitySequences(schema pg.SchemaInfo) []pg.Sequence {
+	r.tableSequences = make(map[string][]pg.Sequence)
+	tables := make(map[string]bool)
+	for _, table := range schema.Tables {
+		tables[table.Name] = true
+	}
+
+	var sequences []pg.Sequence
+	for _, seq := range schema.Sequences {
+		if seq.Identity && tables[seq.OwnerTable] {
+			r.tableSequences[seq.OwnerTable] = append(r.tableSequences[seq.OwnerTable], seq)
+		} else {
+			sequences = append(sequences, seq)
+		}
+	}
+	return sequences
+}
+
+// attachToTables moves triggers and owned or column-default sequences into
+// their table's section and returns whatever is left for the schema-level
+// lists, e.g. triggers on views or sequences no column draws from.
+func (r *renderer) attachToTables(schema pg.SchemaInfo) ([]pg.Sequence, []pg.Trigger) {
+	r.tableTriggers = make(map[string][]pg.Trigger)
+	r.tableSequences = make(map[string][]pg.Sequence)
+
+	tables := make(map[string]bool)
+	seqOwner := make(map[string]string)
+	for _, table := range schema.Tables {
+		tables[table.Name] = true
+		for _, col := range table.Columns {
+			if seq := nextvalSequence(col.Default, schema.Name); seq != "" {
+				if _, taken := seqOwner[seq]; !taken {
+					seqOwner[seq] = table.Name
+				}
+			}
+		}
+	}
+
+	var sequences []pg.Sequence
+	for _, seq := range schema.Sequences {
+		if tables[seq.OwnerTable] {
+			r.tableSequences[seq.OwnerTable] = append(r.tableSequences[seq.OwnerTable], seq)
+		} else if owner, ok := seqOwner[seq.Name]; ok {
+			r.tableSequences[owner] = append(r.tableSequences[owner], seq)
+		} else {
+			sequences = append(sequences, seq)
+		}
+	}
+
+	var triggers []pg.Trigger
+	for _, trig := range schema.Triggers {
+		if tables[trig.Table] {
+			r.tableTriggers[trig.Table] = append(r.tableTriggers[trig.Table], trig)
+		} else {
+			triggers = append(triggers, trig)
+		}
+	}
+
+	return sequences, triggers
+}
+
+// nextvalSequence extracts the sequence name from a default such as
+// nextval('users_id_seq'::regclass). Names qualified with another schema
+// are ignored since they can't be attached within this schema.
+func nextvalSequence(def, schema string) string {
+	const prefix = "nextval('"
+	i := strings.Index(def, prefix)
+	if i < 0 {
+		return ""
+	}
+	rest := def[i+len(prefix):]
+	end := strings.Index(rest, "'")
+	if end < 0 {
+		return ""
+	}
+	name := rest[:end]
+	if qualifier, unqualified, ok := strings.Cut(name, "."); ok {
+		if strings.Trim(qualifier, `"`) != schema {
+			return ""
+		}
+		name = unqualified
+	}
+	return strings.Trim(name, `"`)
+}
+
+func (r *renderer) renderView(view pg.View) {
+	sb := r.sb
+	r.writeAnchor("view", view.Schema, view.Name)
+	fmt.Fprintf(sb, "#### %s\n\n", text(r.qualify(view.Schema, view.Name)))
+	r.writeDescription(view.Description)
+	r.renderColumns(view.Schema, view.Name, view.Columns, false, r.opts.ExpandTypes)
+	r.renderDefinition(view.Definition)
+
+	sb.WriteString("\n")
+}
+
+func (r *renderer) renderMaterializedView(mv pg.MaterializedView) {
+	sb := r.sb
+	r.writeAnchor("matview", mv.Schema, mv.Name)
+	fmt.Fprintf(sb, "#### %s\n\n", text(r.qualify(mv.Schema, mv.Name)))
+	r.writeDescription(mv.Description)
+	r.renderColumns(mv.Schema, mv.Name, mv.Columns, false, r.opts.ExpandTypes)
+	r.renderRefreshes(mv)
+	r.renderDefinition(mv.Definition)
+
+	sb.WriteString("\n")
+}
+
+// renderRefreshes lists the pg_cron jobs and triggers that refresh mv,
+// with a job's schedule.
+func (r *renderer) renderRefreshes(mv pg.MaterializedView) {
+	if len(mv.Refreshes) == 0 {
+		return
+	}
+	r.sb.WriteString("\n**Refreshed by:**\n\n")
+	for _, refresh := range mv.Refreshes {
+		var line string
+		if refresh.Kind == "cron" {
+			line = fmt.Sprintf("pg_cron job %s on %s", code(refresh.Name), code(refresh.Schedule))
+		} else {
+			schema, trigger, _ := strings.Cut(refresh.Name, ".")
+			label := code(r.qualify(schema, trigger))
+			if schema != mv.Schema && !r.opts.QualifyNames {
+				label = code(refresh.Name)
+			}
+			if link := r.link("trigger", schema, trigger); link != "" && (r.documented == nil || r.documented[schema]) {
+				label = fmt.Sprintf("[%s](%s)", label, link)
+			}
+			line = "trigger " + label
+		}
+		if refresh.Function != "" {
+			line += ", via " + r.functionRef(mv.Schema, refresh.Function)
+		}
+		if refresh.Concurrently {
+			line += ", concurrently"
+		}
+		fmt.Fprintf(r.sb, "- %s\n", line)
+	}
+}
+
+// renderDefinition writes a view's query or a function's CREATE statement
+// in a collapsed SQL block.
+func (r *renderer) renderDefinition(def string) {
+	if def = strings.TrimSpace(def); def == "" {
+		return
+	}
+	fence := codeFence(def)
+	fmt.Fprintf(r.sb, "\n<details>\n<summary>Definition</summary>\n\n%ssql\n%s\n%s\n\n</details>\n", fence, def, fence)
+}
+
+// sequenceListLimit is how many sequences a schema can have before they
+// are rendered as a table instead of a list.
+const sequenceListLimit = 5
+
+func (r *renderer) renderSequence(seq pg.Sequence) {
+	extra := ""
+	if seq.Cache > 1 {
+		extra += fmt.Sprintf(", cache=%d", seq.Cache)
+	}
+	if seq.Cycle {
+		extra += ", CYCLE"
+	}
+	if seq.LastValue != nil {
+		extra += fmt.Sprintf(", last=%d", *seq.LastValue)
+	}
+	if owner := sequenceOwner(seq); owner != "" {
+		extra += ", " + owner
+	}
+	fmt.Fprintf(r.sb, "- %s%s (%s): start=%d, inc=%d, range=[%d..%d]%s\n",
+		r.inlineAnchor("sequence", seq.Schema, seq.Name),
+		code(r.qualify(seq.Schema, seq.Name)), text(seq.DataType), seq.Start, seq.Increment, seq.Min, seq.Max, extra)
+}
+
+// sequenceOwner describes the column a sequence belongs to, or returns ""
+// for a free-standing sequence.
+func sequenceOwner(seq pg.Sequence) string {
+	if seq.OwnerTable == "" {
+		return ""
+	}
+	if seq.Identity {
+		return "identity of " + code(seq.OwnerTable+"."+seq.OwnerColumn)
+	}
+	return "owned by " + code(seq.OwnerTable+"."+seq.OwnerColumn)
+}
+
+// renderSequenceTable lays sequences out one per row. The Last Value
+// column only appears when values were fetched, and Owner when any
+// sequence belongs to a column.
+func (r *renderer) renderSequenceTable(sequences []pg.Sequence) {
+	withValues := slices.ContainsFunc(sequences, func(seq pg.Sequence) bool { return seq.LastValue != nil })
+	withOwners := slices.ContainsFunc(sequences, func(seq pg.Sequence) bool { return seq.OwnerTable != "" })
+	headers := []string{"Sequence", "Type", "Start", "Increment", "Range", "Cache", "Cycle"}
+	if withValues {
+		headers = append(headers, "Last Value")
+	}
+	if withOwners {
+		headers = append(headers, "Owner")
+	}
+	writeTableHeader(r.sb, headers)
+
+	for _, seq := range sequences {
+		cells := []string{
+			r.inlineAnchor("sequence", seq.Schema, seq.Name) + text(r.qualify(seq.Schema, seq.Name)),
+			text(seq.DataType),
+			fmt.Sprint(seq.Start),
+			fmt.Sprint(seq.Increment),
+			fmt.Sprintf("%d..%d", seq.Min, seq.Max),
+			fmt.Sprint(seq.Cache),
+			yesNo(seq.Cycle),
+		}
+		if withValues {
+			last := ""
+			if seq.LastValue != nil {
+				last = fmt.Sprint(*seq.LastValue)
+			}
+			cells = append(cells, last)
+		}
+		if withOwners {
+			cells = append(cells, sequenceOwner(seq))
+		}
+		writeTableRow(r.sb, cells)
+	}
+}
+
+func (r *renderer) renderTrigger(trig pg.Trigger) {
+	fnSchema := trig.FunctionSchema
+	if fnSchema == "" {
+		fnSchema = trig.Schema
+	}
+	fn := text(r.qualify(fnSchema, trig.Function) + "()")
+	if fnSchema != trig.Schema && !r.opts.QualifyNames {
+		fn = text(fnSchema+".") + fn
+	}
+	// Trigger functions take no declared arguments, so their anchor is
+	// the bare name.
+	if link := r.link("function", fnSchema, trig.Function); link != "" && r.functions[AnchorID("function", fnSchema, trig.Function)] {
+		fn = fmt.Sprintf("[%s](%s)", fn, link)
+	}
+
+	// Transition tables change how the function must be read: it sees
+	// the affected rows through them rather than NEW and OLD.
+	if ref := trig.Referencing(); ref != "" {
+		fn += " " + code(ref)
+	}
+
+	event := text(trig.EventClause())
+	if trig.Level != "" {
+		event += " FOR EACH " + trig.Level
+	}
+	if trig.When != "" {
+		event += " WHEN " + code(trig.When)
+	}
+
+	fmt.Fprintf(r.sb, "- %s%s on %s: %s %s → %s\n",
+		r.inlineAnchor("trigger", trig.Schema, trig.Table+"."+trig.Name),
+		code(trig.Name), code(r.qualify(trig.Schema, trig.Table)), trig.Timing, event, fn)
+}
+
+// indexFunctions records which functions the document defines anchors
+// for.
+func (r *renderer) indexFunctions(schemas []pg.SchemaInfo) {
+	r.functions = make(map[string]bool)
+	r.firstOverload = make(map[string]pg.Function)
+	for _, s := range schemas {
+		// Functions cut by SectionLimit have no anchor to link to.
+		functions, procedures := splitProcedures(s.Functions)
+		functions, _ = limit(functions, r.opts.SectionLimit)
+		procedures, _ = limit(procedures, r.opts.SectionLimit)
+		for _, fn := range append(functions, procedures...) {
+			r.functions[AnchorID(fn.ObjectType(), fn.Schema, functionAnchorName(fn))] = true
+			if _, ok := r.firstOverload[fn.Schema+"."+fn.Name]; !ok {
+				r.firstOverload[fn.Schema+"."+fn.Name] = fn
+			}
+		}
+	}
+
+	if !r.opts.FunctionCalls {
+		return
+	}
+	r.calls = make(map[string][]string)
+	r.callers = make(map[string][]string)
+	for _, c := range graph.FunctionCalls(schemas) {
+		r.calls[c.Caller] = append(r.calls[c.Caller], c.Callee)
+		r.callers[c.Callee] = append(r.callers[c.Callee], c.Caller)
+	}
+}
+
+// indexTypes records the types the document defines, which columns
+// can link to and expand.
+func (r *renderer) indexTypes(schemas []pg.SchemaInfo) {
+	r.types = make(map[string]pg.CustomType)
+	for _, s := range schemas {
+		// Types cut by SectionLimit have no anchor to link to.
+		types, _ := limit(s.Types, r.opts.SectionLimit)
+		for _, t := range types {
+			r.types[t.Schema+"."+t.Name] = t
+		}
+	}
+}
+
+// indexSchemas records which schemas the document covers.
+func (r *renderer) indexSchemas(schemas []pg.SchemaInfo) {
+	r.documented = make(map[string]bool)
+	for _, s := range schemas {
+		r.documented[s.Name] = true
+	}
+}
+
+// foreignKeyTarget names the table fk references from a table in schema.
+// A table in another schema is always qualified, and marked external when
+// that schema is not documented.
+func (r *renderer) foreignKeyTarget(schema string, fk pg.ForeignKey) string {
+	name := r.qualify(fk.RefSchema, fk.RefTable)
+	if fk.RefSchema != schema {
+		name = fk.RefSchema + "." + fk.RefTable
+	}
+	target := text(name)
+	if r.documented != nil && !r.documented[fk.RefSchema] {
+		target += " _(external)_"
+	}
+	return target
+}
+
+// splitProcedures separates procedures from functions, keeping their
+// order; they have sections of their own.
+func splitProcedures(all []pg.Function) (functions, procedures []pg.Function) {
+	for _, fn := range all {
+		if fn.Kind == "procedure" {
+			procedures = append(procedures, fn)
+		} else {
+			functions = append(functions, fn)
+		}
+	}
+	return functions, procedures
+}
+
+// renderFunctions lists functions with overloads collapsed under a single
+// entry, optionally split into sub-headings by name prefix.
+func (r *renderer) renderFunctions(functions []pg.Function) {
+	groups := groupOverloads(functions)
+
+	if !r.opts.GroupFunctionsByPrefix {
+		for _, g := range groups {
+			r.renderOverloads(g)
+		}
+		r.sb.WriteString("\n")
+		return
+	}
+
+	for _, group := range groupByPrefix(groups) {
+		fmt.Fprintf(r.sb, "#### %s\n\n", text(group.title))
+		for _, g := range group.overloads {
+			r.renderOverloads(g)
+		}
+		r.sb.WriteString("\n")
+	}
+}
+
+func (r *renderer) renderOverloads(overloads []pg.Function) {
+	if len(overloads) == 1 {
+		r.renderFunction(overloads[0], "")
+	} else {
+		fmt.Fprintf(r.sb, "- %s (%d overloads)\n", code(r.qualify(overloads[0].Schema, overloads[0].Name)), len(overloads))
+		for _, fn := range overloads {
+			r.renderFunction(fn, "  ")
+		}
+	}
+	r.renderCalls(overloads[0].Schema, overloads[0].Name)
+}
+
+// renderCalls lists the documented functions a function calls and is
+// called by, overloads taken together.
+func (r *renderer) renderCalls(schema, name string) {
+	key := schema + "." + name
+	for _, list := range []struct {
+		label string
+		names []string
+	}{
+		{"Calls", r.calls[key]},
+		{"Called by", r.callers[key]},
+	} {
+		if len(list.names) == 0 {
+			continue
+		}
+		refs := make([]string, len(list.names))
+		for i, qualified := range list.names {
+			refs[i] = r.functionRef(schema, qualified)
+		}
+		fmt.Fprintf(r.sb, "  %s: %s\n", list.label, strings.Join(refs, ", "))
+	}
+}
+
+// functionRef names the function "schema.name" as seen from schema,
+// linked to its first overload when it has an anchor.
+func (r *renderer) functionRef(from, qualified string) string {
+	fnSchema, name, _ := strings.Cut(qualified, ".")
+	label := r.qualify(fnSchema, name) + "()"
+	if fnSchema != from && !r.opts.QualifyNames {
+		label = qualified + "()"
+	}
+	ref := code(label)
+	if fn, ok := r.firstOverload[qualified]; ok {
+		if link := r.link(fn.ObjectType(), fn.Schema, functionAnchorName(fn)); link != "" {
+			ref = fmt.Sprintf("[%s](%s)", ref, link)
+		}
+	}
+	return ref
+}
+
+func (r *renderer) renderFunction(fn pg.Function, indent string) {
+	anchor := r.inlineAnchor(fn.ObjectType(), fn.Schema, functionAnchorName(fn))
+	name := r.qualify(fn.Schema, fn.Name)
+	returnsRows := fn.ReturnsSet && len(fn.ReturnColumns) > 0
+	args := fn.Args
+	if returnsRows {
+		args = slices.DeleteFunc(slices.Clone(args), func(a pg.Argument) bool { return a.Mode == "TABLE" })
+	}
+
+	desc := functionTraits(fn)
+	if d := cellProse(fn.Description); d != "" {
+		desc += " — " + d
+	}
+
+	switch {
+	case r.opts.FunctionArgTables && len(args) > 0:
+		returnType := fn.ReturnType
+		if returnsRows && strings.HasPrefix(returnType, "TABLE(") {
+			returnType = "TABLE(…)"
+		}
+		fmt.Fprintf(r.sb, "%s- %s%s%s\n\n", indent, anchor, code(name+"(…)"+returns(returnType)), desc)
+		r.renderArguments(args, indent+"  ")
+	default:
+		fmt.Fprintf(r.sb, "%s- %s%s%s\n", indent, anchor, code(name+"("+fn.Arguments+")"+returns(fn.ReturnType)), desc)
+	}
+
+	var executors []string
+	for _, g := range fn.Grants {
+		if slices.Contains(g.Privileges, "EXECUTE") {
+			executors = append(executors, code(g.Grantee))
+		}
+	}
+	if len(executors) > 0 {
+		fmt.Fprintf(r.sb, "%s  Executable by: %s\n", indent, strings.Join(executors, ", "))
+	}
+
+	if returnsRows {
+		if !r.opts.FunctionArgTables || len(args) == 0 {
+			r.sb.WriteString("\n")
+		}
+		fmt.Fprintf(r.sb, "%s  Returns rows of:\n\n", indent)
+		out, block := r.sb, &strings.Builder{}
+		r.sb = block
+		r.renderColumns(fn.Schema, fn.Name, fn.ReturnColumns, false, false)
+		r.sb = out
+		writeIndented(r.sb, block.String(), indent+"  ")
+	}
+
+	if fn.Definition != "" {
+		out, block := r.sb, &strings.Builder{}
+		r.sb = block
+		r.renderDefinition(fn.Definition)
+		r.sb = out
+		writeIndented(r.sb, block.String(), indent+"  ")
+	}
+}
+
+// functionTraits lists how fn runs: its language, volatility, a cost
+// other than the default and, in bold so audits can spot it, SECURITY
+// DEFINER.
+func functionTraits(fn pg.Function) string {
+	var traits []string
+	if fn.Language != "" {
+		traits = append(traits, text(fn.Language))
+	}
+	if fn.Volatility != "" {
+		traits = append(traits, fn.Volatility)
+	}
+	if fn.Cost != 0 && fn.Cost != pg.DefaultCost(fn.Language) {
+		traits = append(traits, fmt.Sprintf("cost %g", fn.Cost))
+	}
+	if fn.SecurityDefiner {
+		traits = append(traits, "**SECURITY DEFINER**")
+	}
+	if len(traits) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(traits, ", ") + ")"
+}
+
+// returns formats a return type after a signature; procedures have none.
+func returns(returnType string) string {
+	if returnType == "" {
+		return ""
+	}
+	return " → " + returnType
+}
+
+// privilegeOrder is the order GRANT documents table privileges in;
+// anything else sorts after them.
+var privilegeOrder = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER", "MAINTAIN"}
+
+// renderGrants writes a role × privilege matrix with a column for each
+// privilege granted to anyone. Column-level grants get a row of their own.
+func renderGrants(sb *strings.Builder, grants []pg.Grant) {
+	var privileges []string
+	for _, g := range grants {
+		for _, p := range g.Privileges {
+			if !slices.Contains(privileges, p) {
+				privileges = append(privileges, p)
+			}
+		}
+	}
+	rank := func(p string) int {
+		if i := slices.Index(privilegeOrder, p); i >= 0 {
+			return i
+		}
+		return len(privilegeOrder)
+	}
+	slices.SortStableFunc(privileges, func(a, b string) int { return rank(a) - rank(b) })
+
+	writeTableHeader(sb, append([]string{"Role"}, privileges...))
+	for _, g := range grants {
+		role := text(g.Grantee)
+		if g.Column != "" {
+			role += fmt.Sprintf(" (%s)", code(g.Column))
+		}
+		cells := []string{role}
+		for _, p := range privileges {
+			mark := ""
+			if slices.Contains(g.Privileges, p) {
+				mark = "✓"
+			}
+			cells = append(cells, mark)
+		}
+		writeTableRow(sb, cells)
+	}
+}
+
+// renderArguments writes a parameter table, indented to sit inside the
+// function's list item.
+func (r *renderer) renderArguments(args []pg.Argument, indent string) {
+	var sb strings.Builder
+	writeTableHeader(&sb, []string{"Name", "Type", "Mode", "Default"})
+	for _, arg := range args {
+		var def string
+		if arg.Default != "" {
+			def = code(arg.Default)
+		}
+		writeTableRow(&sb, []string{text(arg.Name), text(arg.Type), arg.Mode, def})
+	}
+	writeIndented(r.sb, sb.String(), indent)
+}
+
+// writeIndented writes a block under a list item, followed by a blank
+// line.
+func writeIndented(sb *strings.Builder, block, indent string) {
+	for _, line := range strings.SplitAfter(block, "\n") {
+		switch line {
+		case "":
+		case "\n":
+			sb.WriteString(line)
+		default:
+			sb.WriteString(indent + line)
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// groupOverloads collects functions sharing a name, in order of first
+// occurrence.
+func groupOverloads(functions []pg.Function) [][]pg.Function {
+	var groups [][]pg.Function
+	index := make(map[string]int)
+	for _, fn := range functions {
+		i, ok := index[fn.Name]
+		if !ok {
+			i = len(groups)
+			index[fn.Name] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], fn)
+	}
+	return groups
+}
+
+type prefixGroup struct {
+	title     string
+	overloads [][]pg.Function
+}
+
+// groupByPrefix buckets functions by the part of their name before the
+// first underscore. Prefixes shared by fewer than two functions are
+// gathered in a trailing "Other" group.
+func groupByPrefix(groups [][]pg.Function) []prefixGroup {
+	prefixOf := func(name string) string {
+		if i := strings.Index(name, "_"); i > 0 {
+			return name[:i+1]
+		}
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, g := range groups {
+		counts[prefixOf(g[0].Name)]++
+	}
+
+	var result []prefixGroup
+	index := make(map[string]int)
+	var other [][]pg.Function
+	for _, g := range groups {
+		prefix := prefixOf(g[0].Name)
+		if prefix == "" || counts[prefix] < 2 {
+			other = append(other, g)
+			continue
+		}
+		i, ok := index[prefix]
+		if !ok {
+			i = len(result)
+			index[prefix] = i
+			result = append(result, prefixGroup{title: prefix + "*"})
+		}
+		result[i].overloads = append(result[i].overloads, g)
+	}
+	if len(other) > 0 {
+		result = append(result, prefixGroup{title: "Other", overloads: other})
+	}
+	return result
+}
+
+// renderCompositeType lays out a composite type's attributes like a
+// view's columns, with the fields of nested composites beneath theirs.
+func (r *renderer) renderCompositeType(t pg.CustomType) {
+	sb := r.sb
+	r.writeAnchor("type", t.Schema, t.Name)
+	fmt.Fprintf(sb, "#### %s (composite)\n\n", text(r.qualify(t.Schema, t.Name)))
+	r.writeDescription(t.Description)
+	r.renderColumns(t.Schema, t.Name, t.Attributes, false, true)
+	sb.WriteString("\n")
+}
+
+// renderPartitions lists partitions with their bounds, nesting
+// sub-partitions under their parent.
+func (r *renderer) renderPartitions(parentSchema string, partitions []pg.Partition, indent string) {
+	for _, p := range partitions {
+		name := p.Name
+		if p.Schema != parentSchema {
+			name = p.Schema + "." + p.Name
+		}
+		fmt.Fprintf(r.sb, "%s- %s: %s", indent, code(name), text(strings.TrimPrefix(p.Bound, "FOR VALUES ")))
+		if p.PartitionStrategy != "" {
+			fmt.Fprintf(r.sb, ", partitioned by %s (%s)", p.PartitionStrategy, code(p.PartitionKey))
+		}
+		r.sb.WriteString("\n")
+		r.renderPartitions(p.Schema, p.Partitions, indent+"  ")
+	}
+}
+
+// renderEnumType lists an enum's values with their descriptions, in
+// sort order.
+func (r *renderer) renderEnumType(t pg.CustomType) {
+	sb := r.sb
+	r.writeAnchor("type", t.Schema, t.Name)
+	fmt.Fprintf(sb, "#### %s (enum)\n\n", text(r.qualify(t.Schema, t.Name)))
+	r.writeDescription(t.Description)
+	writeTableHeader(sb, []string{"Value", "Description"})
+	for _, v := range t.Values {
+		writeTableRow(sb, []string{code(v), cellProse(t.ValueDescriptions[v])})
+	}
+	sb.WriteString("\n")
+}
+
+func (r *renderer) renderType(t pg.CustomType) {
+	anchor := r.inlineAnchor("type", t.Schema, t.Name)
+	name := r.qualify(t.Schema, t.Name)
+	if t.Kind == "enum" {
+		var quoted []string
+		for _, v := range t.Values {
+			quoted = append(quoted, text(fmt.Sprintf("'%s'", v)))
+		}
+		fmt.Fprintf(r.sb, "- %s%s: %s\n", anchor, code(name), strings.Join(quoted, ", "))
+	} else {
+		fmt.Fprintf(r.sb, "- %s%s (composite): %s\n", anchor, code(name), text(strings.Join(t.Values, ", ")))
+	}
+}
+
+// renderColumns writes the column table of a relation in schema.
+// Constraints are only shown for tables. With expand, the attributes of
+// columns typed as a documented composite follow them.
+func (r *renderer) renderColumns(schema, relation string, columns []pg.Column, withConstraints, expand bool) {
+	sb := r.sb
+	headers := []string{"Column", "Type"}
+	if r.opts.ColumnPositions {
+		headers = append([]string{"#"}, headers...)
+	}
+	if withConstraints {
+		headers = append(headers, "Constraints")
+	}
+	withLineage, withDescriptions := false, false
+	for _, col := range columns {
+		withLineage = withLineage || len(col.DerivedFrom) > 0
+		withDescriptions = withDescriptions || col.Description != ""
+	}
+	if withLineage {
+		headers = append(headers, "Derived From")
+	}
+	if withDescriptions {
+		headers = append(headers, "Description")
+	}
+	writeTableHeader(sb, headers)
+
+	columns, omitted := r.filterColumns(relation, columns)
+	if r.opts.ColumnOrder == ColumnOrderAlphabetical {
+		columns = append([]pg.Column(nil), columns...)
+		sort.SliceStable(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	}
+
+	// extraRow writes a row that is not one of columns, leaving the
+	// other cells empty.
+	extraRow := func(name, typ, desc string) {
+		var cells []string
+		if r.opts.ColumnPositions {
+			cells = append(cells, "")
+		}
+		cells = append(cells, name, typ)
+		if withConstraints {
+			cells = append(cells, "")
+		}
+		if withLineage {
+			cells = append(cells, "")
+		}
+		if withDescriptions {
+			cells = append(cells, desc)
+		}
+		writeTableRow(sb, cells)
+	}
+
+	for _, col := range columns {
+		var cells []string
+		if r.opts.ColumnPositions {
+			cells = append(cells, fmt.Sprintf("%d", col.Position))
+		}
+		cells = append(cells, text(col.Name), r.typeCell(schema, col.DeclaredType()))
+		if withConstraints {
+			if col.Default != "" {
+				col.Default = r.renderDefault(col.Default)
+			}
+			cells = append(cells, buildConstraints(col))
+		}
+		if withLineage {
+			cells = append(cells, derivedFrom(col))
+		}
+		if withDescriptions {
+			cells = append(cells, cellProse(col.Description))
+		}
+		writeTableRow(sb, cells)
+		if expand {
+			r.writeNestedColumns(schema, col.Name, col.DeclaredType(), nil, extraRow)
+		}
+	}
+	for _, o := range omitted {
+		extraRow("_"+o.label()+"_", text(o.typ), "")
+	}
+}
+
+// writeNestedColumns writes a row for each attribute of the composite
+// typ names, if it is one, and for theirs in turn, labelled with their
+// path from the column. seen holds the types already being expanded, so
+// a cycle in a snapshot ends.
+func (r *renderer) writeNestedColumns(schema, path, typ string, seen map[string]bool, row func(name, typ, desc string)) {
+	t, ok := r.lookupType(schema, typ)
+	key := t.Schema + "." + t.Name
+	if !ok || t.Kind != "composite" || seen[key] {
+		return
+	}
+	if strings.HasSuffix(strings.TrimSpace(typ), "[]") {
+		path += "[]"
+	}
+	seen = maps.Clone(seen)
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	seen[key] = true
+	for _, attr := range t.Attributes {
+		attrPath := path + "." + attr.Name
+		row(text("↳ "+attrPath), r.typeCell(t.Schema, attr.Type), cellProse(attr.Description))
+		r.writeNestedColumns(t.Schema, attrPath, attr.Type, seen, row)
+	}
+}
+
+// typeCell shows a column type, linked to the type's definition when the
+// document has one. Arrays link to their element type.
+func (r *renderer) typeCell(schema, typ string) string {
+	t, ok := r.lookupType(schema, typ)
+	if !ok {
+		return text(typ)
+	}
+	if link := r.link("type", t.Schema, t.Name); link != "" {
+		return fmt.Sprintf("[%s](%s)", text(typ), link)
+	}
+	return text(typ)
+}
+
+// lookupType finds the documented type typ names, looking an unqualified
+// name up in schema first. information_schema's udt_name carries no
+// schema, so failing that, an unqualified name matches the one type of
+// that name in another schema, if there is exactly one.
+func (r *renderer) lookupType(schema, typ string) (pg.CustomType, bool) {
+	key := pg.ReferencedType(schema, typ)
+	if t, ok := r.types[key]; ok {
+		return t, true
+	}
+	if strings.Contains(typ, ".") {
+		return pg.CustomType{}, false
+	}
+	_, name, _ := strings.Cut(key, ".")
+	var found []pg.CustomType
+	for _, t := range r.types {
+		if t.Name == name {
+			found = append(found, t)
+		}
+	}
+	if len(found) != 1 {
+		return pg.CustomType{}, false
+	}
+	return found[0], true
+}
+
+// derivedFrom lists the table columns a view column is computed from.
+func derivedFrom(col pg.Column) string {
+	sources := make([]string, len(col.DerivedFrom))
+	for i, src := range col.DerivedFrom {
+		sources[i] = code(src)
+	}
+	return strings.Join(sources, ", ")
+}
+
+// writeDescription writes an object comment as its own paragraph.
+func (r *renderer) writeDescription(desc string) {
+	if desc = strings.TrimSpace(desc); desc != "" {
+		r.sb.WriteString(prose(desc) + "\n\n")
+	}
+}
+
+// oneLine folds a multi-line comment into a single table cell.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeTableHeader(sb *strings.Builder, headers []string) {
+	writeTableRow(sb, headers)
+	seps := make([]string, len(headers))
+	for i, h := range headers {
+		seps[i] = strings.Repeat("-", len(h)+2)
+	}
+	sb.WriteString("|" + strings.Join(seps, "|") + "|\n")
+}
+
+// writeTableRow writes one table row, escaping the pipes in its cells.
+func writeTableRow(sb *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = escapePipes(c)
+	}
+	sb.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+}
+
+type omittedColumns struct {
+	typ   string
+	count int
+}
+
+func (o omittedColumns) label() string {
+	if o.count == 1 {
+		return "1 column omitted"
+	}
+	return fmt.Sprintf("%d columns omitted", o.count)
+}
+
+// filterColumns drops columns hidden by the type filters. The returned
+// summaries are only populated when SummarizeExcludedColumns is set, in
+// order of first occurrence.
+func (r *renderer) filterColumns(relation string, columns []pg.Column) ([]pg.Column, []omittedColumns) {
+	if len(r.opts.ColumnTypeFilters) == 0 {
+		return columns, nil
+	}
+
+	var kept []pg.Column
+	var omitted []omittedColumns
+	for _, col := range columns {
+		if !r.columnExcluded(relation, col) {
+			kept = append(kept, col)
+			continue
+		}
+		if !r.opts.SummarizeExcludedColumns {
+			continue
+		}
+		found := false
+		for i := range omitted {
+			if omitted[i].typ == col.DeclaredType() {
+				omitted[i].count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			omitted = append(omitted, omittedColumns{typ: col.DeclaredType(), count: 1})
+		}
+	}
+	return kept, omitted
+}
+
+func (r *renderer) columnExcluded(relation string, col pg.Column) bool {
+	for _, f := range r.opts.ColumnTypeFilters {
+		if f.TablePattern != "" {
+			if ok, _ := path.Match(f.TablePattern, relation); !ok {
+				continue
+			}
+		}
+		for _, t := range f.Types {
+			if strings.EqualFold(t, col.Type) || strings.EqualFold(t, col.DeclaredType()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func buildConstraints(col pg.Column) string {
+	var parts []string
+
+	if col.IsPK {
+		parts = append(parts, "PK")
+	}
+	if !col.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	if col.IsUnique && !col.IsPK {
+		parts = append(parts, "UNIQUE")
+	}
+	if col.FKRef != "" {
+		fk := "FK→" + text(col.FKRef)
+		if col.FKOnDelete != "" {
+			fk += " ON DELETE " + col.FKOnDelete
+		}
+		if col.FKOnUpdate != "" {
+			fk += " ON UPDATE " + col.FKOnUpdate
+		}
+		parts = append(parts, fk)
+	}
+	if col.Identity != "" {
+		parts = append(parts, "IDENTITY "+col.Identity)
+	} else if col.Default != "" {
+		parts = append(parts, fmt.Sprintf("DEFAULT %s", col.Default))
+	}
+	if col.Storage != "" {
+		parts = append(parts, fmt.Sprintf("STORAGE %s", col.Storage))
+	}
+	if col.Compression != "" {
+		parts = append(parts, fmt.Sprintf("COMPRESSION %s", col.Compression))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// AnchorID returns the stable anchor for an object. Only the object's own
+// kind, schema and name feed into it, so regenerating the document or
+// renaming unrelated objects never changes existing links. Anchors are
+// lower case ASCII; when the schema or name holds anything else (upper
+// case, spaces, punctuation such as "-" or "[]", non-Latin letters) the
+// slug alone could match another object's, so a suffix hashed from the
+// exact kind, schema and name is added: "Users" and users, or "a-b".c
+// and a."b-c", link to different places.
+func AnchorID(kind, schema, name string) string {
+	id := kind + "-" + schema
+	if name != "" {
+		id += "-" + name
+	}
+	slug := slugify(id)
+	if !plainSlug(schema) || !plainSlug(name) || slug != id {
+		slug += "-" + nameHash(kind, schema, name)
+	}
+	return slug
+}
+
+// nameHash hashes the exact spelling of parts, kept apart so that moving
+// a separator between them changes the result.
+func nameHash(parts ...string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.Join(parts, "\x00")))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// plainSlug reports whether s is made only of characters slugify keeps,
+// so it cannot run into a neighbouring part of an anchor.
+func plainSlug(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(s) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' {
+			b.WriteRune(c)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// functionAnchorName disambiguates overloads by folding the identity
+// argument types into the anchor, as pg_get_function_identity_arguments
+// lists them but without names or defaults, so renaming a parameter
+// keeps links working. Functions without arguments keep their bare name.
+func functionAnchorName(fn pg.Function) string {
+	var types []string
+	for _, a := range fn.Args {
+		// Output parameters identify procedures but not functions.
+		if a.Mode == "TABLE" || a.Mode == "OUT" && fn.Kind != "procedure" {
+			continue
+		}
+		types = append(types, a.Type)
+	}
+	if len(types) == 0 {
+		return fn.Name
+	}
+	return fn.Name + "(" + strings.Join(types, ", ") + ")"
+}
+
+func (r *renderer) qualify(schema, name string) string {
+	if r.opts.QualifyNames && schema != "" {
+		return schema + "." + name
+	}
+	return name
+}
+
+func (r *renderer) writeAnchor(kind, schema, name string) {
+	if r.opts.Anchors {
+		fmt.Fprintf(r.sb, "<a id=\"%s\"></a>\n", AnchorID(kind, schema, name))
+	}
+}
+
+// link returns the href of an object's anchor, or "" without anchors.
+func (r *renderer) link(kind, schema, name string) string {
+	if !r.opts.Anchors {
+		return ""
+	}
+	id := "#" + AnchorID(kind, schema, name)
+	if r.multiPage {
+		return r.pageDir + schemaFile(schema) + id
+	}
+	return id
+}
+
+func (r *renderer) inlineAnchor(kind, schema, name string) string {
+	if !r.opts.Anchors {
+		return ""
+	}
+	return fmt.Sprintf("<a id=\"%s\"></a>", AnchorID(kind, schema, name))
+}
@@ -0,0 +1,1862 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestRender_EmptySchema(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{Name: "public"},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "# Database Schema Documentation") {
+		t.Error("expected header not found")
+	}
+	if !strings.Contains(result, "## Schema: public") {
+		t.Error("expected schema name not found")
+	}
+}
+
+func TestRender_TableWithColumns(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid", Nullable: false, IsPK: true},
+						{Name: "email", Type: "text", Nullable: false, IsUnique: true},
+						{Name: "name", Type: "text", Nullable: true},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Tables") {
+		t.Error("expected Tables section not found")
+	}
+	if !strings.Contains(result, "#### users") {
+		t.Error("expected table name not found")
+	}
+	if !strings.Contains(result, "| id | uuid | PK, NOT NULL |") {
+		t.Error("expected PK column not found")
+	}
+	if !strings.Contains(result, "| email | text | NOT NULL, UNIQUE |") {
+		t.Error("expected unique column not found")
+	}
+	if !strings.Contains(result, "| name | text |  |") {
+		t.Error("expected nullable column not found")
+	}
+}
+
+func TestRender_TableWithForeignKey(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "posts",
+					Columns: []pg.Column{
+						{Name: "user_id", Type: "uuid", Nullable: false, FKRef: "public.users.id"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "FK→public.users.id") {
+		t.Error("expected FK reference not found")
+	}
+}
+
+func TestRender_TableWithIndexes(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid", IsPK: true},
+					},
+					Indexes: []pg.Index{
+						{Name: "users_pkey", Columns: []string{"id"}, IsPrimary: true},
+						{Name: "idx_email", Columns: []string{"email"}, IsUnique: true},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "**Indexes:**") {
+		t.Error("expected Indexes section not found")
+	}
+	if !strings.Contains(result, "users_pkey (id, PK)") {
+		t.Error("expected primary key index not found")
+	}
+	if !strings.Contains(result, "idx_email (email, UNIQUE)") {
+		t.Error("expected unique index not found")
+	}
+}
+
+func TestRender_Views(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Views: []pg.View{
+				{
+					Schema: "public",
+					Name:   "active_users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid"},
+						{Name: "email", Type: "text"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Views") {
+		t.Error("expected Views section not found")
+	}
+	if !strings.Contains(result, "#### active_users") {
+		t.Error("expected view name not found")
+	}
+}
+
+func TestRender_ViewColumnLineage(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Views: []pg.View{
+				{
+					Schema: "public",
+					Name:   "active_users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid", DerivedFrom: []string{"public.users.id"}},
+						{Name: "email", Type: "text", DerivedFrom: []string{"public.users.email", "public.users.login"}},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "| id | uuid | `public.users.id` |") {
+		t.Errorf("expected single-source lineage not found:\n%s", result)
+	}
+	if !strings.Contains(result, "| email | text | `public.users.email`, `public.users.login` |") {
+		t.Errorf("expected column lineage not found:\n%s", result)
+	}
+}
+
+func TestRender_ViewDefinitions(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Views: []pg.View{
+				{
+					Schema:     "public",
+					Name:       "active_users",
+					Columns:    []pg.Column{{Name: "id", Type: "uuid"}, {Name: "email", Type: "text"}},
+					Definition: " SELECT id,\n    email\n   FROM users\n  WHERE active;",
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	expected := "\n<details>\n<summary>Definition</summary>\n\n```sql\nSELECT id,\n    email\n   FROM users\n  WHERE active;\n```\n\n</details>\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected collapsed view definition not found:\n%s", result)
+	}
+}
+
+func TestRender_MaterializedViews(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			MaterializedViews: []pg.MaterializedView{
+				{
+					Schema: "public",
+					Name:   "user_stats",
+					Columns: []pg.Column{
+						{Name: "user_id", Type: "uuid"},
+						{Name: "post_count", Type: "bigint"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Materialized Views") {
+		t.Error("expected Materialized Views section not found")
+	}
+	if !strings.Contains(result, "#### user_stats") {
+		t.Error("expected materialized view name not found")
+	}
+}
+
+func TestRender_Sequences(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Sequences: []pg.Sequence{
+				{
+					Schema:    "public",
+					Name:      "users_id_seq",
+					DataType:  "bigint",
+					Start:     1,
+					Min:       1,
+					Max:       9223372036854775807,
+					Increment: 1,
+					Cycle:     false,
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Sequences") {
+		t.Error("expected Sequences section not found")
+	}
+	if !strings.Contains(result, "`users_id_seq` (bigint)") {
+		t.Error("expected sequence name not found")
+	}
+	if !strings.Contains(result, "start=1") {
+		t.Error("expected start value not found")
+	}
+}
+
+func TestRender_SequenceWithCycle(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Sequences: []pg.Sequence{
+				{
+					Schema:    "public",
+					Name:      "rotating_seq",
+					DataType:  "integer",
+					Start:     1,
+					Min:       1,
+					Max:       100,
+					Increment: 1,
+					Cycle:     true,
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, ", CYCLE") {
+		t.Error("expected CYCLE flag not found")
+	}
+}
+
+func TestRender_SequenceCacheAndValues(t *testing.T) {
+	lastValue := int64(1042)
+	seq := func(name string) pg.Sequence {
+		return pg.Sequence{Schema: "public", Name: name, DataType: "bigint", Start: 1, Min: 1, Max: 1000000, Increment: 1, Cache: 20}
+	}
+
+	few := seq("orders_id_seq")
+	few.LastValue = &lastValue
+	result := Render([]pg.SchemaInfo{{Name: "public", Sequences: []pg.Sequence{few}}}, Options{})
+	if !strings.Contains(result, "- `orders_id_seq` (bigint): start=1, inc=1, range=[1..1000000], cache=20, last=1042\n") {
+		t.Errorf("expected cache and last value in sequence list:\n%s", result)
+	}
+
+	var many []pg.Sequence
+	for _, name := range []string{"a_seq", "b_seq", "c_seq", "d_seq", "e_seq", "f_seq"} {
+		many = append(many, seq(name))
+	}
+	many[0].LastValue = &lastValue
+	result = Render([]pg.SchemaInfo{{Name: "public", Sequences: many}}, Options{})
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"header", "| Sequence | Type | Start | Increment | Range | Cache | Cycle | Last Value |\n"},
+		{"used", "| a_seq | bigint | 1 | 1 | 1..1000000 | 20 | no | 1042 |\n"},
+		{"unused", "| f_seq | bigint | 1 | 1 | 1..1000000 | 20 | no |  |\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRender_Triggers(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Triggers: []pg.Trigger{
+				{
+					Schema:   "public",
+					Table:    "users",
+					Name:     "update_timestamp",
+					Event:    "UPDATE",
+					Timing:   "BEFORE",
+					Function: "set_updated_at",
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Triggers") {
+		t.Error("expected Triggers section not found")
+	}
+	if !strings.Contains(result, "`update_timestamp` on `users`") {
+		t.Error("expected trigger name not found")
+	}
+	if !strings.Contains(result, "BEFORE UPDATE") {
+		t.Error("expected trigger timing/event not found")
+	}
+	if !strings.Contains(result, "set_updated_at()") {
+		t.Error("expected trigger function not found")
+	}
+}
+
+func TestRender_Functions(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{
+					Schema:     "public",
+					Name:       "get_user",
+					Arguments:  "id uuid",
+					ReturnType: "users",
+				},
+				{
+					Schema:     "public",
+					Name:       "count_users",
+					Arguments:  "",
+					ReturnType: "bigint",
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Functions") {
+		t.Error("expected Functions section not found")
+	}
+	if !strings.Contains(result, "`get_user(id uuid) → users`") {
+		t.Error("expected function with args not found")
+	}
+	if !strings.Contains(result, "`count_users() → bigint`") {
+		t.Error("expected function without args not found")
+	}
+}
+
+func TestRender_FunctionDescriptions(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{
+					Schema:      "public",
+					Name:        "count_users",
+					ReturnType:  "bigint",
+					Description: "Number of registered users",
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "`count_users() → bigint` — Number of registered users\n") {
+		t.Errorf("expected function description not found:\n%s", result)
+	}
+}
+
+func TestRender_FunctionDefinitions(t *testing.T) {
+	def := "CREATE OR REPLACE FUNCTION public.touch()\n RETURNS trigger\n LANGUAGE plpgsql\nAS $function$\nBEGIN\n  NEW.updated_at := now();\n\n  RETURN NEW;\nEND;\n$function$\n"
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Functions: []pg.Function{
+			{Schema: "public", Name: "now_utc", ReturnType: "timestamp"},
+			{Schema: "public", Name: "touch", ReturnType: "trigger", Definition: def},
+		},
+	}}
+
+	result := Render(schemas, Options{})
+
+	expected := "- `touch() → trigger`\n\n  <details>\n  <summary>Definition</summary>\n\n  ```sql\n  CREATE OR REPLACE FUNCTION public.touch()\n" +
+		"   RETURNS trigger\n   LANGUAGE plpgsql\n  AS $function$\n  BEGIN\n    NEW.updated_at := now();\n\n    RETURN NEW;\n  END;\n  $function$\n  ```\n\n  </details>\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected collapsed definition:\n%s", result)
+	}
+	if strings.Count(result, "<details>") != 1 {
+		t.Errorf("expected only functions with a definition to have one:\n%s", result)
+	}
+}
+
+func TestRender_Procedures(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{Schema: "public", Name: "archive_orders", Kind: "procedure", Arguments: "before date", Args: []pg.Argument{{Name: "before", Type: "date", Mode: "IN"}}, Description: "Moves old orders"},
+				{Schema: "public", Name: "count_orders", ReturnType: "bigint"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{Anchors: true})
+
+	expected := "### Functions\n\n- <a id=\"function-public-count_orders\"></a>`count_orders() → bigint`\n\n" +
+		"### Procedures\n\n- <a id=\"procedure-public-archive_orders-date-f33bd9ec\"></a>`archive_orders(before date)` — Moves old orders\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected procedures in a section of their own:\n%s", result)
+	}
+}
+
+func TestRender_FunctionTraits(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Functions: []pg.Function{
+			{Schema: "public", Name: "grant_admin", Arguments: "id uuid", ReturnType: "void", Language: "plpgsql", Volatility: "volatile", SecurityDefiner: true, Cost: 100, Description: "Promotes a user"},
+			{Schema: "public", Name: "slugify", Arguments: "s text", ReturnType: "text", Language: "sql", Volatility: "immutable", Cost: 5},
+			{Schema: "public", Name: "hash", Arguments: "bytea", ReturnType: "bytea", Language: "c", Volatility: "immutable", Cost: 1},
+		},
+	}}
+
+	result := Render(schemas, Options{})
+
+	for _, expected := range []string{
+		"`grant_admin(id uuid) → void` (plpgsql, volatile, **SECURITY DEFINER**) — Promotes a user\n",
+		"`slugify(s text) → text` (sql, immutable, cost 5)\n",
+		"`hash(bytea) → bytea` (c, immutable)\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %q in:\n%s", expected, result)
+		}
+	}
+}
+
+func TestRender_FunctionArgTables(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{
+					Schema:     "public",
+					Name:       "search",
+					Arguments:  "query text, lim integer DEFAULT 10, OUT total bigint",
+					ReturnType: "bigint",
+					Args: []pg.Argument{
+						{Name: "query", Type: "text", Mode: "IN"},
+						{Name: "lim", Type: "integer", Mode: "IN", Default: "10"},
+						{Name: "total", Type: "bigint", Mode: "OUT"},
+					},
+				},
+				{Schema: "public", Name: "now_utc", ReturnType: "timestamp without time zone"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{FunctionArgTables: true})
+
+	expected := "- `search(…) → bigint`\n\n  | Name | Type | Mode | Default |\n  |------|------|------|---------|\n  | query | text | IN |  |\n  | lim | integer | IN | `10` |\n  | total | bigint | OUT |  |\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected argument table not found:\n%s", result)
+	}
+	if !strings.Contains(result, "- `now_utc() → timestamp without time zone`\n") {
+		t.Error("expected function without args to keep its inline signature")
+	}
+}
+
+func TestRender_SetReturningFunctions(t *testing.T) {
+	rows := []pg.Column{
+		{Name: "id", Position: 1, Type: "bigint", Nullable: true},
+		{Name: "title", Position: 2, Type: "text", Nullable: true},
+	}
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{
+					Schema:        "public",
+					Name:          "search",
+					Arguments:     "q text",
+					ReturnType:    "TABLE(id bigint, title text)",
+					ReturnsSet:    true,
+					ReturnColumns: rows,
+					Args: []pg.Argument{
+						{Name: "q", Type: "text", Mode: "IN"},
+						{Name: "id", Type: "bigint", Mode: "TABLE"},
+						{Name: "title", Type: "text", Mode: "TABLE"},
+					},
+				},
+			},
+		},
+	}
+
+	returned := "  Returns rows of:\n\n  | Column | Type |\n  |--------|------|\n  | id | bigint |\n  | title | text |\n\n"
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"inline signature", Options{}, "- `search(q text) → TABLE(id bigint, title text)`\n\n" + returned},
+		{"argument table", Options{FunctionArgTables: true}, "- `search(…) → TABLE(…)`\n\n  | Name | Type | Mode | Default |\n  |------|------|------|---------|\n  | q | text | IN |  |\n\n" + returned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Render(schemas, tt.opts)
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRender_CustomTypes(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Types: []pg.CustomType{
+				{
+					Schema: "public",
+					Name:   "status",
+					Kind:   "enum",
+					Values: []string{"pending", "active", "archived"},
+				},
+				{
+					Schema: "public",
+					Name:   "address",
+					Kind:   "composite",
+					Values: []string{"street text", "city text", "zip text"},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "### Custom Types") {
+		t.Error("expected Custom Types section not found")
+	}
+	if !strings.Contains(result, "`status`: 'pending', 'active', 'archived'") {
+		t.Error("expected enum type not found")
+	}
+	if !strings.Contains(result, "`address` (composite)") {
+		t.Error("expected composite type not found")
+	}
+}
+
+func TestRender_EnumValueDescriptions(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Types: []pg.CustomType{
+				{
+					Schema:            "public",
+					Name:              "order_status",
+					Kind:              "enum",
+					Description:       "Order lifecycle",
+					Values:            []string{"pending", "paid"},
+					ValueDescriptions: map[string]string{"pending": "Awaiting payment"},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	expected := "#### order_status (enum)\n\nOrder lifecycle\n\n| Value | Description |\n|-------|-------------|\n| `pending` | Awaiting payment |\n| `paid` |  |\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected enum value table not found:\n%s", result)
+	}
+}
+
+func TestRender_CompositeTypeAttributes(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Types: []pg.CustomType{
+				{
+					Schema: "public",
+					Name:   "address",
+					Kind:   "composite",
+					Values: []string{"street text", "city text", "zip text"},
+				},
+				{
+					Schema:      "public",
+					Name:        "money_amount",
+					Kind:        "composite",
+					Description: "An amount in a currency",
+					Values:      []string{"amount numeric(12,2)", "currency character(3)"},
+					Attributes: []pg.Column{
+						{Name: "amount", Position: 1, Type: "numeric(12,2)", Nullable: true},
+						{Name: "currency", Position: 2, Type: "character(3)", Nullable: true, Description: "ISO 4217 code"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "`address` (composite): street text, city text, zip text") {
+		t.Error("expected composite type without attributes to fall back to a list")
+	}
+	expected := "#### money_amount (composite)\n\nAn amount in a currency\n\n| Column | Type | Description |\n|--------|------|-------------|\n| amount | numeric(12,2) |  |\n| currency | character(3) | ISO 4217 code |\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected composite attribute table not found:\n%s", result)
+	}
+}
+
+func TestRender_MultipleSchemas(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{Name: "public"},
+		{Name: "auth"},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "## Schema: public") {
+		t.Error("expected public schema not found")
+	}
+	if !strings.Contains(result, "## Schema: auth") {
+		t.Error("expected auth schema not found")
+	}
+	if !strings.Contains(result, "---") {
+		t.Error("expected schema separator not found")
+	}
+}
+
+func TestBuildConstraints(t *testing.T) {
+	tests := []struct {
+		name     string
+		col      pg.Column
+		expected string
+	}{
+		{
+			name:     "primary key",
+			col:      pg.Column{IsPK: true, Nullable: false},
+			expected: "PK, NOT NULL",
+		},
+		{
+			name:     "unique not null",
+			col:      pg.Column{IsUnique: true, Nullable: false},
+			expected: "NOT NULL, UNIQUE",
+		},
+		{
+			name:     "foreign key",
+			col:      pg.Column{FKRef: "public.users.id", Nullable: false},
+			expected: "NOT NULL, FK→public.users.id",
+		},
+		{
+			name:     "with default",
+			col:      pg.Column{Default: "now()", Nullable: false},
+			expected: "NOT NULL, DEFAULT now()",
+		},
+		{
+			name:     "nullable no constraints",
+			col:      pg.Column{Nullable: true},
+			expected: "",
+		},
+		{
+			name:     "pk is also unique - no duplicate",
+			col:      pg.Column{IsPK: true, IsUnique: true, Nullable: false},
+			expected: "PK, NOT NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildConstraints(tt.col)
+			if result != tt.expected {
+				t.Errorf("buildConstraints() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildConstraints_ReferentialActions(t *testing.T) {
+	col := pg.Column{FKRef: "public.users.id", FKOnDelete: "CASCADE", FKOnUpdate: "SET NULL", Nullable: true}
+	if got, want := buildConstraints(col), "FK→public.users.id ON DELETE CASCADE ON UPDATE SET NULL"; got != want {
+		t.Errorf("buildConstraints() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildConstraints_Identity(t *testing.T) {
+	col := pg.Column{Identity: "BY DEFAULT", Default: "nextval('t_id_seq'::regclass)"}
+	if got, want := buildConstraints(col), "NOT NULL, IDENTITY BY DEFAULT"; got != want {
+		t.Errorf("buildConstraints() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildConstraints_StorageAndCompression(t *testing.T) {
+	col := pg.Column{Nullable: true, Storage: "EXTERNAL", Compression: "lz4"}
+	if got, want := buildConstraints(col), "STORAGE EXTERNAL, COMPRESSION lz4"; got != want {
+		t.Errorf("buildConstraints() = %q, want %q", got, want)
+	}
+}
+
+func TestRender_Anchors(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users"},
+			},
+			Functions: []pg.Function{
+				{Schema: "public", Name: "set_updated_at", ReturnType: "trigger"},
+				{Schema: "public", Name: "get_user", Arguments: "id uuid", Args: []pg.Argument{{Name: "id", Type: "uuid", Mode: "IN"}}, ReturnType: "users"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{Anchors: true})
+
+	if !strings.Contains(result, "<a id=\"schema-public\"></a>\n## Schema: public") {
+		t.Error("expected schema anchor not found")
+	}
+	if !strings.Contains(result, "<a id=\"table-public-users\"></a>\n#### users") {
+		t.Error("expected table anchor not found")
+	}
+	if !strings.Contains(result, "<a id=\"function-public-set_updated_at\"></a>`set_updated_at()") {
+		t.Error("expected function anchor not found")
+	}
+	if !strings.Contains(result, "<a id=\"function-public-get_user-uuid-26b41d56\"></a>") {
+		t.Error("expected overload-aware function anchor not found")
+	}
+
+	if strings.Contains(Render(schemas, Options{}), "<a id=") {
+		t.Error("anchors rendered without the option")
+	}
+}
+
+func TestAnchorID(t *testing.T) {
+	tests := []struct {
+		kind, schema, name string
+		expected           string
+	}{
+		{"table", "public", "users", "table-public-users"},
+		{"table", "auth", "user accounts", "table-auth-user-accounts-0da5500e"},
+		{"table", "public", "Users", "table-public-users-20b2e478"},
+		{"schema", "public", "", "schema-public"},
+		{"function", "public", "f(a integer, b text)", "function-public-f-a-integer-b-text-6d1c153f"},
+	}
+
+	for _, tt := range tests {
+		if got := AnchorID(tt.kind, tt.schema, tt.name); got != tt.expected {
+			t.Errorf("AnchorID(%q, %q, %q) = %q, want %q", tt.kind, tt.schema, tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestAnchorID_Unique(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b [3]string
+	}{
+		{"non-ASCII names", [3]string{"table", "public", "χρήστες"}, [3]string{"table", "public", "παραγγελίες"}},
+		{"array overloads", [3]string{"function", "public", "f(integer)"}, [3]string{"function", "public", "f(integer[])"}},
+		{"separator in schema or name", [3]string{"table", "a-b", "c"}, [3]string{"table", "a", "b-c"}},
+		{"space and dash", [3]string{"table", "public", "order items"}, [3]string{"table", "public", "order-items"}},
+		{"case", [3]string{"table", "public", "Users"}, [3]string{"table", "public", "users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := AnchorID(tt.a[0], tt.a[1], tt.a[2]), AnchorID(tt.b[0], tt.b[1], tt.b[2])
+			if a == b {
+				t.Errorf("AnchorID(%q) and AnchorID(%q) are both %q", tt.a, tt.b, a)
+			}
+		})
+	}
+}
+
+func TestFunctionAnchorName(t *testing.T) {
+	tests := []struct {
+		fn       pg.Function
+		expected string
+	}{
+		{pg.Function{Name: "now_utc"}, "now_utc"},
+		{pg.Function{Name: "add", Args: []pg.Argument{{Name: "a", Type: "integer", Mode: "IN"}, {Name: "b", Type: "integer", Mode: "IN", Default: "1"}}}, "add(integer, integer)"},
+		{pg.Function{Name: "add", Args: []pg.Argument{{Name: "x", Type: "integer", Mode: "IN"}, {Type: "integer", Mode: "IN"}}}, "add(integer, integer)"},
+		{pg.Function{Name: "stats", Args: []pg.Argument{{Name: "total", Type: "bigint", Mode: "OUT"}}}, "stats"},
+		{pg.Function{Name: "stats", Kind: "procedure", Args: []pg.Argument{{Name: "total", Type: "bigint", Mode: "OUT"}}}, "stats(bigint)"},
+		{pg.Function{Name: "search", Args: []pg.Argument{{Name: "q", Type: "text", Mode: "IN"}, {Name: "id", Type: "integer", Mode: "TABLE"}}}, "search(text)"},
+	}
+
+	for _, tt := range tests {
+		if got := functionAnchorName(tt.fn); got != tt.expected {
+			t.Errorf("functionAnchorName(%+v) = %q, want %q", tt.fn, got, tt.expected)
+		}
+	}
+}
+
+func TestRender_ExcludeColumnTypes(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "files",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid", IsPK: true},
+						{Name: "blob", Type: "bytea", Nullable: true},
+						{Name: "thumb", Type: "bytea", Nullable: true},
+					},
+				},
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "avatar", Type: "bytea", Nullable: true},
+					},
+				},
+			},
+		},
+	}
+	filter, err := ParseColumnTypeFilter("file*=BYTEA")
+	if err != nil {
+		t.Fatalf("ParseColumnTypeFilter() error = %v", err)
+	}
+
+	result := Render(schemas, Options{ColumnTypeFilters: []ColumnTypeFilter{filter}})
+
+	if strings.Contains(result, "| blob |") || strings.Contains(result, "| thumb |") {
+		t.Error("expected bytea columns of files to be hidden")
+	}
+	if !strings.Contains(result, "| avatar | bytea |") {
+		t.Error("expected bytea column of non-matching table to be kept")
+	}
+	if strings.Contains(result, "omitted") {
+		t.Error("unexpected summary row without SummarizeExcludedColumns")
+	}
+
+	result = Render(schemas, Options{ColumnTypeFilters: []ColumnTypeFilter{filter}, SummarizeExcludedColumns: true})
+
+	if !strings.Contains(result, "| _2 columns omitted_ | bytea |  |") {
+		t.Error("expected summary row for hidden columns not found")
+	}
+}
+
+func TestParseColumnTypeFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ColumnTypeFilter
+		wantErr  bool
+	}{
+		{
+			name:     "types only",
+			input:    "bytea, tsvector",
+			expected: ColumnTypeFilter{Types: []string{"bytea", "tsvector"}},
+		},
+		{
+			name:     "with table pattern",
+			input:    "events_*=jsonb",
+			expected: ColumnTypeFilter{TablePattern: "events_*", Types: []string{"jsonb"}},
+		},
+		{
+			name:    "no types",
+			input:   "events_*=",
+			wantErr: true,
+		},
+		{
+			name:    "bad pattern",
+			input:   "[=bytea",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseColumnTypeFilter(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColumnTypeFilter(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseColumnTypeFilter(%q) = %+v, want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRender_FunctionOverloads(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Functions: []pg.Function{
+				{Schema: "public", Name: "auth_check", Arguments: "id uuid", ReturnType: "boolean"},
+				{Schema: "public", Name: "auth_login", Arguments: "email text", ReturnType: "uuid"},
+				{Schema: "public", Name: "now_utc", ReturnType: "timestamp"},
+				{Schema: "public", Name: "to_cents", Arguments: "amount numeric", ReturnType: "bigint"},
+				{Schema: "public", Name: "to_cents", Arguments: "amount text", ReturnType: "bigint"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "- `to_cents` (2 overloads)\n  - `to_cents(amount numeric) → bigint`\n  - `to_cents(amount text) → bigint`\n") {
+		t.Error("expected overloads grouped under a single entry")
+	}
+	if !strings.Contains(result, "- `now_utc() → timestamp`\n") {
+		t.Error("expected single function rendered flat")
+	}
+	if strings.Contains(result, "#### auth_*") {
+		t.Error("unexpected prefix group without GroupFunctionsByPrefix")
+	}
+
+	result = Render(schemas, Options{GroupFunctionsByPrefix: true})
+
+	if !strings.Contains(result, "#### auth_\\*\n\n- `auth_check(id uuid) → boolean`\n- `auth_login(email text) → uuid`\n") {
+		t.Error("expected auth_* prefix group not found")
+	}
+	if !strings.Contains(result, "#### Other\n\n- `now_utc() → timestamp`\n- `to_cents` (2 overloads)") {
+		t.Error("expected ungrouped functions under Other")
+	}
+}
+
+func TestRender_Grants(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:  "public",
+					Name:    "orders",
+					Columns: []pg.Column{{Name: "total", Type: "numeric", Nullable: true}},
+					Grants: []pg.Grant{
+						{Grantee: "app_rw", Privileges: []string{"DELETE", "INSERT", "SELECT", "UPDATE"}},
+						{Grantee: "reporting", Privileges: []string{"SELECT"}},
+						{Grantee: "auditor", Privileges: []string{"SELECT"}, Column: "total"},
+					},
+				},
+			},
+			Functions: []pg.Function{
+				{Schema: "public", Name: "refund", ReturnType: "void", Grants: []pg.Grant{
+					{Grantee: "PUBLIC", Privileges: []string{"EXECUTE"}},
+					{Grantee: "owner", Privileges: []string{"EXECUTE"}},
+				}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	expected := "**Grants:**\n\n| Role | SELECT | INSERT | UPDATE | DELETE |\n|------|--------|--------|--------|--------|\n" +
+		"| app_rw | ✓ | ✓ | ✓ | ✓ |\n" +
+		"| reporting | ✓ |  |  |  |\n" +
+		"| auditor (`total`) | ✓ |  |  |  |\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected grants matrix, got:\n%s", result)
+	}
+	if !strings.Contains(result, "- `refund() → void`\n  Executable by: `PUBLIC`, `owner`\n") {
+		t.Error("expected function grantees")
+	}
+}
+
+func TestRender_FunctionGrantsWithoutExecute(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Functions: []pg.Function{
+			{Schema: "public", Name: "purge", ReturnType: "void", Grants: []pg.Grant{
+				{Grantee: "app", Privileges: []string{"USAGE"}},
+			}},
+		},
+	}}
+
+	result := Render(schemas, Options{})
+
+	if strings.Contains(result, "Executable by") {
+		t.Errorf("expected no grantee line without an EXECUTE grant:\n%s", result)
+	}
+}
+
+func TestRender_TableChapters(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "auth_sessions"},
+				{Schema: "public", Name: "billing_invoices"},
+				{Schema: "public", Name: "settings"},
+			},
+		},
+	}
+	chapters := []Chapter{
+		{Title: "Billing", Patterns: []string{"billing_*"}},
+		{Title: "Auth", Patterns: []string{"auth_*"}},
+		{Title: "Empty", Patterns: []string{"nothing_*"}},
+	}
+
+	result := Render(schemas, Options{TableChapters: chapters, Anchors: true})
+
+	billing := strings.Index(result, "### Billing\n\n- [billing_invoices](#table-public-billing_invoices)\n\n")
+	auth := strings.Index(result, "### Auth\n\n- [auth_sessions](#table-public-auth_sessions)\n\n")
+	other := strings.Index(result, "### Other Tables\n\n")
+	if billing < 0 || auth < 0 || other < 0 {
+		t.Fatalf("expected chapters with contents, got:\n%s", result)
+	}
+	if !(billing < auth && auth < other && other < strings.Index(result, "#### settings")) {
+		t.Error("expected chapters in configured order, then the remaining tables")
+	}
+	if strings.Contains(result, "### Empty") || strings.Contains(result, "### Tables") {
+		t.Error("expected no heading for an empty chapter or a plain Tables section")
+	}
+}
+
+func TestRender_Limits(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "accounts"},
+				{Schema: "public", Name: "orders"},
+				{Schema: "public", Name: "users"},
+			},
+			Functions: []pg.Function{
+				{Schema: "public", Name: "a_fn", ReturnType: "void"},
+				{Schema: "public", Name: "b_fn", ReturnType: "void"},
+			},
+			Types: []pg.CustomType{{Schema: "public", Name: "mood", Kind: "enum", Values: []string{"ok"}}},
+		},
+	}
+
+	result := Render(schemas, Options{MaxTablesPerSchema: 2, SectionLimit: 1})
+
+	if !strings.Contains(result, "#### orders") || strings.Contains(result, "#### users") {
+		t.Errorf("expected the first two tables only, got:\n%s", result)
+	}
+	if !strings.Contains(result, "_… 1 more tables omitted._") {
+		t.Error("expected omitted tables marker")
+	}
+	if strings.Contains(result, "b_fn") || !strings.Contains(result, "_… 1 more functions omitted._") {
+		t.Error("expected functions cut to the section limit")
+	}
+	if strings.Contains(result, "more types omitted") {
+		t.Error("expected no marker for a section within the limit")
+	}
+}
+
+func TestRender_TableLayout(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Type: "bigint", IsPK: true, Default: "nextval('users_id_seq'::regclass)"},
+					},
+				},
+			},
+			Views: []pg.View{
+				{Schema: "public", Name: "active_users"},
+			},
+			Sequences: []pg.Sequence{
+				{Schema: "public", Name: "users_id_seq", DataType: "bigint"},
+				{Schema: "public", Name: "invoice_no_seq", DataType: "bigint"},
+			},
+			Triggers: []pg.Trigger{
+				{Schema: "public", Table: "users", Name: "touch", Event: "UPDATE", Timing: "BEFORE", Function: "set_updated_at"},
+				{Schema: "public", Table: "active_users", Name: "redirect", Event: "INSERT", Timing: "INSTEAD OF", Function: "insert_user"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{Layout: LayoutTable})
+
+	if !strings.Contains(result, "**Sequences:**\n\n- `users_id_seq` (bigint)") {
+		t.Error("expected owned sequence under its table")
+	}
+	if !strings.Contains(result, "**Triggers:**\n\n- `touch` on `users`") {
+		t.Error("expected trigger under its table")
+	}
+	if !strings.Contains(result, "### Sequences\n\n- `invoice_no_seq`") {
+		t.Error("expected unattached sequence at schema level")
+	}
+	if !strings.Contains(result, "### Triggers\n\n- `redirect` on `active_users`") {
+		t.Error("expected view trigger at schema level")
+	}
+	if strings.Count(result, "`touch` on `users`") != 1 {
+		t.Error("expected table trigger rendered exactly once")
+	}
+}
+
+func TestRender_IdentityColumn(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "orders",
+					Columns: []pg.Column{
+						{Name: "id", Type: "bigint", IsPK: true, Identity: "ALWAYS"},
+						{Name: "number", Type: "integer", Nullable: true, Default: "nextval('order_number_seq'::regclass)"},
+					},
+				},
+			},
+			Sequences: []pg.Sequence{
+				{Schema: "public", Name: "order_number_seq", DataType: "integer", OwnerTable: "orders", OwnerColumn: "number"},
+				{Schema: "public", Name: "orders_id_seq", DataType: "bigint", OwnerTable: "orders", OwnerColumn: "id", Identity: true},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "| id | bigint | PK, NOT NULL, IDENTITY ALWAYS |") {
+		t.Errorf("expected identity in constraints, got:\n%s", result)
+	}
+	if !strings.Contains(result, "**Sequences:**\n\n- `orders_id_seq` (bigint): start=0, inc=0, range=[0..0], identity of `orders.id`") {
+		t.Error("expected identity sequence under its table")
+	}
+	if !strings.Contains(result, "### Sequences\n\n- `order_number_seq` (integer): start=0, inc=0, range=[0..0], owned by `orders.number`") {
+		t.Error("expected serial sequence at schema level with its owner")
+	}
+	if strings.Count(result, "`orders_id_seq`") != 1 {
+		t.Error("expected identity sequence rendered exactly once")
+	}
+}
+
+func TestNextvalSequence(t *testing.T) {
+	tests := []struct {
+		def      string
+		expected string
+	}{
+		{"nextval('users_id_seq'::regclass)", "users_id_seq"},
+		{"nextval('public.users_id_seq'::regclass)", "users_id_seq"},
+		{"nextval('\"Odd_Seq\"'::regclass)", "Odd_Seq"},
+		{"nextval('auth.users_id_seq'::regclass)", ""},
+		{"now()", ""},
+	}
+
+	for _, tt := range tests {
+		if got := nextvalSequence(tt.def, "public"); got != tt.expected {
+			t.Errorf("nextvalSequence(%q) = %q, want %q", tt.def, got, tt.expected)
+		}
+	}
+}
+
+func TestRender_QualifyNames(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "auth",
+			Tables: []pg.Table{
+				{Schema: "auth", Name: "users"},
+			},
+			Triggers: []pg.Trigger{
+				{Schema: "auth", Table: "users", Name: "touch", Event: "UPDATE", Timing: "BEFORE", Function: "set_updated_at"},
+			},
+			Functions: []pg.Function{
+				{Schema: "auth", Name: "uid", ReturnType: "uuid"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{QualifyNames: true})
+
+	if !strings.Contains(result, "#### auth.users") {
+		t.Error("expected qualified table heading not found")
+	}
+	if !strings.Contains(result, "`touch` on `auth.users`") {
+		t.Error("expected qualified trigger table not found")
+	}
+	if !strings.Contains(result, "`auth.uid() → uuid`") {
+		t.Error("expected qualified function name not found")
+	}
+
+	if strings.Contains(Render(schemas, Options{}), "auth.users") {
+		t.Error("names qualified without the option")
+	}
+}
+
+func TestRender_ReplicaIdentity(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "audit", ReplicaIdentity: "full"},
+				{Schema: "public", Name: "tags", ReplicaIdentity: "index", ReplicaIdentityIndex: "tags_name_key"},
+				{Schema: "public", Name: "users", ReplicaIdentity: "default"},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "#### audit\n\n| Column | Type | Constraints |\n|--------|------|-------------|\n\n**Replica identity:** full\n") {
+		t.Error("expected full replica identity under audit")
+	}
+	if !strings.Contains(result, "**Replica identity:** index (tags_name_key)") {
+		t.Error("expected index replica identity under tags")
+	}
+	if strings.Count(result, "**Replica identity:**") != 2 {
+		t.Error("expected default replica identity to be omitted")
+	}
+}
+
+func TestRender_ColumnPositionsAndOrder(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "users",
+					Columns: []pg.Column{
+						{Name: "id", Position: 1, Type: "uuid", IsPK: true},
+						{Name: "name", Position: 2, Type: "text", Nullable: true},
+						{Name: "email", Position: 4, Type: "text", Nullable: true},
+					},
+				},
+			},
+			Views: []pg.View{
+				{
+					Schema: "public",
+					Name:   "names",
+					Columns: []pg.Column{
+						{Name: "name", Position: 1, Type: "text"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{ColumnPositions: true, ColumnOrder: ColumnOrderAlphabetical})
+
+	if !strings.Contains(result, "| # | Column | Type | Constraints |\n|---|--------|------|-------------|\n") {
+		t.Error("expected position header not found")
+	}
+	if !strings.Contains(result, "| 4 | email | text |  |\n| 1 | id | uuid | PK, NOT NULL |\n| 2 | name | text |  |\n") {
+		t.Errorf("expected alphabetical rows with positions:\n%s", result)
+	}
+	if !strings.Contains(result, "| # | Column | Type |\n|---|--------|------|\n| 1 | name | text |\n") {
+		t.Error("expected positions in view columns")
+	}
+
+	result = Render(schemas, Options{})
+	if !strings.Contains(result, "| id | uuid | PK, NOT NULL |\n| name | text |  |\n| email | text |  |\n") {
+		t.Error("expected physical order by default")
+	}
+}
+
+func TestRender_ClassifyTables(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "user_roles", Columns: []pg.Column{
+					{Name: "user_id", Type: "uuid", FKRef: "public.users.id"},
+					{Name: "role_id", Type: "uuid", FKRef: "public.roles.id"},
+				}},
+				{Schema: "public", Name: "users", Columns: []pg.Column{{Name: "id", Type: "uuid"}}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{ClassifyTables: true})
+
+	if !strings.Contains(result, "#### user_roles\n\n_junction table_\n\n| Column") {
+		t.Error("expected junction badge under user_roles")
+	}
+	if strings.Contains(result, "#### users\n\n_") {
+		t.Error("unexpected badge on entity table")
+	}
+}
+
+func TestRender_Descriptions(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:      "public",
+					Name:        "users",
+					Description: "Registered accounts.",
+					Columns: []pg.Column{
+						{Name: "id", Type: "uuid", IsPK: true, Description: "Surrogate key."},
+						{Name: "email", Type: "text", Nullable: true, Description: "Login address,\nlowercased."},
+						{Name: "name", Type: "text", Nullable: true},
+					},
+				},
+				{
+					Schema:  "public",
+					Name:    "tags",
+					Columns: []pg.Column{{Name: "id", Type: "integer", Nullable: true}},
+				},
+			},
+			Views: []pg.View{
+				{
+					Schema:      "public",
+					Name:        "active_users",
+					Description: "Users seen in the last 30 days.",
+					Columns:     []pg.Column{{Name: "id", Type: "uuid", Description: "See users.id."}},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "#### users\n\nRegistered accounts.\n\n| Column | Type | Constraints | Description |\n") {
+		t.Error("expected table description and Description header not found")
+	}
+	if !strings.Contains(result, "| id | uuid | PK, NOT NULL | Surrogate key. |") {
+		t.Error("expected column description not found")
+	}
+	if !strings.Contains(result, "| email | text |  | Login address, lowercased. |") {
+		t.Error("expected multi-line description folded into one line")
+	}
+	if !strings.Contains(result, "| name | text |  |  |") {
+		t.Error("expected empty description cell")
+	}
+	if !strings.Contains(result, "#### tags\n\n| Column | Type | Constraints |\n") {
+		t.Error("expected no Description column without comments")
+	}
+	if !strings.Contains(result, "Users seen in the last 30 days.\n\n| Column | Type | Description |\n") {
+		t.Error("expected view description not found")
+	}
+}
+
+func TestRender_CheckConstraints(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "products",
+					Columns: []pg.Column{
+						{Name: "price", Type: "numeric"},
+						{Name: "sale_price", Type: "numeric", Nullable: true},
+					},
+					Checks: []pg.CheckConstraint{
+						{Name: "products_price_check", Columns: []string{"price"}, Definition: "CHECK ((price > (0)::numeric))"},
+						{Name: "sale_below_price", Columns: []string{"price", "sale_price"}, Definition: "CHECK ((sale_price < price))"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "**Constraints:**") {
+		t.Error("expected Constraints block not found")
+	}
+	if !strings.Contains(result, "- `products_price_check` (on `price`): `CHECK ((price > (0)::numeric))`") {
+		t.Error("expected column-level check not found")
+	}
+	if !strings.Contains(result, "- `sale_below_price`: `CHECK ((sale_price < price))`") {
+		t.Error("expected table-level check not found")
+	}
+}
+
+func TestRender_ForeignKeys(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema: "public",
+					Name:   "order_lines",
+					Columns: []pg.Column{
+						{Name: "order_id", Type: "integer", FKRef: "public.orders.id"},
+						{Name: "tenant_id", Type: "integer", FKRef: "public.orders.tenant_id"},
+					},
+					ForeignKeys: []pg.ForeignKey{
+						{
+							Name:       "order_lines_order_fkey",
+							Columns:    []string{"tenant_id", "order_id"},
+							RefSchema:  "public",
+							RefTable:   "orders",
+							RefColumns: []string{"tenant_id", "id"},
+							OnDelete:   "CASCADE",
+							OnUpdate:   "NO ACTION",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	if !strings.Contains(result, "**Foreign keys:**\n\n- `order_lines_order_fkey`: (tenant_id, order_id) → orders (tenant_id, id) ON DELETE CASCADE\n") {
+		t.Errorf("expected foreign key list not found:\n%s", result)
+	}
+	if strings.Contains(result, "ON UPDATE") {
+		t.Error("default NO ACTION should not be rendered")
+	}
+}
+
+func TestRender_CrossSchemaForeignKeys(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:  "public",
+					Name:    "profiles",
+					Columns: []pg.Column{{Name: "user_id", Type: "uuid", FKRef: "auth.users.id"}, {Name: "org_id", Type: "uuid", FKRef: "billing.orgs.id"}},
+					ForeignKeys: []pg.ForeignKey{
+						{Name: "profiles_user_id_fkey", Columns: []string{"user_id"}, RefSchema: "auth", RefTable: "users", RefColumns: []string{"id"}},
+						{Name: "profiles_org_id_fkey", Columns: []string{"org_id"}, RefSchema: "billing", RefTable: "orgs", RefColumns: []string{"id"}},
+					},
+				},
+			},
+		},
+		{Name: "billing", Tables: []pg.Table{{Schema: "billing", Name: "orgs"}}},
+	}
+
+	result := Render(schemas, Options{})
+
+	tests := []string{
+		"- `profiles_user_id_fkey`: (user_id) → auth.users _(external)_ (id)\n",
+		"- `profiles_org_id_fkey`: (org_id) → billing.orgs (id)\n",
+	}
+	for _, want := range tests {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "External References") {
+		t.Error("unexpected appendix without ExternalReferences")
+	}
+
+	result = Render(schemas, Options{ExternalReferences: true, Anchors: true})
+	if !strings.Contains(result, "## Appendix: External References\n\n| Target | Referenced By | Constraint |\n|--------|---------------|------------|\n| auth.users (id) | [public.profiles](#table-public-profiles) (user_id) | `profiles_user_id_fkey` |\n") {
+		t.Errorf("expected external references appendix:\n%s", result)
+	}
+
+	// A fragment does not know what else is documented.
+	if fragment := RenderTable(schemas[0].Tables[0], Options{}); strings.Contains(fragment, "_(external)_") {
+		t.Errorf("fragment marked a reference external:\n%s", fragment)
+	}
+}
+
+func TestRender_PartitionedTable(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:            "public",
+					Name:              "events",
+					Columns:           []pg.Column{{Name: "created_at", Type: "timestamp with time zone"}},
+					PartitionStrategy: "range",
+					PartitionKey:      "created_at",
+					Partitions: []pg.Partition{
+						{Schema: "public", Name: "events_2024", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+							PartitionStrategy: "list", PartitionKey: "region",
+							Partitions: []pg.Partition{{Schema: "public", Name: "events_2024_eu", Bound: "FOR VALUES IN ('eu')"}}},
+						{Schema: "archive", Name: "events_old", Bound: "DEFAULT"},
+					},
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"strategy", "#### events\n\n**Partitioned by:** range (`created_at`)\n"},
+		{"partitions", "**Partitions (2):**\n\n- `events_2024`: FROM ('2024-01-01') TO ('2025-01-01'), partitioned by list (`region`)\n  - `events_2024_eu`: IN ('eu')\n- `archive.events_old`: DEFAULT\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRender_SoftDelete(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{Schema: "public", Name: "users", Columns: []pg.Column{{Name: "deleted_at", Type: "timestamp with time zone"}}},
+				{Schema: "public", Name: "tags", Columns: []pg.Column{{Name: "id", Type: "integer"}}},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{SoftDelete: "deleted_at IS NULL"})
+
+	if !strings.Contains(result, "#### users\n\n**Soft delete:** live rows satisfy `deleted_at IS NULL`.\n\n") {
+		t.Error("expected soft delete annotation on users")
+	}
+	if strings.Count(result, "**Soft delete:**") != 1 {
+		t.Error("expected only tables with the column annotated")
+	}
+}
+
+func TestRender_ContentHash(t *testing.T) {
+	schemas := []pg.SchemaInfo{{Name: "public"}}
+
+	result := Render(schemas, Options{ContentHash: "sha256:abc123"})
+	if !strings.HasSuffix(result, "\n---\n\n_Schema hash: `sha256:abc123`_\n") {
+		t.Errorf("expected hash footer not found:\n%s", result)
+	}
+
+	if strings.Contains(Render(schemas, Options{}), "Schema hash") {
+		t.Error("hash footer should only appear when a hash is given")
+	}
+}
+
+func TestRender_Warnings(t *testing.T) {
+	db := pg.Database{
+		Schemas:  []pg.SchemaInfo{{Name: "public"}},
+		Warnings: []string{"fetching functions for schema public: permission denied"},
+	}
+
+	result := RenderDatabase(db, Options{})
+	expected := "# Database Schema Documentation\n\n> **Incomplete:** parts of the schema could not be read, so this document may be missing objects.\n>\n> - fetching functions for schema public: permission denied\n\n## Schema: public"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected warnings block not found:\n%s", result)
+	}
+}
+
+func TestRender_TriggerFunctionLinks(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name:   "public",
+			Tables: []pg.Table{{Schema: "public", Name: "users"}},
+			Triggers: []pg.Trigger{
+				{Schema: "public", Table: "users", Name: "touch", Event: "UPDATE", Timing: "BEFORE", Function: "set_updated_at"},
+				{Schema: "public", Table: "users", Name: "audit", Event: "INSERT", Timing: "AFTER", Function: "log_change", FunctionSchema: "audit"},
+				{Schema: "public", Table: "users", Name: "ext", Event: "DELETE", Timing: "AFTER", Function: "undocumented"},
+				{Schema: "public", Table: "users", Name: "batch", Event: "UPDATE", Timing: "AFTER", Function: "undocumented", OldTable: "before", NewTable: "after"},
+				{Schema: "public", Table: "users", Name: "email_changed", Event: "INSERT OR UPDATE", Timing: "AFTER", Function: "notify", Level: "ROW", Columns: []string{"email", "phone"}, When: "old.email IS DISTINCT FROM new.email"},
+			},
+			Functions: []pg.Function{{Schema: "public", Name: "set_updated_at", ReturnType: "trigger"}},
+		},
+		{
+			Name:      "audit",
+			Functions: []pg.Function{{Schema: "audit", Name: "log_change", ReturnType: "trigger"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"same schema", Options{Anchors: true}, "BEFORE UPDATE → [set_updated_at()](#function-public-set_updated_at)\n"},
+		{"other schema", Options{Anchors: true}, "AFTER INSERT → [audit.log_change()](#function-audit-log_change)\n"},
+		{"undocumented function", Options{Anchors: true}, "AFTER DELETE → undocumented()\n"},
+		{"without anchors", Options{}, "BEFORE UPDATE → set_updated_at()\n"},
+		{"row trigger", Options{}, "AFTER INSERT OR UPDATE OF email, phone FOR EACH ROW WHEN `old.email IS DISTINCT FROM new.email` → notify()\n"},
+		{"transition tables", Options{}, "AFTER UPDATE → undocumented() `REFERENCING OLD TABLE AS before NEW TABLE AS after`\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Render(schemas, tt.opts)
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+
+	files := RenderFiles(pg.Database{Schemas: schemas}, Options{Anchors: true}, false)
+	if !strings.Contains(files["public.md"], "[audit.log_change()](audit.md#function-audit-log_change)") {
+		t.Errorf("expected cross-page link:\n%s", files["public.md"])
+	}
+}
+
+func TestRender_NestedCompositeTypes(t *testing.T) {
+	composite := func(name string, attrs ...pg.Column) pg.CustomType {
+		return pg.CustomType{Schema: "public", Name: name, Kind: "composite", Attributes: attrs}
+	}
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Types: []pg.CustomType{
+			composite("coordinates", pg.Column{Name: "lat", Type: "double precision"}, pg.Column{Name: "lng", Type: "double precision"}),
+			composite("address", pg.Column{Name: "street", Type: "text"}, pg.Column{Name: "location", Type: "coordinates"}),
+			composite("customer",
+				pg.Column{Name: "home", Type: "public.address", Description: "Where they live"},
+				pg.Column{Name: "previous", Type: "address[]"},
+			),
+		},
+	}}
+
+	result := Render(schemas, Options{})
+
+	expected := "| home | public.address | Where they live |\n" +
+		"| ↳ home.street | text |  |\n" +
+		"| ↳ home.location | coordinates |  |\n" +
+		"| ↳ home.location.lat | double precision |  |\n" +
+		"| ↳ home.location.lng | double precision |  |\n" +
+		"| previous | address\\[\\] |  |\n" +
+		"| ↳ previous\\[\\].street | text |  |\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("nested attributes not expanded:\n%s", result)
+	}
+	if !strings.Contains(result, "#### address (composite)\n\n| Column | Type |\n|--------|------|\n| street | text |\n| location | coordinates |\n| ↳ location.lat | double precision |\n") {
+		t.Errorf("expected address to expand coordinates:\n%s", result)
+	}
+	if strings.Contains(RenderTable(pg.Table{Schema: "public", Name: "t", Columns: []pg.Column{{Name: "home", Type: "address"}}}, Options{}), "↳") {
+		t.Error("table columns should not be expanded")
+	}
+}
+
+func TestRender_ColumnTypeLinks(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Tables: []pg.Table{{
+			Schema: "public",
+			Name:   "orders",
+			Columns: []pg.Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "items", Type: "line_item[]"},
+				{Name: "flags", Type: "public.flag[]"},
+			},
+		}},
+		Types: []pg.CustomType{
+			{Schema: "public", Name: "flag", Kind: "enum", Values: []string{"rush", "gift"}},
+			{Schema: "public", Name: "line_item", Kind: "composite", Attributes: []pg.Column{
+				{Name: "sku", Type: "text"},
+				{Name: "flag", Type: "flag"},
+			}},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+		absent   []string
+	}{
+		{
+			name: "links",
+			opts: Options{Anchors: true},
+			expected: []string{
+				"| id | bigint | NOT NULL |\n",
+				"| items | [line_item\\[\\]](#type-public-line_item) | NOT NULL |\n",
+				"| flags | [public.flag\\[\\]](#type-public-flag) | NOT NULL |\n",
+			},
+			absent: []string{"↳ items"},
+		},
+		{
+			name: "expanded",
+			opts: Options{ExpandTypes: true},
+			expected: []string{
+				"| items | line_item\\[\\] | NOT NULL |\n| ↳ items\\[\\].sku | text |  |\n| ↳ items\\[\\].flag | flag |  |\n| flags |",
+			},
+		},
+		{
+			name:     "no anchors",
+			opts:     Options{},
+			expected: []string{"| items | line_item\\[\\] | NOT NULL |\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Render(schemas, tt.opts)
+			for _, e := range tt.expected {
+				if !strings.Contains(result, e) {
+					t.Errorf("expected %q in:\n%s", e, result)
+				}
+			}
+			for _, a := range tt.absent {
+				if strings.Contains(result, a) {
+					t.Errorf("unexpected %q in:\n%s", a, result)
+				}
+			}
+		})
+	}
+}
+
+// TestRender_FetchedColumnTypeLinks uses columns shaped like a live fetch,
+// where information_schema reports user types as USER-DEFINED or ARRAY
+// and names them, without a schema, only in udt_name.
+func TestRender_FetchedColumnTypeLinks(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{{
+				Schema: "public",
+				Name:   "orders",
+				Columns: []pg.Column{
+					{Name: "items", Type: "ARRAY", UDTName: "_line_item"},
+					{Name: "flag", Type: "USER-DEFINED", UDTName: "flag"},
+					{Name: "currency", Type: "USER-DEFINED", UDTName: "currency"},
+					{Name: "tags", Type: "ARRAY", UDTName: "_text"},
+				},
+			}},
+			Types: []pg.CustomType{
+				{Schema: "public", Name: "flag", Kind: "enum", Values: []string{"rush", "gift"}},
+				{Schema: "public", Name: "line_item", Kind: "composite", Attributes: []pg.Column{
+					{Name: "sku", Type: "text"},
+				}},
+			},
+		},
+		{
+			Name:  "billing",
+			Types: []pg.CustomType{{Schema: "billing", Name: "currency", Kind: "enum", Values: []string{"EUR"}}},
+		},
+	}
+
+	result := Render(schemas, Options{Anchors: true, ExpandTypes: true})
+
+	for _, e := range []string{
+		"| items | [line_item\\[\\]](#type-public-line_item) | NOT NULL |\n| ↳ items\\[\\].sku | text |  |\n",
+		"| flag | [flag](#type-public-flag) | NOT NULL |\n",
+		"| currency | [currency](#type-billing-currency) | NOT NULL |\n",
+		"| tags | text\\[\\] | NOT NULL |\n",
+	} {
+		if !strings.Contains(result, e) {
+			t.Errorf("expected %q in:\n%s", e, result)
+		}
+	}
+	if strings.Contains(result, "USER-DEFINED") || strings.Contains(result, "| ARRAY |") {
+		t.Errorf("expected declared types rather than data_type:\n%s", result)
+	}
+}
+
+func TestRender_FunctionCalls(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		Functions: []pg.Function{
+			{Schema: "public", Name: "now_utc", ReturnType: "timestamp"},
+			{Schema: "public", Name: "touch", ReturnType: "trigger", Definition: "CREATE FUNCTION public.touch() RETURNS trigger AS $$ BEGIN NEW.updated_at := now_utc(); RETURN NEW; END $$"},
+		},
+	}}
+
+	result := Render(schemas, Options{Anchors: true, FunctionCalls: true})
+	for _, expected := range []string{
+		"`now_utc() → timestamp`\n  Called by: [`touch()`](#function-public-touch)\n",
+		"  Calls: [`now_utc()`](#function-public-now_utc)\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %q in:\n%s", expected, result)
+		}
+	}
+
+	if result := Render(schemas, Options{}); strings.Contains(result, "Calls:") {
+		t.Errorf("calls listed without FunctionCalls:\n%s", result)
+	}
+}
+
+func TestRender_MatviewRefreshes(t *testing.T) {
+	schemas := []pg.SchemaInfo{{
+		Name: "public",
+		MaterializedViews: []pg.MaterializedView{{
+			Schema: "public",
+			Name:   "totals",
+			Refreshes: []pg.Refresh{
+				{Kind: "cron", Name: "nightly", Schedule: "0 3 * * *", Concurrently: true},
+				{Kind: "trigger", Name: "public.orders.orders_refresh", Function: "public.refresh_totals"},
+			},
+		}},
+		Functions: []pg.Function{{Schema: "public", Name: "refresh_totals", ReturnType: "trigger"}},
+	}}
+
+	result := Render(schemas, Options{Anchors: true})
+	for _, expected := range []string{
+		"**Refreshed by:**\n\n",
+		"- pg_cron job `nightly` on `0 3 * * *`, concurrently\n",
+		"- trigger [`orders.orders_refresh`](#trigger-public-orders-orders_refresh-7bd513ac), via [`refresh_totals()`](#function-public-refresh_totals)\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %q in:\n%s", expected, result)
+		}
+	}
+}
+
+func TestRender_Policies(t *testing.T) {
+	schemas := []pg.SchemaInfo{
+		{
+			Name: "public",
+			Tables: []pg.Table{
+				{
+					Schema:      "public",
+					Name:        "accounts",
+					Columns:     []pg.Column{{Name: "id", Type: "integer"}},
+					RowSecurity: true,
+					Policies: []pg.Policy{
+						{Name: "own_rows", Command: "ALL", Roles: []string{"public"}, Using: "(owner = CURRENT_USER)"},
+						{Name: "no_closed", Command: "UPDATE", Restrictive: true, Roles: []string{"app", "auditor"}, WithCheck: "(NOT closed)"},
+					},
+				},
+				{
+					Schema:   "public",
+					Name:     "drafts",
+					Columns:  []pg.Column{{Name: "id", Type: "integer"}},
+					Policies: []pg.Policy{{Name: "mine", Command: "SELECT", Roles: []string{"public"}, Using: "true"}},
+				},
+				{
+					Schema:           "public",
+					Name:             "secrets",
+					Columns:          []pg.Column{{Name: "id", Type: "integer"}},
+					RowSecurity:      true,
+					ForceRowSecurity: true,
+				},
+			},
+		},
+	}
+
+	result := Render(schemas, Options{})
+
+	expected := "\n**Row-level security:** enabled\n\n" +
+		"- `own_rows`: FOR ALL TO `public` USING `(owner = CURRENT_USER)`\n" +
+		"- `no_closed` (restrictive): FOR UPDATE TO `app`, `auditor` WITH CHECK `(NOT closed)`\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected policies not found:\n%s", result)
+	}
+	if !strings.Contains(result, "**Row-level security:** disabled, so these policies are not enforced\n\n- `mine`: FOR SELECT TO `public` USING `true`\n") {
+		t.Errorf("expected policies on a table without row-level security to be marked:\n%s", result)
+	}
+	if !strings.Contains(result, "**Row-level security:** enabled, also for the table owner\n") {
+		t.Errorf("expected forced row-level security not found:\n%s", result)
+	}
+}
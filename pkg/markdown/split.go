@@ -0,0 +1,134 @@
+package markdown
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	anchorPattern    = regexp.MustCompile(`<a id="([^"]+)"></a>`)
+	localLinkPattern = regexp.MustCompile(`\]\(#([^)\s]+)\)`)
+)
+
+// SplitDocument cuts a single-file document that is larger than maxBytes
+// into continuation files, for hosts that stop rendering files past a
+// size, such as GitHub at 512 KB. Files are cut only before headings, so
+// a part can exceed maxBytes when one object does. The first part keeps
+// name; later parts are name-2.md, name-3.md and so on, and every part
+// links to its neighbours. Links to anchors that land in another part are
+// rewritten to point into it.
+//
+// A document within maxBytes is returned as the single file name.
+func SplitDocument(doc, name string, maxBytes int) map[string]string {
+	if maxBytes <= 0 || len(doc) <= maxBytes {
+		return map[string]string{name: doc}
+	}
+
+	names := func(i int) string {
+		if i == 0 {
+			return name
+		}
+		ext := path.Ext(name)
+		return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i+1, ext)
+	}
+
+	// Leave room for the navigation lines and for the file names that
+	// rewritten links gain.
+	budget := maxBytes - 2*len(navLine(names(99), names(99), 99, 99))
+	linkCost := len(names(99))
+
+	var parts []string
+	var current strings.Builder
+	for _, block := range splitBlocks(doc) {
+		size := len(block) + linkCost*len(localLinkPattern.FindAllStringIndex(block, -1))
+		if current.Len() > 0 && current.Len()+size > budget {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		current.WriteString(block)
+	}
+	parts = append(parts, current.String())
+
+	part := make(map[string]int)
+	for i, p := range parts {
+		for _, m := range anchorPattern.FindAllStringSubmatch(p, -1) {
+			part[m[1]] = i
+		}
+	}
+
+	files := make(map[string]string, len(parts))
+	for i, p := range parts {
+		p = localLinkPattern.ReplaceAllStringFunc(p, func(link string) string {
+			id := localLinkPattern.FindStringSubmatch(link)[1]
+			if target, ok := part[id]; ok && target != i {
+				return "](" + names(target) + "#" + id + ")"
+			}
+			return link
+		})
+
+		var prev, next string
+		if i > 0 {
+			prev = names(i - 1)
+		}
+		if i < len(parts)-1 {
+			next = names(i + 1)
+		}
+		nav := navLine(prev, next, i+1, len(parts))
+
+		var sb strings.Builder
+		if i > 0 {
+			sb.WriteString(nav + "\n\n")
+		}
+		sb.WriteString(strings.TrimRight(p, "\n"))
+		sb.WriteString("\n\n" + nav + "\n")
+		files[names(i)] = sb.String()
+	}
+	return files
+}
+
+func navLine(prev, next string, n, total int) string {
+	var parts []string
+	if prev != "" {
+		parts = append(parts, fmt.Sprintf("[← Previous](%s)", prev))
+	}
+	parts = append(parts, fmt.Sprintf("_Part %d of %d_", n, total))
+	if next != "" {
+		parts = append(parts, fmt.Sprintf("[Next →](%s)", next))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// splitBlocks cuts doc before each heading of level two to four, keeping
+// the anchors that precede a heading with it. Lines in fenced code blocks
+// are never headings.
+func splitBlocks(doc string) []string {
+	var blocks []string
+	var block, anchors strings.Builder
+	fence := ""
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case fence != "":
+			if strings.TrimSpace(trimmed) == fence {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"):
+			fence = strings.TrimRight(trimmed, "abcdefghijklmnopqrstuvwxyz")
+		case anchorPattern.MatchString(trimmed) && strings.HasPrefix(trimmed, "<a id="):
+			anchors.WriteString(line)
+			continue
+		case strings.HasPrefix(trimmed, "## "), strings.HasPrefix(trimmed, "### "), strings.HasPrefix(trimmed, "#### "):
+			if block.Len() > 0 {
+				blocks = append(blocks, block.String())
+				block.Reset()
+			}
+		}
+		block.WriteString(anchors.String())
+		anchors.Reset()
+		block.WriteString(line)
+	}
+	block.WriteString(anchors.String())
+	return append(blocks, block.String())
+}
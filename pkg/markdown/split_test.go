@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestSplitDocument(t *testing.T) {
+	var tables []pg.Table
+	for _, name := range []string{"accounts", "invoices", "payments", "refunds"} {
+		var cols []pg.Column
+		for _, c := range []string{"id", "created_at", "updated_at", "amount", "currency", "status"} {
+			cols = append(cols, pg.Column{Name: c, Type: "text", Description: strings.Repeat("Lorem ipsum dolor sit amet. ", 4)})
+		}
+		if name != "accounts" {
+			cols = append(cols, pg.Column{Name: "account_id", Type: "uuid", FKRef: "public.accounts.id"})
+		}
+		tables = append(tables, pg.Table{Schema: "public", Name: name, Columns: cols})
+	}
+	schemas := []pg.SchemaInfo{{
+		Name:   "public",
+		Tables: tables,
+		Views: []pg.View{{
+			Schema:     "public",
+			Name:       "totals",
+			Definition: "SELECT 1\n## not a heading\n",
+		}},
+	}}
+	doc := Render(schemas, Options{Anchors: true})
+
+	if files := SplitDocument(doc, "schema.md", len(doc)); len(files) != 1 || files["schema.md"] != doc {
+		t.Errorf("a document within the limit was changed: %v", files)
+	}
+
+	files := SplitDocument(doc, "schema.md", 2048)
+	if len(files) < 3 {
+		t.Fatalf("got %d files, want at least 3", len(files))
+	}
+	for name, content := range files {
+		if len(content) > 2048 {
+			t.Errorf("%s is %d bytes", name, len(content))
+		}
+	}
+
+	first, second := files["schema.md"], files["schema-2.md"]
+	if !strings.HasPrefix(first, "# Database Schema Documentation\n") {
+		t.Errorf("first part lost the title:\n%s", first)
+	}
+	if !strings.HasSuffix(first, fmt.Sprintf("\n\n_Part 1 of %d_ · [Next →](schema-2.md)\n", len(files))) {
+		t.Errorf("first part has no navigation:\n%s", first)
+	}
+	if !strings.HasPrefix(second, fmt.Sprintf("[← Previous](schema.md) · _Part 2 of %d_ · [Next →](schema-3.md)\n\n", len(files))) {
+		t.Errorf("second part has no navigation:\n%s", second)
+	}
+
+	// Each table starts a part or follows another whole, with its anchor.
+	var joined strings.Builder
+	for i := range len(files) {
+		name := "schema.md"
+		if i > 0 {
+			name = fmt.Sprintf("schema-%d.md", i+1)
+		}
+		joined.WriteString(files[name])
+	}
+	for _, table := range tables {
+		if !strings.Contains(joined.String(), "<a id=\"table-public-"+table.Name+"\"></a>\n#### "+table.Name+"\n") {
+			t.Errorf("table %s was cut from its anchor", table.Name)
+		}
+	}
+	if !strings.Contains(joined.String(), "SELECT 1\n## not a heading\n") {
+		t.Error("a code block was split")
+	}
+
+	// The table of contents in the first part links into later parts.
+	if !strings.Contains(first, "](schema-") || strings.Contains(first, "](#table-public-refunds)") {
+		t.Errorf("links into later parts were not rewritten:\n%s", first)
+	}
+}
@@ -0,0 +1,51 @@
+package pg_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sotirismorf/pgmd/internal/synthetic"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// BenchmarkFetchSchemas introspects generated schemas in the scratch
+// database named by PGMD_BENCH_URI, which it creates and drops.
+func BenchmarkFetchSchemas(b *testing.B) {
+	uri := os.Getenv("PGMD_BENCH_URI")
+	if uri == "" {
+		b.Skip("PGMD_BENCH_URI not set")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, uri)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	for _, tables := range []int{100, 1000} {
+		schema := fmt.Sprintf("pgmd_bench_%d", tables)
+		if _, err := conn.Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE"); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := conn.Exec(ctx, synthetic.DDL(schema, tables)); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := pg.FetchSchemas(ctx, conn, []string{schema}, pg.FetchOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		if _, err := conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
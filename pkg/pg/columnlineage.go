@@ -0,0 +1,394 @@
+//go:build cgo
+
+package pg
+
+import (
+	"slices"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v6"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// columnLineage reports whether deriveColumnLineage traces lineage; it
+// needs the PostgreSQL parser, which builds only with cgo.
+const columnLineage = true
+
+// deriveColumnLineage parses the definition of every view and materialized
+// view in info and sets each column's DerivedFrom to the table columns its
+// value is computed from. References to other views and materialized views
+// of the schema are followed to their own sources, so the result names
+// base tables wherever the chain can be traced. Definitions that do not
+// parse leave DerivedFrom empty.
+func deriveColumnLineage(info *SchemaInfo) {
+	l := &lineageResolver{
+		info:    info,
+		columns: make(map[string][]string),
+		derived: make(map[string]map[string][]string),
+	}
+	for _, t := range info.Tables {
+		l.columns[info.Name+"."+t.Name] = columnNames(t.Columns)
+	}
+	for _, v := range info.Views {
+		l.columns[info.Name+"."+v.Name] = columnNames(v.Columns)
+	}
+	for _, mv := range info.MaterializedViews {
+		l.columns[info.Name+"."+mv.Name] = columnNames(mv.Columns)
+	}
+
+	for i := range info.Views {
+		v := &info.Views[i]
+		setDerivedFrom(v.Columns, l.relation(info.Name+"."+v.Name))
+	}
+	for i := range info.MaterializedViews {
+		mv := &info.MaterializedViews[i]
+		setDerivedFrom(mv.Columns, l.relation(info.Name+"."+mv.Name))
+	}
+}
+
+func setDerivedFrom(columns []Column, sources map[string][]string) {
+	for i := range columns {
+		columns[i].DerivedFrom = sources[columns[i].Name]
+	}
+}
+
+func columnNames(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// lineageResolver computes column lineage for the views of one schema,
+// remembering each view's result so chains of views are parsed once.
+type lineageResolver struct {
+	info *SchemaInfo
+	// columns lists the column names of the schema's relations, keyed by
+	// "schema.name", for resolving unqualified column references.
+	columns map[string][]string
+	// derived maps "schema.view" to the sources of each of its columns; a
+	// nil entry marks a view being resolved.
+	derived map[string]map[string][]string
+}
+
+// relation returns the sources of each column of the view or materialized
+// view named "schema.name", or nil if it is not one of the schema's.
+func (l *lineageResolver) relation(qualified string) map[string][]string {
+	if sources, ok := l.derived[qualified]; ok {
+		return sources
+	}
+	var definition string
+	var columns []Column
+	var dependsOn []string
+	for _, v := range l.info.Views {
+		if l.info.Name+"."+v.Name == qualified {
+			definition, columns, dependsOn = v.Definition, v.Columns, v.DependsOn
+		}
+	}
+	for _, mv := range l.info.MaterializedViews {
+		if l.info.Name+"."+mv.Name == qualified {
+			definition, columns, dependsOn = mv.Definition, mv.Columns, mv.DependsOn
+		}
+	}
+	if definition == "" {
+		return nil
+	}
+
+	l.derived[qualified] = nil
+	tree, err := pg_query.Parse(definition)
+	if err != nil || len(tree.Stmts) == 0 || tree.Stmts[0].Stmt.GetSelectStmt() == nil {
+		return nil
+	}
+	q := lineageQuery{resolver: l, dependsOn: dependsOn}
+	targets := q.selectSources(tree.Stmts[0].Stmt.GetSelectStmt(), nil)
+
+	sources := make(map[string][]string)
+	for i, col := range columns {
+		if i < len(targets) {
+			sources[col.Name] = targets[i]
+		}
+	}
+	l.derived[qualified] = sources
+	return sources
+}
+
+// baseColumn returns what column col of relation "schema.name" derives
+// from: the column itself for a table, or the view column's own sources.
+func (l *lineageResolver) baseColumn(relation, col string) []string {
+	if sources := l.relation(relation); sources != nil {
+		return sources[col]
+	}
+	if _, ok := l.derived[relation]; ok {
+		// A view whose definition could not be traced.
+		return nil
+	}
+	return []string{relation + "." + col}
+}
+
+// lineageQuery resolves the relation names in one view definition.
+type lineageQuery struct {
+	resolver  *lineageResolver
+	dependsOn []string
+}
+
+// lineageRel is a relation in a query's FROM clause: a table or view
+// reached by name, or a subquery or CTE whose columns have known sources.
+type lineageRel struct {
+	alias    string
+	relation string
+	derived  map[string][]string
+	// opaque relations, such as set-returning functions, have columns
+	// with no traceable source.
+	opaque bool
+}
+
+type lineageScope struct {
+	rels   []lineageRel
+	ctes   map[string]map[string][]string
+	parent *lineageScope
+}
+
+func (sc *lineageScope) cte(name string) (map[string][]string, bool) {
+	for ; sc != nil; sc = sc.parent {
+		if sources, ok := sc.ctes[name]; ok {
+			return sources, true
+		}
+	}
+	return nil, false
+}
+
+// selectSources returns the sources of each output column of stmt.
+func (q lineageQuery) selectSources(stmt *pg_query.SelectStmt, parent *lineageScope) [][]string {
+	sc := &lineageScope{parent: parent}
+	if with := stmt.GetWithClause(); with != nil {
+		sc.ctes = make(map[string]map[string][]string)
+		for _, node := range with.GetCtes() {
+			cte := node.GetCommonTableExpr()
+			sub := cte.GetCtequery().GetSelectStmt()
+			if sub == nil {
+				continue
+			}
+			sc.ctes[cte.GetCtename()] = namedSources(sub, cte.GetAliascolnames(), q.selectSources(sub, sc))
+		}
+	}
+
+	if stmt.GetOp() != pg_query.SetOperation_SETOP_NONE {
+		left := q.selectSources(stmt.GetLarg(), sc)
+		right := q.selectSources(stmt.GetRarg(), sc)
+		for i := range left {
+			if i < len(right) {
+				left[i] = mergeSources(left[i], right[i])
+			}
+		}
+		return left
+	}
+
+	for _, node := range stmt.GetFromClause() {
+		q.addFromItem(sc, node)
+	}
+
+	var targets [][]string
+	for _, node := range stmt.GetTargetList() {
+		var sources []string
+		q.exprSources(node.GetResTarget().GetVal().ProtoReflect(), sc, func(s []string) {
+			sources = mergeSources(sources, s)
+		})
+		targets = append(targets, sources)
+	}
+	return targets
+}
+
+// namedSources keys a subquery's target sources by the names its columns
+// are known by outside it.
+func namedSources(stmt *pg_query.SelectStmt, aliases []*pg_query.Node, targets [][]string) map[string][]string {
+	for stmt.GetOp() != pg_query.SetOperation_SETOP_NONE {
+		stmt = stmt.GetLarg()
+	}
+	sources := make(map[string][]string)
+	for i, node := range stmt.GetTargetList() {
+		if i >= len(targets) {
+			break
+		}
+		name := ""
+		switch {
+		case i < len(aliases):
+			name = aliases[i].GetString_().GetSval()
+		case node.GetResTarget().GetName() != "":
+			name = node.GetResTarget().GetName()
+		default:
+			if fields := node.GetResTarget().GetVal().GetColumnRef().GetFields(); len(fields) > 0 {
+				name = fields[len(fields)-1].GetString_().GetSval()
+			}
+		}
+		if name != "" {
+			sources[name] = targets[i]
+		}
+	}
+	return sources
+}
+
+func (q lineageQuery) addFromItem(sc *lineageScope, node *pg_query.Node) {
+	switch {
+	case node.GetRangeVar() != nil:
+		rv := node.GetRangeVar()
+		alias := rv.GetRelname()
+		if rv.GetAlias() != nil {
+			alias = rv.GetAlias().GetAliasname()
+		}
+		if rv.GetSchemaname() == "" {
+			if sources, ok := sc.cte(rv.GetRelname()); ok {
+				sc.rels = append(sc.rels, lineageRel{alias: alias, derived: sources})
+				return
+			}
+		}
+		sc.rels = append(sc.rels, lineageRel{alias: alias, relation: q.qualify(rv.GetSchemaname(), rv.GetRelname())})
+
+	case node.GetJoinExpr() != nil:
+		q.addFromItem(sc, node.GetJoinExpr().GetLarg())
+		q.addFromItem(sc, node.GetJoinExpr().GetRarg())
+
+	case node.GetRangeSubselect() != nil:
+		rs := node.GetRangeSubselect()
+		sub := rs.GetSubquery().GetSelectStmt()
+		if sub == nil || rs.GetAlias() == nil {
+			return
+		}
+		targets := q.selectSources(sub, sc)
+		sc.rels = append(sc.rels, lineageRel{
+			alias:   rs.GetAlias().GetAliasname(),
+			derived: namedSources(sub, rs.GetAlias().GetColnames(), targets),
+		})
+
+	case node.GetRangeFunction() != nil:
+		rf := node.GetRangeFunction()
+		if rf.GetAlias() != nil {
+			sc.rels = append(sc.rels, lineageRel{alias: rf.GetAlias().GetAliasname(), opaque: true})
+		}
+	}
+}
+
+// qualify names a relation "schema.name". pg_get_viewdef leaves out the
+// schema of relations on the search path, so an unqualified name is
+// matched against the view's dependencies, then assumed to be in the
+// view's own schema.
+func (q lineageQuery) qualify(schema, name string) string {
+	if schema != "" {
+		return schema + "." + name
+	}
+	own := q.resolver.info.Name + "." + name
+	if slices.Contains(q.dependsOn, own) {
+		return own
+	}
+	for _, dep := range q.dependsOn {
+		if strings.HasSuffix(dep, "."+name) {
+			return dep
+		}
+	}
+	return own
+}
+
+// exprSources reports the sources of every column an expression reads,
+// including the outputs of scalar subqueries within it.
+func (q lineageQuery) exprSources(m protoreflect.Message, sc *lineageScope, add func([]string)) {
+	switch n := m.Interface().(type) {
+	case *pg_query.ColumnRef:
+		add(q.columnRef(n, sc))
+		return
+	case *pg_query.SelectStmt:
+		for _, s := range q.selectSources(n, sc) {
+			add(s)
+		}
+		return
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil || fd.IsMap():
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				q.exprSources(v.List().Get(i).Message(), sc, add)
+			}
+		default:
+			q.exprSources(v.Message(), sc, add)
+		}
+		return true
+	})
+}
+
+// columnRef resolves "col", "rel.col" or "schema.rel.col" against the
+// FROM clauses in scope, innermost first. An unqualified column that more
+// than one relation has, as after JOIN ... USING, derives from all of them.
+func (q lineageQuery) columnRef(ref *pg_query.ColumnRef, sc *lineageScope) []string {
+	var fields []string
+	for _, f := range ref.GetFields() {
+		if f.GetString_() == nil {
+			return nil
+		}
+		fields = append(fields, f.GetString_().GetSval())
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	col := fields[len(fields)-1]
+
+	for ; sc != nil; sc = sc.parent {
+		var matches []lineageRel
+		for _, rel := range sc.rels {
+			switch len(fields) {
+			case 1:
+				if q.hasColumn(rel, col) || (len(sc.rels) == 1 && !q.knowsColumns(rel)) {
+					matches = append(matches, rel)
+				}
+			case 2:
+				if rel.alias == fields[0] {
+					matches = append(matches, rel)
+				}
+			default:
+				if rel.relation == fields[len(fields)-3]+"."+fields[len(fields)-2] {
+					matches = append(matches, rel)
+				}
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		var sources []string
+		for _, rel := range matches {
+			switch {
+			case rel.opaque:
+			case rel.derived != nil:
+				sources = mergeSources(sources, rel.derived[col])
+			default:
+				sources = mergeSources(sources, q.resolver.baseColumn(rel.relation, col))
+			}
+		}
+		return sources
+	}
+	return nil
+}
+
+// knowsColumns reports whether rel's column names are known, which they
+// are not for relations outside the schema.
+func (q lineageQuery) knowsColumns(rel lineageRel) bool {
+	_, ok := q.resolver.columns[rel.relation]
+	return rel.derived != nil || ok
+}
+
+func (q lineageQuery) hasColumn(rel lineageRel, col string) bool {
+	if rel.derived != nil {
+		_, ok := rel.derived[col]
+		return ok
+	}
+	return slices.Contains(q.resolver.columns[rel.relation], col)
+}
+
+// mergeSources returns the sorted union of a and b.
+func mergeSources(a, b []string) []string {
+	for _, s := range b {
+		if !slices.Contains(a, s) {
+			a = append(a, s)
+		}
+	}
+	slices.Sort(a)
+	return a
+}
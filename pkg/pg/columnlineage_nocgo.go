@@ -0,0 +1,10 @@
+//go:build !cgo
+
+package pg
+
+// columnLineage reports whether deriveColumnLineage traces lineage; it
+// needs the PostgreSQL parser, which builds only with cgo.
+const columnLineage = false
+
+// deriveColumnLineage leaves DerivedFrom empty without cgo.
+func deriveColumnLineage(info *SchemaInfo) {}
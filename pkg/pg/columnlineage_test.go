@@ -0,0 +1,76 @@
+//go:build cgo
+
+package pg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeriveColumnLineage(t *testing.T) {
+	columns := func(names ...string) []Column {
+		var cols []Column
+		for _, n := range names {
+			cols = append(cols, Column{Name: n})
+		}
+		return cols
+	}
+	info := SchemaInfo{
+		Name: "public",
+		Tables: []Table{
+			{Schema: "public", Name: "users", Columns: columns("id", "email", "first", "last")},
+			{Schema: "public", Name: "orders", Columns: columns("id", "user_id", "total")},
+		},
+		Views: []View{
+			{
+				Schema: "public", Name: "user_totals",
+				Columns:   columns("id", "name", "total", "n", "latest"),
+				DependsOn: []string{"public.orders", "public.users"},
+				Definition: ` SELECT u.id,
+    (u.first || ' '::text) || u.last AS name,
+    sum(o.total) AS total,
+    count(*) AS n,
+    ( SELECT max(o2.total) FROM orders o2 WHERE o2.user_id = u.id) AS latest
+   FROM users u
+     JOIN orders o ON o.user_id = u.id
+  GROUP BY u.id;`,
+			},
+			{
+				Schema: "public", Name: "big_spenders",
+				Columns:    columns("id", "total"),
+				DependsOn:  []string{"public.user_totals"},
+				Definition: ` WITH t AS (SELECT user_totals.id, user_totals.total FROM user_totals) SELECT id, total FROM t WHERE total > 100;`,
+			},
+			{
+				Schema: "public", Name: "contacts",
+				Columns:    columns("email"),
+				DependsOn:  []string{"public.users", "crm.leads"},
+				Definition: ` SELECT users.email FROM users UNION SELECT l.email FROM crm.leads l;`,
+			},
+		},
+	}
+
+	deriveColumnLineage(&info)
+
+	tests := []struct {
+		view   int
+		column string
+		want   []string
+	}{
+		{0, "id", []string{"public.users.id"}},
+		{0, "name", []string{"public.users.first", "public.users.last"}},
+		{0, "total", []string{"public.orders.total"}},
+		{0, "n", nil},
+		{0, "latest", []string{"public.orders.total"}},
+		{1, "total", []string{"public.orders.total"}},
+		{2, "email", []string{"crm.leads.email", "public.users.email"}},
+	}
+	for _, tt := range tests {
+		view := info.Views[tt.view]
+		for _, col := range view.Columns {
+			if col.Name == tt.column && !reflect.DeepEqual(col.DerivedFrom, tt.want) {
+				t.Errorf("%s.%s derived from %v, want %v", view.Name, col.Name, col.DerivedFrom, tt.want)
+			}
+		}
+	}
+}
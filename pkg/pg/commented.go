@@ -0,0 +1,33 @@
+package pg
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Commented wraps conn so that every query starts with a /* comment */,
+// letting DBAs attribute pgmd's queries in server logs and
+// pg_stat_activity.
+func Commented(conn Querier, comment string) Querier {
+	comment = strings.ReplaceAll(comment, "*/", "* /")
+	return commentedQuerier{conn: conn, prefix: "/* " + comment + " */\n"}
+}
+
+type commentedQuerier struct {
+	conn   Querier
+	prefix string
+}
+
+func (q commentedQuerier) unwrap() Querier {
+	return q.conn
+}
+
+func (q commentedQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return q.conn.Query(ctx, q.prefix+sql, args...)
+}
+
+func (q commentedQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return q.conn.QueryRow(ctx, q.prefix+sql, args...)
+}
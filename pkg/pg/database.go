@@ -0,0 +1,256 @@
+package pg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+)
+
+// Database holds the documented schemas plus optional database-wide
+// sections that don't belong to any single schema.
+type Database struct {
+	Schemas    []SchemaInfo `json:"schemas"`
+	Extensions []Extension  `json:"extensions,omitempty"`
+	Storage    *Storage     `json:"storage,omitempty"`
+	Roles      []Role       `json:"roles,omitempty"`
+	Settings   []Setting    `json:"settings,omitempty"`
+	// Warnings describes permission gaps and sections left out because
+	// they failed to load.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Hash returns a content hash of the introspected model as
+// "sha256:<hex>". Storage statistics, sequence values and schema
+// warnings are left out since they drift with the data or the connected
+// role rather than the schema.
+func (db Database) Hash() (string, error) {
+	db.Storage = nil
+	db.Schemas = slices.Clone(db.Schemas)
+	for i := range db.Schemas {
+		stripVolatile(&db.Schemas[i])
+	}
+	data, err := json.Marshal(db)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Extension is an installed extension; Schema is where its objects live.
+type Extension struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Schema      string `json:"schema"`
+	Description string `json:"description,omitempty"`
+}
+
+// FetchExtensions lists installed extensions. plpgsql is left out since
+// every database has it.
+func FetchExtensions(ctx context.Context, conn Querier) ([]Extension, error) {
+	query := `
+		SELECT
+			e.extname,
+			e.extversion,
+			n.nspname,
+			COALESCE(obj_description(e.oid, 'pg_extension'), '') as description
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		WHERE e.extname <> 'plpgsql'
+		ORDER BY e.extname`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var extensions []Extension
+	for rows.Next() {
+		var ext Extension
+		if err := rows.Scan(&ext.Name, &ext.Version, &ext.Schema, &ext.Description); err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, ext)
+	}
+
+	return extensions, nil
+}
+
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value,omitempty"`
+	Unit   string `json:"unit,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// schemaSettings are the GUCs that change how a schema behaves or is
+// interpreted: name resolution, literals, time handling, planner
+// statistics and storage defaults.
+var schemaSettings = []string{
+	"bytea_output",
+	"check_function_bodies",
+	"datestyle",
+	"default_statistics_target",
+	"default_table_access_method",
+	"default_tablespace",
+	"default_text_search_config",
+	"default_toast_compression",
+	"default_transaction_isolation",
+	"intervalstyle",
+	"row_security",
+	"search_path",
+	"standard_conforming_strings",
+	"timezone",
+	"xmloption",
+}
+
+// Role mirrors pg_roles minus anything password related.
+type Role struct {
+	Name        string `json:"name"`
+	CanLogin    bool   `json:"can_login,omitempty"`
+	Superuser   bool   `json:"superuser,omitempty"`
+	CreateDB    bool   `json:"create_db,omitempty"`
+	CreateRole  bool   `json:"create_role,omitempty"`
+	Replication bool   `json:"replication,omitempty"`
+	BypassRLS   bool   `json:"bypass_rls,omitempty"`
+	// ConnLimit is -1 for unlimited.
+	ConnLimit  int32    `json:"conn_limit,omitempty"`
+	ValidUntil string   `json:"valid_until,omitempty"`
+	MemberOf   []string `json:"member_of,omitempty"`
+}
+
+type Storage struct {
+	LargeObjects int64         `json:"large_objects,omitempty"`
+	ByteaColumns []ByteaColumn `json:"bytea_columns,omitempty"`
+}
+
+// ByteaColumn describes a bytea column using planner statistics, so the
+// figures are only as fresh as the last ANALYZE.
+type ByteaColumn struct {
+	Schema        string  `json:"schema"`
+	Table         string  `json:"table"`
+	Column        string  `json:"column"`
+	AvgWidth      int32   `json:"avg_width,omitempty"`
+	NullFrac      float32 `json:"null_frac,omitempty"`
+	EstimatedRows int64   `json:"estimated_rows,omitempty"`
+}
+
+// EstimatedBytes approximates the column's total payload.
+func (c ByteaColumn) EstimatedBytes() int64 {
+	return int64(float64(c.AvgWidth) * float64(c.EstimatedRows) * (1 - float64(c.NullFrac)))
+}
+
+func FetchStorage(ctx context.Context, conn Querier, schemas []string) (*Storage, error) {
+	var storage Storage
+
+	if err := conn.QueryRow(ctx, `SELECT count(*) FROM pg_largeobject_metadata`).Scan(&storage.LargeObjects); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			s.schemaname,
+			s.tablename,
+			s.attname,
+			s.avg_width,
+			s.null_frac,
+			GREATEST(c.reltuples, 0)::bigint as estimated_rows
+		FROM pg_stats s
+		JOIN pg_namespace n ON n.nspname = s.schemaname
+		JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = s.tablename
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = s.attname
+		WHERE s.schemaname = ANY($1)
+		  AND NOT s.inherited
+		  AND a.atttypid = 'bytea'::regtype
+		ORDER BY s.avg_width DESC, s.schemaname, s.tablename, s.attname`
+
+	rows, err := conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col ByteaColumn
+		if err := rows.Scan(&col.Schema, &col.Table, &col.Column, &col.AvgWidth, &col.NullFrac, &col.EstimatedRows); err != nil {
+			return nil, err
+		}
+		storage.ByteaColumns = append(storage.ByteaColumns, col)
+	}
+
+	return &storage, nil
+}
+
+// FetchRoles lists cluster roles, skipping the predefined pg_* roles.
+func FetchRoles(ctx context.Context, conn Querier) ([]Role, error) {
+	query := `
+		SELECT
+			r.rolname,
+			r.rolcanlogin,
+			r.rolsuper,
+			r.rolcreatedb,
+			r.rolcreaterole,
+			r.rolreplication,
+			r.rolbypassrls,
+			r.rolconnlimit,
+			COALESCE(r.rolvaliduntil::text, '') as valid_until,
+			ARRAY(
+				SELECT m.rolname
+				FROM pg_auth_members am
+				JOIN pg_roles m ON m.oid = am.roleid
+				WHERE am.member = r.oid
+				ORDER BY m.rolname
+			) as member_of
+		FROM pg_roles r
+		WHERE r.rolname NOT LIKE 'pg\_%'
+		ORDER BY r.rolname`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []Role
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.Name, &role.CanLogin, &role.Superuser, &role.CreateDB, &role.CreateRole,
+			&role.Replication, &role.BypassRLS, &role.ConnLimit, &role.ValidUntil, &role.MemberOf); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// FetchSettings returns the schema-relevant settings whose value doesn't
+// come from the built-in default.
+func FetchSettings(ctx context.Context, conn Querier) ([]Setting, error) {
+	query := `
+		SELECT name, setting, COALESCE(unit, ''), source
+		FROM pg_settings
+		WHERE lower(name) = ANY($1)
+		  AND source <> 'default'
+		ORDER BY name`
+
+	rows, err := conn.Query(ctx, query, schemaSettings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settings []Setting
+	for rows.Next() {
+		var st Setting
+		if err := rows.Scan(&st.Name, &st.Value, &st.Unit, &st.Source); err != nil {
+			return nil, err
+		}
+		settings = append(settings, st)
+	}
+
+	return settings, nil
+}
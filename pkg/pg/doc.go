@@ -0,0 +1,18 @@
+// Package pg introspects a PostgreSQL database into a plain schema model.
+//
+// FetchSchemas reads tables, views, sequences, triggers, functions and
+// types over any Querier (*pgx.Conn, pgx.Tx or *pgxpool.Pool); a Fetcher
+// keeps a pool around and introspects schemas in parallel. The model
+// (SchemaInfo, Table, Column, ...) is plain data with JSON tags, and
+// MarshalDocument/UnmarshalDocument read and write it as a versioned
+// snapshot, so it can also be produced or consumed without a database.
+//
+// Two separate contracts apply. The Go API follows semantic versioning
+// of the module: within a major version, exported functions, types and
+// fields may be added but are not renamed, removed or changed in
+// signature; that takes a new major version. The JSON document has its
+// own FormatVersion, which tracks the encoded shape only: it is bumped
+// when a JSON field is renamed or removed or its meaning changes, and
+// readers reject other versions. Adding a field changes neither, and a
+// Go refactor that keeps the JSON tags leaves FormatVersion alone.
+package pg
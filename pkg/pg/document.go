@@ -0,0 +1,34 @@
+package pg
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FormatVersion is the version of the JSON document shape. It is bumped
+// whenever a field is renamed or removed or its meaning changes; adding
+// fields does not bump it.
+const FormatVersion = 1
+
+type document struct {
+	FormatVersion int `json:"format_version"`
+	Database
+}
+
+// MarshalDocument encodes db as a versioned JSON document.
+func MarshalDocument(db Database) ([]byte, error) {
+	return json.MarshalIndent(document{FormatVersion: FormatVersion, Database: db}, "", "  ")
+}
+
+// UnmarshalDocument decodes a document written by MarshalDocument,
+// rejecting versions this build does not understand.
+func UnmarshalDocument(data []byte) (Database, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Database{}, err
+	}
+	if doc.FormatVersion != FormatVersion {
+		return Database{}, fmt.Errorf("unsupported format version %d (want %d)", doc.FormatVersion, FormatVersion)
+	}
+	return doc.Database, nil
+}
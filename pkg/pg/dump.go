@@ -0,0 +1,1377 @@
+package pg
+
+import (
+	"cmp"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseDump builds the model from a plain-format pg_dump --schema-only
+// file instead of a live connection. It reads the statements pg_dump
+// writes for tables, constraints, indexes, views, sequences, triggers,
+// functions, types and comments, and ignores everything else.
+//
+// A dump records less than the catalogs: view columns have only the types
+// that can be inferred from the view's query, extensions have no version,
+// and grants and sequence values are not read.
+func ParseDump(r io.Reader, schemas []string, opts FetchOptions) (Database, error) {
+	keep, err := opts.tableFilter()
+	if err != nil {
+		return Database{}, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Database{}, err
+	}
+	statements, err := lexDump(string(data))
+	if err != nil {
+		return Database{}, err
+	}
+
+	d := newDumpModel()
+	d.withBodies = opts.FunctionBodies
+	for _, toks := range statements {
+		d.statement(&dumpStmt{src: string(data), toks: toks})
+	}
+
+	db := Database{Extensions: d.extensions}
+	for _, schema := range schemas {
+		db.Schemas = append(db.Schemas, d.schema(schema, keep))
+	}
+	return db, nil
+}
+
+// dumpFunction is a function with the argument list COMMENT ON FUNCTION
+// identifies it by.
+type dumpFunction struct {
+	Function
+	identity string
+	// source is the CREATE statement, kept to find the materialized views
+	// the function refreshes.
+	source string
+}
+
+// dumpPartition records a PARTITION OF or ATTACH PARTITION statement.
+type dumpPartition struct {
+	parent string
+	bound  string
+}
+
+// dumpModel collects objects by qualified name as statements are read.
+type dumpModel struct {
+	tables     map[string]*Table
+	views      map[string]*View
+	matviews   map[string]*MaterializedView
+	sequences  map[string]*Sequence
+	functions  []*dumpFunction
+	types      map[string]*CustomType
+	domains    map[string]string
+	triggers   []Trigger
+	partitions map[string]dumpPartition
+	// relations holds every relation name, including foreign tables and
+	// schemas that are not documented, for view dependencies.
+	relations  map[string]bool
+	warnings   map[string][]Warning
+	extensions []Extension
+	// withBodies keeps each function's statement as its definition.
+	withBodies bool
+}
+
+func newDumpModel() *dumpModel {
+	return &dumpModel{
+		tables:     make(map[string]*Table),
+		views:      make(map[string]*View),
+		matviews:   make(map[string]*MaterializedView),
+		sequences:  make(map[string]*Sequence),
+		types:      make(map[string]*CustomType),
+		domains:    make(map[string]string),
+		partitions: make(map[string]dumpPartition),
+		relations:  make(map[string]bool),
+		warnings:   make(map[string][]Warning),
+	}
+}
+
+func (d *dumpModel) warn(schema, kind, object, message string) {
+	d.warnings[schema] = append(d.warnings[schema], Warning{Kind: kind, Object: object, Message: message})
+}
+
+func (d *dumpModel) statement(s *dumpStmt) {
+	switch {
+	case s.keyword("CREATE"):
+		s.keyword("OR", "REPLACE")
+		switch {
+		case s.keyword("TABLE"), s.keyword("UNLOGGED", "TABLE"):
+			d.createTable(s)
+		case s.keyword("FOREIGN", "TABLE"):
+			s.keyword("IF", "NOT", "EXISTS")
+			schema, name := s.qualifiedName()
+			d.relations[schema+"."+name] = true
+			d.warn(schema, WarningSkipped, name, "foreign table is not documented")
+		case s.keyword("VIEW"), s.keyword("RECURSIVE", "VIEW"):
+			d.createView(s)
+		case s.keyword("MATERIALIZED", "VIEW"):
+			d.createMaterializedView(s)
+		case s.keyword("SEQUENCE"):
+			s.keyword("IF", "NOT", "EXISTS")
+			schema, name := s.qualifiedName()
+			seq := &Sequence{Schema: schema, Name: name}
+			parseSequenceOptions(s, seq)
+			d.sequences[schema+"."+name] = seq
+		case s.keyword("INDEX"):
+			d.createIndex(s, false)
+		case s.keyword("UNIQUE", "INDEX"):
+			d.createIndex(s, true)
+		case s.keyword("FUNCTION"):
+			d.createFunction(s, "")
+		case s.keyword("PROCEDURE"):
+			d.createFunction(s, "procedure")
+		case s.keyword("AGGREGATE"):
+			schema, name := s.qualifiedName()
+			d.warn(schema, WarningSkipped, name, "aggregate is not documented")
+		case s.keyword("TYPE"):
+			d.createType(s)
+		case s.keyword("DOMAIN"):
+			schema, name := s.qualifiedName()
+			s.keyword("AS")
+			start := s.until(anyOf("COLLATE", "DEFAULT", "CONSTRAINT", "NOT", "NULL", "CHECK"))
+			d.domains[schema+"."+name] = s.text(start, s.i)
+		case s.keyword("TRIGGER"), s.keyword("CONSTRAINT", "TRIGGER"):
+			d.createTrigger(s)
+		case s.keyword("POLICY"):
+			d.createPolicy(s)
+		case s.keyword("RULE"):
+			rule := s.next().value
+			s.until(anyOf("TO"))
+			if s.keyword("TO") && rule != "_RETURN" {
+				schema, name := s.qualifiedName()
+				d.warn(schema, WarningUnsupported, name, "rule "+rule+" is not documented")
+			}
+		case s.keyword("EXTENSION"):
+			s.keyword("IF", "NOT", "EXISTS")
+			ext := Extension{Name: s.next().value}
+			for !s.done() {
+				switch {
+				case s.keyword("SCHEMA"):
+					ext.Schema = s.next().value
+				case s.keyword("VERSION"):
+					ext.Version = s.next().value
+				default:
+					s.i++
+				}
+			}
+			if ext.Name != "plpgsql" {
+				d.extensions = append(d.extensions, ext)
+			}
+		}
+	case s.keyword("ALTER", "TABLE"):
+		d.alterTable(s)
+	case s.keyword("ALTER", "SEQUENCE"):
+		schema, name := s.qualifiedName()
+		if seq := d.sequences[schema+"."+name]; seq != nil && s.keyword("OWNED", "BY") {
+			if parts := s.names(); len(parts) >= 2 {
+				seq.OwnerTable, seq.OwnerColumn = parts[len(parts)-2], parts[len(parts)-1]
+			}
+		}
+	case s.keyword("COMMENT", "ON"):
+		d.comment(s)
+	}
+}
+
+// tableElementStop ends a column's type or default within CREATE TABLE.
+var tableElementStop = anyOf("COLLATE", "DEFAULT", "NOT", "NULL", "CONSTRAINT", "CHECK", "GENERATED", "PRIMARY", "UNIQUE", "REFERENCES", "COMPRESSION", "STORAGE")
+
+func (d *dumpModel) createTable(s *dumpStmt) {
+	s.keyword("IF", "NOT", "EXISTS")
+	schema, name := s.qualifiedName()
+	qualified := schema + "." + name
+	t := &Table{Schema: schema, Name: name, ReplicaIdentity: "default"}
+	d.tables[qualified] = t
+	d.relations[qualified] = true
+
+	if s.keyword("PARTITION", "OF") {
+		parentSchema, parent := s.qualifiedName()
+		if from, to, ok := s.group(); ok {
+			d.tableElements(s, t, from, to)
+		}
+		start := s.until(func(t dumpToken) bool {
+			// Hash partition bounds read FOR VALUES WITH (...).
+			return t.is("PARTITION") || t.is("TABLESPACE") || t.is("USING") || (t.is("WITH") && !s.toks[s.i-1].is("VALUES"))
+		})
+		d.partitions[qualified] = dumpPartition{parent: parentSchema + "." + parent, bound: s.text(start, s.i)}
+	} else if from, to, ok := s.group(); ok {
+		d.tableElements(s, t, from, to)
+	}
+
+	for !s.done() {
+		switch {
+		case s.keyword("INHERITS"):
+			from, to, _ := s.group()
+			var parents []string
+			for _, part := range s.split(from, to) {
+				parents = append(parents, s.text(part[0], part[1]))
+			}
+			d.warn(schema, WarningUnsupported, name, "inherits from "+strings.Join(parents, ", ")+"; table inheritance is not documented")
+		case s.keyword("PARTITION", "BY"):
+			t.PartitionStrategy = strings.ToLower(s.next().text)
+			from, to, _ := s.group()
+			t.PartitionKey = s.text(from, to)
+		default:
+			s.i++
+		}
+	}
+}
+
+// tableElements reads the columns and constraints of CREATE TABLE.
+func (d *dumpModel) tableElements(s *dumpStmt, t *Table, from, to int) {
+	for _, part := range s.split(from, to) {
+		e := s.sub(part[0], part[1])
+		switch {
+		case e.keyword("CONSTRAINT"):
+			name := e.next().value
+			addConstraint(e, t, name)
+		case e.peek().is("CHECK") || e.peek().is("PRIMARY") || e.peek().is("UNIQUE") || e.peek().is("FOREIGN") || e.peek().is("EXCLUDE"):
+			addConstraint(e, t, "")
+		case e.peek().is("LIKE"):
+		default:
+			t.Columns = append(t.Columns, parseColumn(e, len(t.Columns)+1))
+		}
+	}
+}
+
+// parseColumn reads a column definition. Type is left as written until
+// the column's schema is assembled.
+func parseColumn(e *dumpStmt, position int) Column {
+	col := Column{Name: e.next().value, Position: position, Nullable: true}
+	start := e.until(tableElementStop)
+	col.Type = e.text(start, e.i)
+
+	for !e.done() {
+		switch {
+		case e.keyword("COLLATE"):
+			e.names()
+		case e.keyword("DEFAULT"):
+			start := e.until(func(t dumpToken) bool {
+				return t.is("CONSTRAINT") || t.is("CHECK") || t.is("GENERATED") || t.is("PRIMARY") ||
+					t.is("UNIQUE") || t.is("REFERENCES") || t.is("NOT")
+			})
+			col.Default = e.text(start, e.i)
+		case e.keyword("NOT", "NULL"):
+			col.Nullable = false
+		case e.keyword("GENERATED", "ALWAYS", "AS", "IDENTITY"):
+			col.Identity = "ALWAYS"
+			e.group()
+		case e.keyword("GENERATED", "BY", "DEFAULT", "AS", "IDENTITY"):
+			col.Identity = "BY DEFAULT"
+			e.group()
+		case e.keyword("PRIMARY", "KEY"):
+			col.IsPK = true
+			col.Nullable = false
+		case e.keyword("UNIQUE"):
+			col.IsUnique = true
+		default:
+			e.i++
+		}
+	}
+	return col
+}
+
+// addConstraint reads a table constraint after its name.
+func addConstraint(e *dumpStmt, t *Table, name string) {
+	switch {
+	case e.peek().is("CHECK"):
+		start := e.i
+		e.i = len(e.toks)
+		check := CheckConstraint{Name: name, Definition: e.text(start, e.i)}
+		referenced := make(map[string]bool)
+		for _, tok := range e.toks[start:] {
+			if tok.isName() {
+				referenced[tok.value] = true
+			}
+		}
+		for _, col := range t.Columns {
+			if referenced[col.Name] {
+				check.Columns = append(check.Columns, col.Name)
+			}
+		}
+		t.Checks = append(t.Checks, check)
+
+	case e.keyword("PRIMARY", "KEY"), e.keyword("UNIQUE"):
+		primary := e.toks[e.i-1].is("KEY")
+		e.keyword("NULLS", "NOT", "DISTINCT")
+		columns := nameList(e)
+		for i := range t.Columns {
+			if slices.Contains(columns, t.Columns[i].Name) {
+				if primary {
+					t.Columns[i].IsPK = true
+				} else {
+					t.Columns[i].IsUnique = true
+				}
+			}
+		}
+		t.Indexes = append(t.Indexes, Index{Name: name, Columns: columns, IsUnique: true, IsPrimary: primary})
+
+	case e.keyword("FOREIGN", "KEY"):
+		fk := ForeignKey{Name: name, Columns: nameList(e), OnDelete: "NO ACTION", OnUpdate: "NO ACTION"}
+		if e.keyword("REFERENCES") {
+			fk.RefSchema, fk.RefTable = e.qualifiedName()
+			if fk.RefSchema == "" {
+				fk.RefSchema = t.Schema
+			}
+			fk.RefColumns = nameList(e)
+		}
+		for !e.done() {
+			switch {
+			case e.keyword("ON", "DELETE"):
+				fk.OnDelete = referentialActionWords(e)
+			case e.keyword("ON", "UPDATE"):
+				fk.OnUpdate = referentialActionWords(e)
+			default:
+				e.i++
+			}
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	}
+}
+
+func referentialActionWords(e *dumpStmt) string {
+	for _, action := range []string{"NO ACTION", "RESTRICT", "CASCADE", "SET NULL", "SET DEFAULT"} {
+		if e.keyword(strings.Fields(action)...) {
+			// SET NULL and SET DEFAULT may name columns.
+			e.group()
+			return action
+		}
+	}
+	return "NO ACTION"
+}
+
+// nameList reads a parenthesized list of column names.
+func nameList(e *dumpStmt) []string {
+	from, to, ok := e.group()
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, part := range e.split(from, to) {
+		if tok := e.toks[part[0]]; tok.isName() {
+			names = append(names, tok.value)
+		}
+	}
+	return names
+}
+
+func (d *dumpModel) alterTable(s *dumpStmt) {
+	s.keyword("IF", "EXISTS")
+	s.keyword("ONLY")
+	schema, name := s.qualifiedName()
+	t := d.tables[schema+"."+name]
+	if t == nil {
+		return
+	}
+
+	switch {
+	case s.keyword("ADD", "CONSTRAINT"):
+		constraint := s.next().value
+		addConstraint(s, t, constraint)
+
+	case s.keyword("ALTER", "COLUMN"), s.keyword("ALTER"):
+		col := tableColumn(t, s.next().value)
+		if col == nil {
+			return
+		}
+		switch {
+		case s.keyword("SET", "DEFAULT"):
+			col.Default = s.rest()
+		case s.keyword("SET", "NOT", "NULL"):
+			col.Nullable = false
+		case s.keyword("SET", "STORAGE"):
+			col.Storage = strings.ToUpper(s.next().text)
+		case s.keyword("SET", "COMPRESSION"):
+			col.Compression = s.next().value
+		case s.keyword("ADD", "GENERATED"):
+			if s.keyword("ALWAYS") {
+				col.Identity = "ALWAYS"
+			} else if s.keyword("BY", "DEFAULT") {
+				col.Identity = "BY DEFAULT"
+			}
+			s.keyword("AS", "IDENTITY")
+			seq := &Sequence{Schema: schema, OwnerTable: name, OwnerColumn: col.Name, Identity: true}
+			if from, to, ok := s.group(); ok {
+				parseSequenceOptions(s.sub(from, to), seq)
+			}
+			if seq.Name != "" {
+				d.sequences[seq.Schema+"."+seq.Name] = seq
+			}
+		}
+
+	case s.keyword("ATTACH", "PARTITION"):
+		childSchema, child := s.qualifiedName()
+		d.partitions[childSchema+"."+child] = dumpPartition{parent: schema + "." + name, bound: s.rest()}
+
+	case s.keyword("REPLICA", "IDENTITY"):
+		switch {
+		case s.keyword("USING", "INDEX"):
+			t.ReplicaIdentity = "index"
+			t.ReplicaIdentityIndex = s.next().value
+		default:
+			t.ReplicaIdentity = strings.ToLower(s.next().text)
+		}
+
+	case s.keyword("ENABLE", "ROW", "LEVEL", "SECURITY"):
+		t.RowSecurity = true
+
+	case s.keyword("FORCE", "ROW", "LEVEL", "SECURITY"):
+		t.ForceRowSecurity = true
+
+	case s.keyword("INHERIT"):
+		d.warn(schema, WarningUnsupported, name, "inherits from "+s.rest()+"; table inheritance is not documented")
+	}
+}
+
+// createPolicy reads CREATE POLICY name ON table [AS PERMISSIVE |
+// RESTRICTIVE] [FOR command] [TO role, ...] [USING (expr)] [WITH CHECK
+// (expr)] and adds the policy to its table.
+func (d *dumpModel) createPolicy(s *dumpStmt) {
+	pol := Policy{Name: s.next().value, Command: "ALL"}
+	if !s.keyword("ON") {
+		return
+	}
+	schema, name := s.qualifiedName()
+	t := d.tables[schema+"."+name]
+	if t == nil {
+		return
+	}
+
+	for !s.done() {
+		switch {
+		case s.keyword("AS"):
+			pol.Restrictive = s.next().is("RESTRICTIVE")
+		case s.keyword("FOR"):
+			pol.Command = strings.ToUpper(s.next().text)
+		case s.keyword("TO"):
+			for {
+				pol.Roles = append(pol.Roles, s.next().value)
+				if !s.peek().punct(",") {
+					break
+				}
+				s.i++
+			}
+		case s.keyword("USING"):
+			if from, to, ok := s.group(); ok {
+				pol.Using = s.text(from, to)
+			}
+		case s.keyword("WITH", "CHECK"):
+			if from, to, ok := s.group(); ok {
+				pol.WithCheck = s.text(from, to)
+			}
+		default:
+			s.i++
+		}
+	}
+	// pg_dump leaves out TO for a policy on PUBLIC.
+	if len(pol.Roles) == 0 {
+		pol.Roles = []string{"public"}
+	}
+	t.Policies = append(t.Policies, pol)
+}
+
+func tableColumn(t *Table, name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// parseSequenceOptions reads the options of CREATE SEQUENCE or an identity
+// column, filling in PostgreSQL's defaults for any left out.
+func parseSequenceOptions(s *dumpStmt, seq *Sequence) {
+	var start, min, max *int64
+	seq.DataType = "bigint"
+	for !s.done() {
+		switch {
+		case s.keyword("SEQUENCE", "NAME"):
+			schema, name := s.qualifiedName()
+			if schema != "" {
+				seq.Schema = schema
+			}
+			seq.Name = name
+		case s.keyword("AS"):
+			start := s.until(anyOf("START", "INCREMENT", "MINVALUE", "MAXVALUE", "NO", "CACHE", "CYCLE", "OWNED", "SEQUENCE"))
+			seq.DataType = s.text(start, s.i)
+		case s.keyword("START"):
+			s.keyword("WITH")
+			start = signedInt(s)
+		case s.keyword("INCREMENT"):
+			s.keyword("BY")
+			seq.Increment = *signedInt(s)
+		case s.keyword("MINVALUE"):
+			min = signedInt(s)
+		case s.keyword("MAXVALUE"):
+			max = signedInt(s)
+		case s.keyword("CACHE"):
+			seq.Cache = *signedInt(s)
+		case s.keyword("CYCLE"):
+			seq.Cycle = true
+		case s.keyword("NO", "CYCLE"), s.keyword("NO", "MINVALUE"), s.keyword("NO", "MAXVALUE"):
+		default:
+			s.i++
+		}
+	}
+
+	if seq.Increment == 0 {
+		seq.Increment = 1
+	}
+	if seq.Cache == 0 {
+		seq.Cache = 1
+	}
+	typeMin, typeMax := int64(-9223372036854775808), int64(9223372036854775807)
+	switch seq.DataType {
+	case "integer":
+		typeMin, typeMax = -2147483648, 2147483647
+	case "smallint":
+		typeMin, typeMax = -32768, 32767
+	}
+	seq.Min, seq.Max = 1, typeMax
+	if seq.Increment < 0 {
+		seq.Min, seq.Max = typeMin, -1
+	}
+	if min != nil {
+		seq.Min = *min
+	}
+	if max != nil {
+		seq.Max = *max
+	}
+	seq.Start = seq.Min
+	if seq.Increment < 0 {
+		seq.Start = seq.Max
+	}
+	if start != nil {
+		seq.Start = *start
+	}
+}
+
+// signedInt reads an optionally negative integer, returning 0 if none
+// follows.
+func signedInt(s *dumpStmt) *int64 {
+	negative := false
+	if t := s.peek(); t.kind == tokOp && t.text == "-" {
+		negative = true
+		s.i++
+	}
+	n, _ := strconv.ParseInt(s.next().text, 10, 64)
+	if negative {
+		n = -n
+	}
+	return &n
+}
+
+func (d *dumpModel) createIndex(s *dumpStmt, unique bool) {
+	s.keyword("CONCURRENTLY")
+	s.keyword("IF", "NOT", "EXISTS")
+	idx := Index{Name: s.next().value, IsUnique: unique}
+	if !s.keyword("ON") {
+		return
+	}
+	s.keyword("ONLY")
+	schema, name := s.qualifiedName()
+	t := d.tables[schema+"."+name]
+	if t == nil {
+		return
+	}
+	if s.keyword("USING") {
+		s.next()
+	}
+
+	from, to, _ := s.group()
+	for _, part := range s.split(from, to) {
+		if key := indexKey(s, part[0], part[1]); key != "" {
+			idx.Columns = append(idx.Columns, key)
+		}
+	}
+	for !s.done() {
+		switch {
+		case s.keyword("INCLUDE"):
+			idx.Columns = append(idx.Columns, nameList(s)...)
+		case s.keyword("WHERE"):
+			idx.Predicate = s.rest()
+			s.i = len(s.toks)
+		default:
+			s.i++
+		}
+	}
+	t.Indexes = append(t.Indexes, idx)
+}
+
+// indexKey returns the column of one index key in tokens [from, to), or
+// an expression's text as pg_get_indexdef prints it, without the sort
+// order, collation or operator class that may follow either.
+func indexKey(s *dumpStmt, from, to int) string {
+	tok := s.toks[from]
+	if tok.isName() && (to == from+1 || !s.toks[from+1].punct("(") && !s.toks[from+1].punct(".")) {
+		return tok.value
+	}
+	depth := 0
+	for k := from; k < to; k++ {
+		switch {
+		case s.toks[k].punct("("):
+			depth++
+		case s.toks[k].punct(")"):
+			if depth--; depth == 0 {
+				return s.text(from, k+1)
+			}
+		}
+	}
+	return ""
+}
+
+func (d *dumpModel) createView(s *dumpStmt) {
+	schema, name := s.qualifiedName()
+	columns, definition := viewQuery(s)
+	v := &View{Schema: schema, Name: name, Columns: columns, Definition: definition}
+	d.views[schema+"."+name] = v
+	d.relations[schema+"."+name] = true
+}
+
+func (d *dumpModel) createMaterializedView(s *dumpStmt) {
+	s.keyword("IF", "NOT", "EXISTS")
+	schema, name := s.qualifiedName()
+	columns, definition := viewQuery(s)
+	mv := &MaterializedView{Schema: schema, Name: name, Columns: columns, Definition: definition}
+	d.matviews[schema+"."+name] = mv
+	d.relations[schema+"."+name] = true
+}
+
+// viewQuery reads an optional column list and the query after AS,
+// returning the named columns and the definition as pg_get_viewdef
+// prints it.
+func viewQuery(s *dumpStmt) ([]Column, string) {
+	var names []string
+	if s.peek().punct("(") {
+		names = nameList(s)
+	}
+	s.until(anyOf("AS"))
+	s.keyword("AS")
+
+	start := s.i
+	end := len(s.toks)
+	// Drop WITH [NO] DATA and WITH ... CHECK OPTION.
+	for k := start; k < len(s.toks); k++ {
+		if s.toks[k].is("WITH") && k+1 < len(s.toks) &&
+			(s.toks[k+1].is("DATA") || s.toks[k+1].is("NO") || s.toks[k+1].is("CHECK") || s.toks[k+1].is("CASCADED") || s.toks[k+1].is("LOCAL")) &&
+			depthAt(s.toks[start:k]) == 0 {
+			end = k
+			break
+		}
+	}
+	definition := " " + s.text(start, end) + ";"
+
+	if names == nil {
+		names = selectNames(s.sub(start, end))
+	}
+	columns := make([]Column, len(names))
+	for i, n := range names {
+		columns[i] = Column{Name: n, Position: i + 1, Nullable: true}
+	}
+	return columns, definition
+}
+
+func depthAt(toks []dumpToken) int {
+	depth := 0
+	for _, t := range toks {
+		switch {
+		case t.punct("("):
+			depth++
+		case t.punct(")"):
+			depth--
+		}
+	}
+	return depth
+}
+
+// selectNames returns the output column names of a deparsed SELECT, which
+// names every expression other than a plain column reference with AS.
+func selectNames(q *dumpStmt) []string {
+	if !q.keyword("SELECT") {
+		return nil
+	}
+	if q.keyword("DISTINCT") {
+		if q.keyword("ON") {
+			q.group()
+		}
+	}
+	start := q.until(anyOf("FROM", "WHERE", "GROUP", "HAVING", "WINDOW", "UNION", "INTERSECT", "EXCEPT", "ORDER", "LIMIT", "OFFSET"))
+	var names []string
+	for _, part := range q.split(start, q.i) {
+		last := q.toks[part[1]-1]
+		switch {
+		case part[1]-part[0] >= 2 && q.toks[part[1]-2].is("AS") && last.isName():
+			names = append(names, last.value)
+		case last.isName():
+			names = append(names, last.value)
+		default:
+			names = append(names, "?column?")
+		}
+	}
+	return names
+}
+
+// functionAttributes end a function's return type.
+var functionAttributes = anyOf("LANGUAGE", "IMMUTABLE", "STABLE", "VOLATILE", "STRICT", "CALLED", "RETURNS", "SECURITY",
+	"EXTERNAL", "PARALLEL", "COST", "ROWS", "SUPPORT", "SET", "AS", "WINDOW", "LEAKPROOF", "NOT", "TRANSFORM", "BEGIN")
+
+func (d *dumpModel) createFunction(s *dumpStmt, kind string) {
+	schema, name := s.qualifiedName()
+	fn := &dumpFunction{Function: Function{Schema: schema, Name: name, Kind: kind}, source: s.text(0, len(s.toks))}
+	if d.withBodies {
+		fn.Definition = fn.source
+	}
+	from, to, _ := s.group()
+	fn.Arguments = s.text(from, to)
+	for _, part := range s.split(from, to) {
+		fn.Args = append(fn.Args, parseArgument(s.sub(part[0], part[1])))
+	}
+	fn.identity = identityArguments(fn.Args)
+
+	if s.keyword("RETURNS") {
+		if s.peek().is("TABLE") {
+			start := s.i
+			s.i++
+			from, to, _ := s.group()
+			for _, part := range s.split(from, to) {
+				arg := parseArgument(s.sub(part[0], part[1]))
+				arg.Mode = "TABLE"
+				fn.Args = append(fn.Args, arg)
+			}
+			fn.ReturnType = s.text(start, s.i)
+		} else {
+			start := s.until(functionAttributes)
+			fn.ReturnType = s.text(start, s.i)
+		}
+	}
+	fn.ReturnsSet = strings.HasPrefix(fn.ReturnType, "SETOF ") || strings.HasPrefix(fn.ReturnType, "TABLE(")
+
+	if kind == "" {
+		fn.Volatility = "volatile"
+	}
+	for !s.done() {
+		switch {
+		case s.keyword("WINDOW"):
+			d.warn(schema, WarningSkipped, name, "window function is not documented")
+			return
+		case s.keyword("LANGUAGE"):
+			fn.Language = strings.ToLower(s.next().value)
+		case s.keyword("IMMUTABLE"):
+			fn.Volatility = "immutable"
+		case s.keyword("STABLE"):
+			fn.Volatility = "stable"
+		case s.keyword("SECURITY", "DEFINER"):
+			fn.SecurityDefiner = true
+		case s.keyword("COST"):
+			fn.Cost, _ = strconv.ParseFloat(s.next().text, 64)
+		default:
+			s.i++
+		}
+	}
+	if fn.Cost == 0 {
+		fn.Cost = DefaultCost(fn.Language)
+	}
+	d.functions = append(d.functions, fn)
+}
+
+// parseArgument reads "[mode] [name] type [DEFAULT expr]".
+func parseArgument(e *dumpStmt) Argument {
+	arg := Argument{Mode: "IN"}
+	for _, mode := range []string{"IN", "OUT", "INOUT", "VARIADIC"} {
+		if e.keyword(mode) {
+			arg.Mode = mode
+			break
+		}
+	}
+	start := e.until(func(t dumpToken) bool { return t.is("DEFAULT") || (t.kind == tokOp && t.text == "=") })
+	end := e.i
+	if !e.done() {
+		e.i++
+		arg.Default = e.rest()
+	}
+
+	if end-start >= 2 && e.toks[start].isName() && !e.toks[start+1].punct(".") && !e.toks[start+1].punct("(") &&
+		!e.toks[start+1].punct("[") && !isBuiltinType(e.text(start, end)) {
+		arg.Name = e.toks[start].value
+		start++
+	}
+	arg.Type = e.text(start, end)
+	return arg
+}
+
+// identityArguments formats args as pg_get_function_identity_arguments
+// does, without defaults or TABLE columns.
+func identityArguments(args []Argument) string {
+	var parts []string
+	for _, a := range args {
+		if a.Mode == "TABLE" {
+			continue
+		}
+		var part []string
+		if a.Mode != "IN" {
+			part = append(part, a.Mode)
+		}
+		if a.Name != "" {
+			part = append(part, a.Name)
+		}
+		parts = append(parts, strings.Join(append(part, a.Type), " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *dumpModel) createType(s *dumpStmt) {
+	schema, name := s.qualifiedName()
+	if !s.keyword("AS") {
+		return
+	}
+	switch {
+	case s.keyword("ENUM"):
+		t := &CustomType{Schema: schema, Name: name, Kind: "enum"}
+		from, to, _ := s.group()
+		for k := from; k < to; k++ {
+			if s.toks[k].kind == tokString {
+				t.Values = append(t.Values, s.toks[k].value)
+			}
+		}
+		d.types[schema+"."+name] = t
+	case s.peek().punct("("):
+		t := &CustomType{Schema: schema, Name: name, Kind: "composite"}
+		from, to, _ := s.group()
+		for _, part := range s.split(from, to) {
+			e := s.sub(part[0], part[1])
+			attr := Column{Name: e.next().value, Position: len(t.Attributes) + 1, Nullable: true}
+			start := e.until(anyOf("COLLATE"))
+			attr.Type = e.text(start, e.i)
+			t.Attributes = append(t.Attributes, attr)
+			t.Values = append(t.Values, attr.Name+" "+attr.Type)
+		}
+		d.types[schema+"."+name] = t
+	}
+}
+
+// refreshFunctions lists the functions that refresh a materialized view,
+// with the triggers that run them. A dump holds no pg_cron jobs.
+func (d *dumpModel) refreshFunctions() []refreshFunction {
+	var functions []refreshFunction
+	seen := make(map[string]bool)
+	for _, fn := range d.functions {
+		qualified := fn.Schema + "." + fn.Name
+		if seen[qualified] || !refreshPattern.MatchString(fn.source) {
+			continue
+		}
+		seen[qualified] = true
+		rf := refreshFunction{schema: fn.Schema, name: fn.Name, source: fn.source}
+		for _, trig := range d.triggers {
+			schema := trig.FunctionSchema
+			if schema == "" {
+				schema = trig.Schema
+			}
+			if schema == fn.Schema && trig.Function == fn.Name {
+				rf.triggers = append(rf.triggers, trig.Schema+"."+trig.Table+"."+trig.Name)
+			}
+		}
+		functions = append(functions, rf)
+	}
+	return functions
+}
+
+func (d *dumpModel) createTrigger(s *dumpStmt) {
+	trig := Trigger{Name: s.next().value, Definition: s.text(0, len(s.toks))}
+	switch {
+	case s.keyword("BEFORE"):
+		trig.Timing = "BEFORE"
+	case s.keyword("AFTER"):
+		trig.Timing = "AFTER"
+	case s.keyword("INSTEAD", "OF"):
+		trig.Timing = "INSTEAD OF"
+	}
+
+	events := make(map[string]bool)
+	for !s.done() && !s.peek().is("ON") {
+		switch t := s.next(); {
+		case t.is("OF"):
+			for !s.done() && !s.peek().is("ON") && !s.peek().is("OR") {
+				if t := s.next(); t.isName() {
+					trig.Columns = append(trig.Columns, t.value)
+				}
+			}
+		case t.kind == tokIdent:
+			events[strings.ToUpper(t.text)] = true
+		}
+	}
+	var names []string
+	for _, event := range []string{"INSERT", "DELETE", "UPDATE", "TRUNCATE"} {
+		if events[event] {
+			names = append(names, event)
+		}
+	}
+	trig.Event = strings.Join(names, " OR ")
+
+	s.keyword("ON")
+	trig.Schema, trig.Table = s.qualifiedName()
+	trig.Level = "STATEMENT"
+	for !s.done() && !s.peek().is("EXECUTE") {
+		switch {
+		case s.keyword("FOR"):
+			s.keyword("EACH")
+			if s.keyword("ROW") {
+				trig.Level = "ROW"
+			}
+		case s.keyword("WHEN"):
+			from, to, _ := s.group()
+			trig.When = s.text(from, to)
+		case s.keyword("OLD", "TABLE"):
+			s.keyword("AS")
+			trig.OldTable = s.next().value
+		case s.keyword("NEW", "TABLE"):
+			s.keyword("AS")
+			trig.NewTable = s.next().value
+		default:
+			s.i++
+		}
+	}
+	if s.keyword("EXECUTE") {
+		s.next()
+		fnSchema, fn := s.qualifiedName()
+		trig.Function = fn
+		if fnSchema != "" && fnSchema != trig.Schema {
+			trig.FunctionSchema = fnSchema
+		}
+	}
+	d.triggers = append(d.triggers, trig)
+}
+
+func (d *dumpModel) comment(s *dumpStmt) {
+	var target *string
+	switch {
+	case s.keyword("TABLE"):
+		schema, name := s.qualifiedName()
+		if t := d.tables[schema+"."+name]; t != nil {
+			target = &t.Description
+		}
+	case s.keyword("VIEW"):
+		schema, name := s.qualifiedName()
+		if v := d.views[schema+"."+name]; v != nil {
+			target = &v.Description
+		}
+	case s.keyword("MATERIALIZED", "VIEW"):
+		schema, name := s.qualifiedName()
+		if mv := d.matviews[schema+"."+name]; mv != nil {
+			target = &mv.Description
+		}
+	case s.keyword("TYPE"):
+		schema, name := s.qualifiedName()
+		if t := d.types[schema+"."+name]; t != nil {
+			target = &t.Description
+		}
+	case s.keyword("COLUMN"):
+		parts := s.names()
+		if len(parts) < 2 {
+			return
+		}
+		relation := parts[len(parts)-2]
+		if len(parts) > 2 {
+			relation = parts[len(parts)-3] + "." + relation
+		}
+		if col := d.column(relation, parts[len(parts)-1]); col != nil {
+			target = &col.Description
+		}
+	case s.keyword("FUNCTION"), s.keyword("PROCEDURE"):
+		schema, name := s.qualifiedName()
+		from, to, _ := s.group()
+		identity := strings.Join(strings.Fields(s.text(from, to)), "")
+		var candidates []*dumpFunction
+		for _, fn := range d.functions {
+			if fn.Schema == schema && fn.Name == name {
+				candidates = append(candidates, fn)
+			}
+		}
+		for _, fn := range candidates {
+			if len(candidates) == 1 || strings.Join(strings.Fields(fn.identity), "") == identity {
+				target = &fn.Description
+				break
+			}
+		}
+	case s.keyword("EXTENSION"):
+		name := s.next().value
+		for i := range d.extensions {
+			if d.extensions[i].Name == name {
+				target = &d.extensions[i].Description
+			}
+		}
+	}
+
+	if target == nil {
+		return
+	}
+	s.until(anyOf("IS"))
+	if s.keyword("IS") && s.peek().kind == tokString {
+		*target = s.next().value
+	}
+}
+
+// column finds a column of a table, view, materialized view or composite
+// type by its qualified relation name.
+func (d *dumpModel) column(relation, name string) *Column {
+	var columns []Column
+	switch {
+	case d.tables[relation] != nil:
+		columns = d.tables[relation].Columns
+	case d.views[relation] != nil:
+		columns = d.views[relation].Columns
+	case d.matviews[relation] != nil:
+		columns = d.matviews[relation].Columns
+	case d.types[relation] != nil:
+		columns = d.types[relation].Attributes
+	}
+	for i := range columns {
+		if columns[i].Name == name {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// schema assembles the documented objects of one schema.
+func (d *dumpModel) schema(name string, keep func(schema, table string) bool) SchemaInfo {
+	info := SchemaInfo{Name: name}
+
+	children := make(map[string][]Partition)
+	for child, p := range d.partitions {
+		part := Partition{Bound: p.bound}
+		part.Schema, part.Name, _ = strings.Cut(child, ".")
+		if t := d.tables[child]; t != nil {
+			part.PartitionStrategy, part.PartitionKey = t.PartitionStrategy, t.PartitionKey
+		}
+		children[p.parent] = append(children[p.parent], part)
+	}
+	for _, parts := range children {
+		slices.SortFunc(parts, func(a, b Partition) int { return cmp.Compare(a.Name, b.Name) })
+	}
+
+	for qualified, t := range d.tables {
+		if t.Schema != name || !keep(t.Schema, t.Name) {
+			continue
+		}
+		if _, ok := d.partitions[qualified]; ok {
+			continue
+		}
+		table := *t
+		table.Columns = slices.Clone(t.Columns)
+		for i := range table.Columns {
+			table.Columns[i].Type, table.Columns[i].UDTName = d.columnType(table.Columns[i].Type)
+		}
+		setFKRefs(&table)
+		if table.PartitionStrategy != "" {
+			table.Partitions = partitionTree(children, qualified)
+		}
+		table.Description, table.Tags = parseTags(table.Description)
+		info.Tables = append(info.Tables, table)
+	}
+
+	for qualified, v := range d.views {
+		if v.Schema != name {
+			continue
+		}
+		view := *v
+		view.Columns = d.inferColumnTypes(v.Definition, v.Columns)
+		view.DependsOn = d.dependencies(qualified, v.Definition)
+		view.Description, view.Tags = parseTags(view.Description)
+		info.Views = append(info.Views, view)
+	}
+
+	for qualified, mv := range d.matviews {
+		if mv.Schema != name {
+			continue
+		}
+		view := *mv
+		view.Columns = d.inferColumnTypes(mv.Definition, mv.Columns)
+		view.DependsOn = d.dependencies(qualified, mv.Definition)
+		view.Description, view.Tags = parseTags(view.Description)
+		info.MaterializedViews = append(info.MaterializedViews, view)
+	}
+	attachRefreshes(info.MaterializedViews, nil, d.refreshFunctions())
+
+	for _, seq := range d.sequences {
+		if seq.Schema == name {
+			info.Sequences = append(info.Sequences, *seq)
+		}
+	}
+
+	for _, trig := range d.triggers {
+		if trig.Schema != name {
+			continue
+		}
+		qualified := trig.Schema + "." + trig.Table
+		if _, ok := d.partitions[qualified]; ok {
+			continue
+		}
+		if d.tables[qualified] != nil && !keep(trig.Schema, trig.Table) {
+			continue
+		}
+		info.Triggers = append(info.Triggers, trig)
+	}
+
+	for _, fn := range d.functions {
+		if fn.Schema != name {
+			continue
+		}
+		f := fn.Function
+		f.Description, f.Tags = parseTags(f.Description)
+		f.ReturnColumns = d.returnColumns(f)
+		info.Functions = append(info.Functions, f)
+	}
+
+	for _, t := range d.types {
+		if t.Schema != name {
+			continue
+		}
+		ct := *t
+		var comment string
+		comment, ct.Tags = parseTags(ct.Description)
+		ct.Description = comment
+		if ct.Kind == "enum" {
+			ct.Description, ct.ValueDescriptions = parseEnumComment(comment, ct.Values)
+		}
+		info.Types = append(info.Types, ct)
+	}
+
+	for _, w := range d.warnings[name] {
+		if !d.relations[name+"."+w.Object] || keep(name, w.Object) {
+			info.Warnings = append(info.Warnings, w)
+		}
+	}
+	slices.SortFunc(info.Warnings, func(a, b Warning) int {
+		return cmp.Or(cmp.Compare(a.Object, b.Object), cmp.Compare(a.Message, b.Message))
+	})
+
+	deriveColumnLineage(&info)
+	extractTerms(&info)
+	sortSchema(&info)
+	return info
+}
+
+// returnColumns lists the row a function returns: the columns of a
+// returned table, view or composite type, or its OUT and TABLE
+// parameters.
+func (d *dumpModel) returnColumns(fn Function) []Column {
+	ret := strings.TrimPrefix(fn.ReturnType, "SETOF ")
+	var columns []Column
+	switch {
+	case d.tables[ret] != nil:
+		columns = d.tables[ret].Columns
+	case d.views[ret] != nil:
+		columns = d.views[ret].Columns
+	case d.types[ret] != nil:
+		columns = d.types[ret].Attributes
+	default:
+		return outputColumns(fn.Args)
+	}
+	result := make([]Column, len(columns))
+	for i, col := range columns {
+		result[i] = Column{Name: col.Name, Position: col.Position, Type: col.Type, Nullable: true, Description: col.Description}
+	}
+	return result
+}
+
+// dependencies lists the relations a view's query names, which pg_dump
+// always schema-qualifies.
+func (d *dumpModel) dependencies(self, definition string) []string {
+	statements, _ := lexDump(definition)
+	var deps []string
+	for _, toks := range statements {
+		for k := 0; k+2 < len(toks); k++ {
+			if !toks[k].isName() || !toks[k+1].punct(".") || !toks[k+2].isName() {
+				continue
+			}
+			name := toks[k].value + "." + toks[k+2].value
+			if name != self && d.relations[name] && !slices.Contains(deps, name) {
+				deps = append(deps, name)
+			}
+		}
+	}
+	return deps
+}
+
+// inferColumnTypes types a view's columns where its query makes the type
+// plain: a cast at the end of the expression, or a column of a relation
+// it reads from.
+func (d *dumpModel) inferColumnTypes(definition string, columns []Column) []Column {
+	columns = slices.Clone(columns)
+	statements, _ := lexDump(definition)
+	if len(statements) == 0 {
+		return columns
+	}
+	q := &dumpStmt{src: definition, toks: statements[0]}
+	if !q.keyword("SELECT") {
+		return columns
+	}
+	if q.keyword("DISTINCT") && q.keyword("ON") {
+		q.group()
+	}
+	start := q.until(anyOf("FROM", "WHERE", "GROUP", "HAVING", "WINDOW", "UNION", "INTERSECT", "EXCEPT", "ORDER", "LIMIT", "OFFSET"))
+	targets := q.split(start, q.i)
+	if len(targets) != len(columns) {
+		return columns
+	}
+
+	var sources []*Table
+	for _, dep := range d.dependencies("", definition) {
+		if t := d.tables[dep]; t != nil {
+			sources = append(sources, t)
+		}
+	}
+
+	for i, target := range targets {
+		end := target[1]
+		if end-target[0] >= 3 && q.toks[end-2].is("AS") {
+			end -= 2
+		}
+		var raw string
+		if cast := lastCast(q.toks[target[0]:end]); cast >= 0 {
+			raw = q.text(target[0]+cast+1, end)
+		} else if last := q.toks[end-1]; last.isName() && (end-1 == target[0] || q.toks[end-2].punct(".")) {
+			for _, t := range sources {
+				if col := tableColumn(t, last.value); col != nil {
+					raw = col.Type
+					break
+				}
+			}
+		}
+		if raw != "" {
+			// Like fetchViewColumns, only data_type is set.
+			columns[i].Type, _ = d.columnType(raw)
+		}
+	}
+	return columns
+}
+
+// lastCast returns the index of a top-level :: whose type runs to the end
+// of toks, or -1.
+func lastCast(toks []dumpToken) int {
+	depth := 0
+	found := -1
+	for k, t := range toks {
+		switch {
+		case t.punct("(") || t.punct("["):
+			depth++
+		case t.punct(")") || t.punct("]"):
+			depth--
+		case depth == 0 && t.kind == tokOp:
+			found = -1
+			if t.text == "::" {
+				found = k
+			}
+		}
+	}
+	return found
+}
+
+// builtinTypes maps the pg_catalog types information_schema names by
+// their SQL spelling to their internal names.
+var builtinTypes = map[string]string{
+	"smallint":                    "int2",
+	"integer":                     "int4",
+	"bigint":                      "int8",
+	"real":                        "float4",
+	"double precision":            "float8",
+	"numeric":                     "numeric",
+	"boolean":                     "bool",
+	"text":                        "text",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	`"char"`:                      "char",
+	"bytea":                       "bytea",
+	"date":                        "date",
+	"time without time zone":      "time",
+	"time with time zone":         "timetz",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"interval":                    "interval",
+	"uuid":                        "uuid",
+	"json":                        "json",
+	"jsonb":                       "jsonb",
+	"xml":                         "xml",
+	"inet":                        "inet",
+	"cidr":                        "cidr",
+	"macaddr":                     "macaddr",
+	"macaddr8":                    "macaddr8",
+	"money":                       "money",
+	"bit":                         "bit",
+	"bit varying":                 "varbit",
+	"tsvector":                    "tsvector",
+	"tsquery":                     "tsquery",
+	"oid":                         "oid",
+	"name":                        "name",
+}
+
+// isBuiltinType reports whether a type as written is one of builtinTypes.
+func isBuiltinType(t string) bool {
+	_, ok := builtinTypes[baseTypeName(t)]
+	return ok || strings.HasPrefix(baseTypeName(t), "interval ")
+}
+
+// baseTypeName drops array brackets and type modifiers, turning
+// "timestamp(3) with time zone[]" into "timestamp with time zone".
+func baseTypeName(t string) string {
+	var b strings.Builder
+	depth := 0
+	for _, c := range t {
+		switch {
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(c)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// columnType turns a type as pg_dump writes it into information_schema's
+// data_type and udt_name: arrays become ARRAY with an "_" udt_name,
+// domains resolve to their base type, and types outside pg_catalog become
+// USER-DEFINED.
+func (d *dumpModel) columnType(t string) (dataType, udtName string) {
+	t = strings.TrimSpace(t)
+	if strings.HasSuffix(t, "]") {
+		_, udt := d.columnType(t[:strings.IndexByte(t, '[')])
+		return "ARRAY", "_" + udt
+	}
+
+	name := baseTypeName(t)
+	if udt, ok := builtinTypes[name]; ok {
+		return name, udt
+	}
+	if strings.HasPrefix(name, "interval ") {
+		return "interval", "interval"
+	}
+
+	statements, _ := lexDump(name)
+	var parts []string
+	if len(statements) > 0 {
+		parts = (&dumpStmt{src: name, toks: statements[0]}).names()
+	}
+	switch len(parts) {
+	case 0:
+		return name, name
+	case 1:
+		return parts[0], parts[0]
+	}
+	schema, typ := parts[len(parts)-2], parts[len(parts)-1]
+	if base, ok := d.domains[schema+"."+typ]; ok {
+		return d.columnType(base)
+	}
+	if schema == "pg_catalog" {
+		return typ, typ
+	}
+	return "USER-DEFINED", typ
+}
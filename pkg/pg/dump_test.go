@@ -0,0 +1,438 @@
+package pg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleDump = `--
+-- PostgreSQL database dump
+--
+
+\restrict abc123
+
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public;
+
+COMMENT ON EXTENSION pgcrypto IS 'cryptographic functions';
+
+CREATE TYPE public.status AS ENUM (
+    'active',
+    'banned'
+);
+
+COMMENT ON TYPE public.status IS 'Account state.
+active: can sign in';
+
+CREATE TYPE public.address AS (
+	street text,
+	zip character varying(10)
+);
+
+CREATE DOMAIN public.email AS text
+	CONSTRAINT email_check CHECK ((VALUE ~~ '%@%'::text));
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+  NEW.updated_at := now(); -- keep; this is not a statement end
+  RETURN NEW;
+END;
+$$;
+
+CREATE FUNCTION public.add(a integer, b integer DEFAULT 1) RETURNS integer
+    LANGUAGE sql IMMUTABLE
+    BEGIN ATOMIC
+ SELECT (add.a + add.b);
+END;
+
+COMMENT ON FUNCTION public.add(a integer, b integer) IS 'Adds. @tag api';
+
+CREATE FUNCTION public.active_users() RETURNS SETOF public.users
+    LANGUAGE sql STABLE SECURITY DEFINER COST 500
+    AS $$ SELECT * FROM public.users WHERE status = 'active' $$;
+
+CREATE FUNCTION public.stats(OUT total bigint, OUT banned bigint) RETURNS record
+    LANGUAGE sql
+    AS $$ SELECT 1, 2 $$;
+
+CREATE PROCEDURE public.cleanup()
+    LANGUAGE sql
+    AS $$ DELETE FROM public.users $$;
+
+COMMENT ON PROCEDURE public.cleanup() IS 'Removes every user.';
+
+CREATE TABLE public.users (
+    id integer NOT NULL,
+    email public.email NOT NULL,
+    status public.status DEFAULT 'active'::public.status NOT NULL,
+    tags text[],
+    created_at timestamp(3) with time zone DEFAULT now() NOT NULL,
+    "Display Name" character varying(100) COLLATE pg_catalog."C",
+    CONSTRAINT users_email_check CHECK ((length((email)::text) > 3))
+);
+
+COMMENT ON TABLE public.users IS E'People who sign in.\n@term SKU: stock keeping unit';
+
+COMMENT ON COLUMN public.users.email IS 'Login; unique.';
+
+CREATE SEQUENCE public.users_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+
+CREATE TABLE public.orders (
+    id bigint NOT NULL,
+    user_id integer,
+    total numeric(10,2)
+)
+PARTITION BY RANGE (id);
+
+ALTER TABLE public.orders ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.orders_id_seq
+    START WITH 100
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+CREATE TABLE public.orders_2024 (
+    id bigint NOT NULL,
+    user_id integer,
+    total numeric(10,2)
+);
+
+ALTER TABLE ONLY public.orders ATTACH PARTITION public.orders_2024 FOR VALUES FROM (0) TO (1000);
+
+CREATE FOREIGN TABLE public.remote (
+    id integer
+)
+SERVER other;
+
+CREATE VIEW public.active AS
+ SELECT u.id,
+    u.email,
+    count(*) AS n,
+    NULL::text AS note
+   FROM (public.users u
+     JOIN public.orders o ON ((o.user_id = u.id)))
+  WHERE (u.status = 'active'::public.status)
+  GROUP BY u.id, u.email;
+
+CREATE MATERIALIZED VIEW public.totals AS
+ SELECT sum(total) AS total
+   FROM public.orders
+  WITH NO DATA;
+
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+CREATE INDEX users_lower_email_idx ON public.users USING btree (lower((email)::text));
+
+CREATE INDEX users_status_idx ON public.users USING btree (status, created_at DESC) INCLUDE (id) WHERE (status <> 'banned'::public.status);
+
+CREATE TRIGGER users_touch BEFORE INSERT OR UPDATE OF email ON public.users FOR EACH ROW WHEN ((new.email IS NOT NULL)) EXECUTE FUNCTION public.touch();
+
+CREATE POLICY own_rows ON public.users USING ((id = 1));
+
+CREATE POLICY safe_updates ON public.users AS RESTRICTIVE FOR UPDATE TO app_user, "Auditors" USING ((id > 0)) WITH CHECK ((status <> 'banned'::public.status));
+
+ALTER TABLE public.users ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE audit.log (
+    id integer
+);
+
+--
+-- PostgreSQL database dump complete
+--
+`
+
+func TestParseDump(t *testing.T) {
+	db, err := ParseDump(strings.NewReader(sampleDump), []string{"public"}, FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []Extension{{Name: "pgcrypto", Schema: "public", Description: "cryptographic functions"}}; !reflect.DeepEqual(db.Extensions, want) {
+		t.Errorf("extensions = %+v, want %+v", db.Extensions, want)
+	}
+	if len(db.Schemas) != 1 {
+		t.Fatalf("got %d schemas, want 1", len(db.Schemas))
+	}
+	s := db.Schemas[0]
+
+	var names []string
+	for _, table := range s.Tables {
+		names = append(names, table.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"orders", "users"}) {
+		t.Fatalf("tables = %v, want orders and users (partitions and other schemas left out)", names)
+	}
+	orders, users := s.Tables[0], s.Tables[1]
+
+	if users.Description != "People who sign in." || !reflect.DeepEqual(users.Terms, []Term{{"SKU", "stock keeping unit"}}) {
+		t.Errorf("users description = %q, terms %v", users.Description, users.Terms)
+	}
+
+	wantColumns := []Column{
+		{Name: "id", Position: 1, Type: "integer", UDTName: "int4", IsPK: true, Default: "nextval('public.users_id_seq'::regclass)"},
+		{Name: "email", Position: 2, Type: "text", UDTName: "text", IsUnique: true, Description: "Login; unique."},
+		{Name: "status", Position: 3, Type: "USER-DEFINED", UDTName: "status", Default: "'active'::public.status"},
+		{Name: "tags", Position: 4, Type: "ARRAY", UDTName: "_text", Nullable: true},
+		{Name: "created_at", Position: 5, Type: "timestamp with time zone", UDTName: "timestamptz", Default: "now()"},
+		{Name: "Display Name", Position: 6, Type: "character varying", UDTName: "varchar", Nullable: true},
+	}
+	if !reflect.DeepEqual(users.Columns, wantColumns) {
+		t.Errorf("users columns:\n got %+v\nwant %+v", users.Columns, wantColumns)
+	}
+
+	if want := []CheckConstraint{{Name: "users_email_check", Columns: []string{"email"}, Definition: "CHECK ((length((email)::text) > 3))"}}; !reflect.DeepEqual(users.Checks, want) {
+		t.Errorf("checks = %+v, want %+v", users.Checks, want)
+	}
+	wantPolicies := []Policy{
+		{Name: "own_rows", Command: "ALL", Roles: []string{"public"}, Using: "(id = 1)"},
+		{Name: "safe_updates", Command: "UPDATE", Restrictive: true, Roles: []string{"Auditors", "app_user"}, Using: "(id > 0)", WithCheck: "(status <> 'banned'::public.status)"},
+	}
+	if !users.RowSecurity || users.ForceRowSecurity || !reflect.DeepEqual(users.Policies, wantPolicies) {
+		t.Errorf("row security = %v, forced %v, policies:\n got %+v\nwant %+v", users.RowSecurity, users.ForceRowSecurity, users.Policies, wantPolicies)
+	}
+
+	wantIndexes := []Index{
+		{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+		{Name: "users_lower_email_idx", Columns: []string{"lower((email)::text)"}},
+		{Name: "users_pkey", Columns: []string{"id"}, IsUnique: true, IsPrimary: true},
+		{Name: "users_status_idx", Columns: []string{"status", "created_at", "id"}, Predicate: "(status <> 'banned'::public.status)"},
+	}
+	if !reflect.DeepEqual(users.Indexes, wantIndexes) {
+		t.Errorf("indexes:\n got %+v\nwant %+v", users.Indexes, wantIndexes)
+	}
+
+	if orders.PartitionStrategy != "range" || orders.PartitionKey != "id" {
+		t.Errorf("orders partitioning = %q %q", orders.PartitionStrategy, orders.PartitionKey)
+	}
+	if want := []Partition{{Schema: "public", Name: "orders_2024", Bound: "FOR VALUES FROM (0) TO (1000)"}}; !reflect.DeepEqual(orders.Partitions, want) {
+		t.Errorf("partitions = %+v, want %+v", orders.Partitions, want)
+	}
+	if orders.Columns[0].Identity != "ALWAYS" {
+		t.Errorf("orders.id identity = %q", orders.Columns[0].Identity)
+	}
+	userID := orders.Columns[1]
+	if userID.FKRef != "public.users.id" || userID.FKOnDelete != "CASCADE" {
+		t.Errorf("orders.user_id FK = %q on delete %q", userID.FKRef, userID.FKOnDelete)
+	}
+
+	wantSequences := []Sequence{
+		{Schema: "public", Name: "orders_id_seq", DataType: "bigint", Start: 100, Min: 1, Max: 9223372036854775807, Increment: 1, Cache: 1, OwnerTable: "orders", OwnerColumn: "id", Identity: true},
+		{Schema: "public", Name: "users_id_seq", DataType: "integer", Start: 1, Min: 1, Max: 2147483647, Increment: 1, Cache: 1, OwnerTable: "users", OwnerColumn: "id"},
+	}
+	if !reflect.DeepEqual(s.Sequences, wantSequences) {
+		t.Errorf("sequences:\n got %+v\nwant %+v", s.Sequences, wantSequences)
+	}
+
+	if len(s.Views) != 1 {
+		t.Fatalf("got %d views, want 1", len(s.Views))
+	}
+	view := s.Views[0]
+	wantViewColumns := []Column{
+		{Name: "id", Position: 1, Type: "integer", Nullable: true, DerivedFrom: []string{"public.users.id"}},
+		{Name: "email", Position: 2, Type: "text", Nullable: true, DerivedFrom: []string{"public.users.email"}},
+		{Name: "n", Position: 3, Nullable: true},
+		{Name: "note", Position: 4, Type: "text", Nullable: true},
+	}
+	if !columnLineage {
+		for i := range wantViewColumns {
+			wantViewColumns[i].DerivedFrom = nil
+		}
+	}
+	if !reflect.DeepEqual(view.Columns, wantViewColumns) {
+		t.Errorf("view columns:\n got %+v\nwant %+v", view.Columns, wantViewColumns)
+	}
+	if !reflect.DeepEqual(view.DependsOn, []string{"public.orders", "public.users"}) {
+		t.Errorf("view depends on %v", view.DependsOn)
+	}
+	if !strings.HasPrefix(view.Definition, " SELECT u.id,") || !strings.HasSuffix(view.Definition, "GROUP BY u.id, u.email;") {
+		t.Errorf("view definition = %q", view.Definition)
+	}
+
+	if len(s.MaterializedViews) != 1 || strings.Contains(s.MaterializedViews[0].Definition, "WITH NO DATA") {
+		t.Errorf("materialized views = %+v", s.MaterializedViews)
+	}
+
+	want := []Trigger{{
+		Schema: "public", Table: "users", Name: "users_touch", Event: "INSERT OR UPDATE", Timing: "BEFORE", Function: "touch",
+		Level: "ROW", Columns: []string{"email"}, When: "(new.email IS NOT NULL)",
+		Definition: "CREATE TRIGGER users_touch BEFORE INSERT OR UPDATE OF email ON public.users FOR EACH ROW WHEN ((new.email IS NOT NULL)) EXECUTE FUNCTION public.touch()",
+	}}
+	if !reflect.DeepEqual(s.Triggers, want) {
+		t.Errorf("triggers = %+v, want %+v", s.Triggers, want)
+	}
+	if clause := s.Triggers[0].EventClause(); clause != "INSERT OR UPDATE OF email" {
+		t.Errorf("EventClause() = %q", clause)
+	}
+
+	functions := make(map[string]Function)
+	for _, fn := range s.Functions {
+		functions[fn.Name] = fn
+	}
+	if len(functions) != 5 {
+		t.Errorf("got functions %v, want active_users, add, cleanup, stats and touch", s.Functions)
+	}
+	add := functions["add"]
+	if add.Arguments != "a integer, b integer DEFAULT 1" || add.ReturnType != "integer" || add.Description != "Adds." || !reflect.DeepEqual(add.Tags, []string{"api"}) {
+		t.Errorf("add = %+v", add)
+	}
+	if want := []Argument{{Name: "a", Type: "integer", Mode: "IN"}, {Name: "b", Type: "integer", Mode: "IN", Default: "1"}}; !reflect.DeepEqual(add.Args, want) {
+		t.Errorf("add args = %+v, want %+v", add.Args, want)
+	}
+	if add.Language != "sql" || add.Volatility != "immutable" || add.SecurityDefiner || add.Cost != 100 {
+		t.Errorf("add attributes = %+v", add)
+	}
+	if fn := functions["active_users"]; fn.Volatility != "stable" || !fn.SecurityDefiner || fn.Cost != 500 {
+		t.Errorf("active_users attributes = %+v", fn)
+	}
+	if fn := functions["active_users"]; !fn.ReturnsSet || len(fn.ReturnColumns) != 6 || fn.ReturnColumns[1].Type != "public.email" {
+		t.Errorf("active_users = %+v", fn)
+	}
+	if fn := functions["stats"]; len(fn.ReturnColumns) != 2 {
+		t.Errorf("stats return columns = %+v", fn.ReturnColumns)
+	}
+	if fn := functions["cleanup"]; fn.Kind != "procedure" || fn.ReturnType != "" || fn.Volatility != "" || fn.Description != "Removes every user." {
+		t.Errorf("cleanup = %+v", fn)
+	}
+
+	if len(s.Types) != 2 {
+		t.Fatalf("got %d types, want 2", len(s.Types))
+	}
+	status, address := s.Types[0], s.Types[1]
+	if status.Description != "Account state." || status.ValueDescriptions["active"] != "can sign in" {
+		t.Errorf("status = %+v", status)
+	}
+	if !reflect.DeepEqual(address.Values, []string{"street text", "zip character varying(10)"}) {
+		t.Errorf("address values = %v", address.Values)
+	}
+
+	wantWarnings := []Warning{
+		{Kind: WarningSkipped, Object: "remote", Message: "foreign table is not documented"},
+	}
+	if !reflect.DeepEqual(s.Warnings, wantWarnings) {
+		t.Errorf("warnings:\n got %+v\nwant %+v", s.Warnings, wantWarnings)
+	}
+}
+
+func TestParseDumpFunctionBodies(t *testing.T) {
+	db, err := ParseDump(strings.NewReader(sampleDump), []string{"public"}, FetchOptions{FunctionBodies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range db.Schemas[0].Functions {
+		if fn.Name != "add" {
+			continue
+		}
+		expected := "CREATE FUNCTION public.add(a integer, b integer DEFAULT 1) RETURNS integer\n    LANGUAGE sql IMMUTABLE\n    BEGIN ATOMIC\n SELECT (add.a + add.b);\nEND"
+		if fn.Definition != expected {
+			t.Errorf("add definition = %q, want %q", fn.Definition, expected)
+		}
+		return
+	}
+	t.Error("function add not found")
+}
+
+func TestParseDumpTransitionTables(t *testing.T) {
+	dump := `CREATE TABLE public.orders (id integer);
+
+CREATE TRIGGER orders_audit AFTER UPDATE ON public.orders REFERENCING OLD TABLE AS old_orders NEW TABLE AS new_orders FOR EACH STATEMENT EXECUTE FUNCTION public.audit_orders();
+`
+	db, err := ParseDump(strings.NewReader(dump), []string{"public"}, FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Trigger{{
+		Schema: "public", Table: "orders", Name: "orders_audit", Event: "UPDATE", Timing: "AFTER", Function: "audit_orders",
+		Level: "STATEMENT", OldTable: "old_orders", NewTable: "new_orders",
+		Definition: "CREATE TRIGGER orders_audit AFTER UPDATE ON public.orders REFERENCING OLD TABLE AS old_orders NEW TABLE AS new_orders FOR EACH STATEMENT EXECUTE FUNCTION public.audit_orders()",
+	}}
+	if !reflect.DeepEqual(db.Schemas[0].Triggers, want) {
+		t.Errorf("triggers = %+v, want %+v", db.Schemas[0].Triggers, want)
+	}
+	if ref := want[0].Referencing(); ref != "REFERENCING OLD TABLE AS old_orders NEW TABLE AS new_orders" {
+		t.Errorf("Referencing() = %q", ref)
+	}
+}
+
+func TestParseDumpMatviewRefreshes(t *testing.T) {
+	dump := `CREATE TABLE public.orders (id integer, total numeric);
+
+CREATE FUNCTION public.refresh_totals() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$BEGIN REFRESH MATERIALIZED VIEW CONCURRENTLY public.totals; RETURN NULL; END$$;
+
+CREATE MATERIALIZED VIEW public.totals AS
+ SELECT sum(total) AS total FROM public.orders
+  WITH NO DATA;
+
+CREATE TRIGGER orders_refresh AFTER INSERT ON public.orders FOR EACH STATEMENT EXECUTE FUNCTION public.refresh_totals();
+`
+	db, err := ParseDump(strings.NewReader(dump), []string{"public"}, FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Refresh{{Kind: "trigger", Name: "public.orders.orders_refresh", Function: "public.refresh_totals", Concurrently: true}}
+	if got := db.Schemas[0].MaterializedViews[0].Refreshes; !reflect.DeepEqual(got, want) {
+		t.Errorf("refreshes = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDumpExpressionIndexes(t *testing.T) {
+	dump := `CREATE TABLE public.events (id integer, data jsonb, name text);
+
+CREATE INDEX events_keys_idx ON public.events USING btree (((data ->> 'kind'::text)) DESC, public.normalize(name) text_pattern_ops, id);
+`
+	db, err := ParseDump(strings.NewReader(dump), []string{"public"}, FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"((data ->> 'kind'::text))", "public.normalize(name)", "id"}
+	if got := db.Schemas[0].Tables[0].Indexes[0].Columns; !reflect.DeepEqual(got, want) {
+		t.Errorf("columns = %q, want %q", got, want)
+	}
+}
+
+func TestParseDumpFilters(t *testing.T) {
+	db, err := ParseDump(strings.NewReader(sampleDump), []string{"public", "audit"}, FetchOptions{ExcludeTables: []string{"users"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, audit := db.Schemas[0], db.Schemas[1]
+	if len(public.Tables) != 1 || public.Tables[0].Name != "orders" {
+		t.Errorf("public tables = %+v, want only orders", public.Tables)
+	}
+	if len(public.Triggers) != 0 {
+		t.Errorf("triggers of excluded tables should be dropped, got %+v", public.Triggers)
+	}
+	if len(audit.Tables) != 1 || audit.Tables[0].Name != "log" {
+		t.Errorf("audit tables = %+v", audit.Tables)
+	}
+
+	if _, err := ParseDump(strings.NewReader("CREATE TABLE t (a text DEFAULT 'x);"), []string{"public"}, FetchOptions{}); err == nil {
+		t.Error("expected error for an unterminated string")
+	}
+}
@@ -0,0 +1,431 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+)
+
+type dumpTokenKind int
+
+const (
+	tokIdent       dumpTokenKind = iota // unquoted identifier or keyword
+	tokQuotedIdent                      // "identifier"
+	tokString                           // 'literal', E'literal' or $$literal$$
+	tokNumber
+	tokPunct // ( ) [ ] , . ;
+	tokOp    // any other operator, including ::
+)
+
+// dumpToken is one lexical token of a dump. text is the token as written;
+// value is an identifier folded to lower case, or a quoted identifier or
+// string with its quotes removed.
+type dumpToken struct {
+	kind     dumpTokenKind
+	text     string
+	value    string
+	pos, end int
+}
+
+// is reports whether t is the unquoted keyword word, ignoring case.
+func (t dumpToken) is(word string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
+
+func (t dumpToken) punct(p string) bool {
+	return t.kind == tokPunct && t.text == p
+}
+
+// isName reports whether t can be an object name.
+func (t dumpToken) isName() bool {
+	return t.kind == tokIdent || t.kind == tokQuotedIdent
+}
+
+// lexDump splits SQL into statements of tokens, dropping comments and
+// psql meta-commands such as \connect. Statement tokens keep their offsets
+// into src so the original text of a clause can be recovered.
+func lexDump(src string) ([][]dumpToken, error) {
+	var statements [][]dumpToken
+	var current []dumpToken
+	// A BEGIN ATOMIC function body holds statements of its own; caseDepth
+	// tells its END from those of CASE expressions.
+	atomic, caseDepth := false, 0
+	line := func(pos int) int { return strings.Count(src[:pos], "\n") + 1 }
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+
+		case strings.HasPrefix(src[i:], "--"):
+			i = skipLine(src, i)
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end, ok := skipBlockComment(src, i)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated comment", line(i))
+			}
+			i = end
+
+		case c == '\\' && len(current) == 0:
+			i = skipLine(src, i)
+
+		case c == ';' && atomic:
+			current = append(current, dumpToken{kind: tokPunct, text: ";", value: ";", pos: i, end: i + 1})
+			i++
+
+		case c == ';':
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+			i++
+
+		case c == '\'':
+			start := i
+			escapes := false
+			// E'...' strings are lexed as an identifier E followed by a
+			// string; merge them.
+			if n := len(current); n > 0 && current[n-1].kind == tokIdent && current[n-1].end == i && strings.EqualFold(current[n-1].text, "E") {
+				start = current[n-1].pos
+				escapes = true
+				current = current[:n-1]
+			}
+			value, end, ok := lexString(src, i, escapes)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated string", line(i))
+			}
+			current = append(current, dumpToken{kind: tokString, text: src[start:end], value: value, pos: start, end: end})
+			i = end
+
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for {
+				k := strings.IndexByte(src[j:], '"')
+				if k < 0 {
+					return nil, fmt.Errorf("line %d: unterminated quoted identifier", line(i))
+				}
+				b.WriteString(src[j : j+k])
+				j += k + 1
+				if j < len(src) && src[j] == '"' {
+					b.WriteByte('"')
+					j++
+					continue
+				}
+				break
+			}
+			current = append(current, dumpToken{kind: tokQuotedIdent, text: src[i:j], value: b.String(), pos: i, end: j})
+			i = j
+
+		case c == '$' && dollarTag(src[i:]) != "":
+			tag := dollarTag(src[i:])
+			k := strings.Index(src[i+len(tag):], tag)
+			if k < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar-quoted string", line(i))
+			}
+			body := src[i+len(tag) : i+len(tag)+k]
+			end := i + len(tag) + k + len(tag)
+			current = append(current, dumpToken{kind: tokString, text: src[i:end], value: body, pos: i, end: end})
+			i = end
+
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j]) || src[j] == '$') {
+				j++
+			}
+			tok := dumpToken{kind: tokIdent, text: src[i:j], value: strings.ToLower(src[i:j]), pos: i, end: j}
+			switch {
+			case tok.is("ATOMIC") && len(current) > 0 && current[len(current)-1].is("BEGIN"):
+				atomic = true
+			case atomic && tok.is("CASE"):
+				caseDepth++
+			case atomic && tok.is("END") && caseDepth > 0:
+				caseDepth--
+			case atomic && tok.is("END"):
+				atomic = false
+			}
+			current = append(current, tok)
+			i = j
+
+		case isDigit(c):
+			j := i + 1
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			if j < len(src) && (src[j] == 'e' || src[j] == 'E') {
+				k := j + 1
+				if k < len(src) && (src[k] == '+' || src[k] == '-') {
+					k++
+				}
+				if k < len(src) && isDigit(src[k]) {
+					for j = k; j < len(src) && isDigit(src[j]); j++ {
+					}
+				}
+			}
+			current = append(current, dumpToken{kind: tokNumber, text: src[i:j], value: src[i:j], pos: i, end: j})
+			i = j
+
+		case strings.IndexByte("()[],.", c) >= 0:
+			current = append(current, dumpToken{kind: tokPunct, text: src[i : i+1], value: src[i : i+1], pos: i, end: i + 1})
+			i++
+
+		default:
+			j := i + 1
+			if c == ':' && j < len(src) && src[j] == ':' {
+				j++
+			} else {
+				for j < len(src) && strings.IndexByte("+-*/<>=~!@#%^&|`?:$", src[j]) >= 0 &&
+					!strings.HasPrefix(src[j:], "--") && !strings.HasPrefix(src[j:], "/*") {
+					j++
+				}
+			}
+			current = append(current, dumpToken{kind: tokOp, text: src[i:j], value: src[i:j], pos: i, end: j})
+			i = j
+		}
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements, nil
+}
+
+func skipLine(src string, i int) int {
+	if k := strings.IndexByte(src[i:], '\n'); k >= 0 {
+		return i + k + 1
+	}
+	return len(src)
+}
+
+// skipBlockComment returns the offset after the comment starting at i,
+// which may nest.
+func skipBlockComment(src string, i int) (int, bool) {
+	depth := 0
+	for i < len(src)-1 {
+		switch {
+		case src[i] == '/' && src[i+1] == '*':
+			depth++
+			i += 2
+		case src[i] == '*' && src[i+1] == '/':
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, true
+			}
+		default:
+			i++
+		}
+	}
+	return 0, false
+}
+
+// lexString reads the literal whose opening quote is at i, returning its
+// value and the offset after the closing quote.
+func lexString(src string, i int, escapes bool) (string, int, bool) {
+	var b strings.Builder
+	for j := i + 1; j < len(src); j++ {
+		switch c := src[j]; {
+		case c == '\'' && j+1 < len(src) && src[j+1] == '\'':
+			b.WriteByte('\'')
+			j++
+		case c == '\'':
+			return b.String(), j + 1, true
+		case c == '\\' && escapes && j+1 < len(src):
+			j++
+			switch src[j] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(src[j])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// dollarTag returns the $tag$ opening s, or "" if s does not start one.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '$':
+			return s[:j+1]
+		case isIdentStart(c) || (j > 1 && isDigit(c)):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// dumpStmt walks the tokens of one statement.
+type dumpStmt struct {
+	src  string
+	toks []dumpToken
+	i    int
+}
+
+func (s *dumpStmt) done() bool {
+	return s.i >= len(s.toks)
+}
+
+func (s *dumpStmt) peek() dumpToken {
+	if s.done() {
+		return dumpToken{kind: -1}
+	}
+	return s.toks[s.i]
+}
+
+func (s *dumpStmt) next() dumpToken {
+	t := s.peek()
+	s.i++
+	return t
+}
+
+// keyword consumes words if they come next, ignoring case.
+func (s *dumpStmt) keyword(words ...string) bool {
+	if s.i+len(words) > len(s.toks) {
+		return false
+	}
+	for k, w := range words {
+		if !s.toks[s.i+k].is(w) {
+			return false
+		}
+	}
+	s.i += len(words)
+	return true
+}
+
+// names reads a dotted name such as schema.table.column.
+func (s *dumpStmt) names() []string {
+	var parts []string
+	for s.peek().isName() {
+		parts = append(parts, s.next().value)
+		if !s.peek().punct(".") {
+			break
+		}
+		s.i++
+	}
+	return parts
+}
+
+// qualifiedName reads "schema.name" or "name".
+func (s *dumpStmt) qualifiedName() (schema, name string) {
+	parts := s.names()
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// text returns the source spanning tokens [from, to).
+func (s *dumpStmt) text(from, to int) string {
+	if from >= to || from >= len(s.toks) {
+		return ""
+	}
+	return s.src[s.toks[from].pos:s.toks[to-1].end]
+}
+
+// group consumes a parenthesized group, returning the token range inside
+// it. It returns ok=false if no group starts here.
+func (s *dumpStmt) group() (from, to int, ok bool) {
+	if !s.peek().punct("(") {
+		return 0, 0, false
+	}
+	from = s.i + 1
+	depth := 0
+	for ; s.i < len(s.toks); s.i++ {
+		switch {
+		case s.toks[s.i].punct("(") || s.toks[s.i].punct("["):
+			depth++
+		case s.toks[s.i].punct(")") || s.toks[s.i].punct("]"):
+			depth--
+			if depth == 0 {
+				s.i++
+				return from, s.i - 1, true
+			}
+		}
+	}
+	return from, len(s.toks), true
+}
+
+// until advances to the first token at nesting depth 0 for which stop
+// returns true, or to the end, and returns where it started.
+func (s *dumpStmt) until(stop func(t dumpToken) bool) int {
+	start := s.i
+	depth := 0
+	for ; s.i < len(s.toks); s.i++ {
+		t := s.toks[s.i]
+		if depth == 0 && stop(t) {
+			break
+		}
+		switch {
+		case t.punct("(") || t.punct("["):
+			depth++
+		case t.punct(")") || t.punct("]"):
+			depth--
+		}
+	}
+	return start
+}
+
+// split divides tokens [from, to) at top-level commas.
+func (s *dumpStmt) split(from, to int) [][2]int {
+	var parts [][2]int
+	depth, start := 0, from
+	for k := from; k < to; k++ {
+		t := s.toks[k]
+		switch {
+		case t.punct("(") || t.punct("["):
+			depth++
+		case t.punct(")") || t.punct("]"):
+			depth--
+		case t.punct(",") && depth == 0:
+			parts = append(parts, [2]int{start, k})
+			start = k + 1
+		}
+	}
+	if start < to {
+		parts = append(parts, [2]int{start, to})
+	}
+	return parts
+}
+
+// sub returns a walker over tokens [from, to).
+func (s *dumpStmt) sub(from, to int) *dumpStmt {
+	return &dumpStmt{src: s.src, toks: s.toks[from:to]}
+}
+
+// rest returns the source from the current token to the end.
+func (s *dumpStmt) rest() string {
+	return s.text(s.i, len(s.toks))
+}
+
+// anyOf returns a stop function matching any of the keywords.
+func anyOf(words ...string) func(dumpToken) bool {
+	return func(t dumpToken) bool {
+		for _, w := range words {
+			if t.is(w) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,94 @@
+package pg
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Fetcher introspects a database through a connection pool. It is safe
+// for concurrent use and meant to be kept around for repeated fetches;
+// pgx caches each query's prepared statement per pooled connection, so
+// later fetches skip parsing and planning of the catalog queries.
+type Fetcher struct {
+	pool *pgxpool.Pool
+	opts FetchOptions
+}
+
+// NewFetcher opens a pool for connString. With FetchOptions.ReadOnly,
+// every pooled session is opened with default_transaction_read_only.
+// Close the Fetcher when done.
+func NewFetcher(ctx context.Context, connString string, opts FetchOptions) (*Fetcher, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	config, err := poolConfig(connString, opts)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &Fetcher{pool: pool, opts: opts}, nil
+}
+
+func poolConfig(connString string, opts FetchOptions) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ReadOnly {
+		SetReadOnlyParams(config.ConnConfig.RuntimeParams)
+	}
+	return config, nil
+}
+
+// NewFetcherFromPool wraps an existing pool, which the caller keeps
+// ownership of.
+func NewFetcherFromPool(pool *pgxpool.Pool, opts FetchOptions) *Fetcher {
+	return &Fetcher{pool: pool, opts: opts}
+}
+
+func (f *Fetcher) Close() {
+	f.pool.Close()
+}
+
+// FetchSchemas is like the package-level FetchSchemas but introspects the
+// schemas in parallel, one pooled connection each, at most
+// FetchOptions.MaxConcurrency at a time.
+func (f *Fetcher) FetchSchemas(ctx context.Context, schemas []string) ([]SchemaInfo, error) {
+	result := make([]SchemaInfo, len(schemas))
+	errs := make([]error, len(schemas))
+
+	limit := f.opts.MaxConcurrency
+	if limit == 0 {
+		limit = len(schemas)
+	}
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i, schema := range schemas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			infos, err := FetchSchemas(ctx, f.pool, []string{schema}, f.opts)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			result[i] = infos[0]
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
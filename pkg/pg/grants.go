@@ -0,0 +1,66 @@
+package pg
+
+import "context"
+
+// Grant is the privileges one role holds on a table, column or function,
+// e.g. SELECT and INSERT. Grantee is "PUBLIC" for grants to everyone.
+// Objects without an explicit ACL report the owner's default privileges.
+type Grant struct {
+	Grantee    string   `json:"grantee"`
+	Privileges []string `json:"privileges"`
+	// Column is set for a column-level grant on a table.
+	Column string `json:"column,omitempty"`
+}
+
+// fetchTableGrants returns the table and column grants of each named table
+// in schema, keyed by table name.
+func fetchTableGrants(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]Grant, error) {
+	query := `
+		SELECT table_name, column_name, grantee, privileges FROM (
+			SELECT
+				c.relname::text as table_name,
+				'' as column_name,
+				CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee)::text END as grantee,
+				array_agg(a.privilege_type::text ORDER BY a.privilege_type) as privileges
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			CROSS JOIN LATERAL aclexplode(COALESCE(c.relacl, acldefault('r', c.relowner))) a
+			WHERE n.nspname = $1
+			  AND c.relname = ANY($2)
+			  AND c.relkind IN ('r', 'p')
+			GROUP BY 1, 2, 3
+			UNION ALL
+			SELECT
+				c.relname::text,
+				att.attname::text,
+				CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee)::text END,
+				array_agg(a.privilege_type::text ORDER BY a.privilege_type)
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			JOIN pg_attribute att ON att.attrelid = c.oid AND att.attnum > 0 AND NOT att.attisdropped
+			CROSS JOIN LATERAL aclexplode(att.attacl) a
+			WHERE n.nspname = $1
+			  AND c.relname = ANY($2)
+			  AND c.relkind IN ('r', 'p')
+			GROUP BY 1, 2, 3
+		) g
+		ORDER BY table_name, column_name, grantee`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make(map[string][]Grant)
+	for rows.Next() {
+		var table string
+		var g Grant
+		if err := rows.Scan(&table, &g.Column, &g.Grantee, &g.Privileges); err != nil {
+			return nil, err
+		}
+		grants[table] = append(grants[table], g)
+	}
+
+	return grants, nil
+}
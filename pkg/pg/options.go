@@ -0,0 +1,163 @@
+package pg
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FetchOptions narrows what FetchSchemas introspects.
+type FetchOptions struct {
+	// IncludeTables, when non-empty, keeps only tables matching one of
+	// these patterns; ExcludeTables then drops any that match. Patterns
+	// are globs like "log_*", or regular expressions when prefixed with
+	// "re:". A pattern containing a dot matches "schema.table" instead of
+	// the bare table name. Skipped tables are never queried for columns,
+	// indexes or constraints, and their triggers are left out.
+	IncludeTables []string
+	ExcludeTables []string
+
+	// SequenceValues also reads each sequence's last_value. It changes
+	// with every insert, so it is off by default and left out of
+	// Database.Hash.
+	SequenceValues bool
+
+	// Grants also reads the privileges on tables, columns and functions.
+	Grants bool
+
+	// FunctionBodies also reads each function's and procedure's full
+	// CREATE statement, body included.
+	FunctionBodies bool
+
+	// Sizes also reads each table's disk usage and estimated row count.
+	// Like sequence values they drift constantly and are left out of
+	// Database.Hash.
+	Sizes bool
+
+	// MaxConcurrency caps how many schemas a Fetcher introspects at once;
+	// 0 means all of them.
+	MaxConcurrency int
+	// QueryDelay pauses before every catalog query, trading speed for
+	// less load on a busy server.
+	QueryDelay time.Duration
+	// ReadOnly refuses any statement but SELECT, WITH or SHOW before it
+	// is sent (see ReadOnly). Pair it with a session opened through
+	// ReadOnlyConfig; NewFetcher opens its pool that way itself.
+	ReadOnly bool
+	// QueryComment, when set, is prepended to every catalog query as a
+	// /* comment */ (see Commented).
+	QueryComment string
+}
+
+// Validate reports the first malformed pattern or negative limit.
+func (o FetchOptions) Validate() error {
+	if o.MaxConcurrency < 0 {
+		return errors.New("max concurrency must not be negative")
+	}
+	if o.QueryDelay < 0 {
+		return errors.New("query delay must not be negative")
+	}
+	_, err := o.tableFilter()
+	return err
+}
+
+type tablePattern struct {
+	qualified bool
+	glob      string
+	re        *regexp.Regexp
+}
+
+func parseTablePattern(p string) (tablePattern, error) {
+	if expr, ok := strings.CutPrefix(p, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return tablePattern{}, fmt.Errorf("invalid table pattern %q: %w", p, err)
+		}
+		return tablePattern{qualified: strings.Contains(expr, `\.`), re: re}, nil
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return tablePattern{}, fmt.Errorf("invalid table pattern %q: %w", p, err)
+	}
+	return tablePattern{qualified: strings.Contains(p, "."), glob: p}, nil
+}
+
+func (p tablePattern) match(schema, table string) bool {
+	name := table
+	if p.qualified {
+		name = schema + "." + table
+	}
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	ok, _ := path.Match(p.glob, name)
+	return ok
+}
+
+// tableFilter compiles the patterns into a predicate over tables.
+func (o FetchOptions) tableFilter() (func(schema, table string) bool, error) {
+	var include, exclude []tablePattern
+	for _, p := range o.IncludeTables {
+		tp, err := parseTablePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		include = append(include, tp)
+	}
+	for _, p := range o.ExcludeTables {
+		tp, err := parseTablePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, tp)
+	}
+
+	return func(schema, table string) bool {
+		if len(include) > 0 && !matchAny(include, schema, table) {
+			return false
+		}
+		return !matchAny(exclude, schema, table)
+	}, nil
+}
+
+func matchAny(patterns []tablePattern, schema, table string) bool {
+	for _, p := range patterns {
+		if p.match(schema, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTables applies the table patterns of opts to an already fetched
+// model, such as a loaded snapshot, dropping tables and their triggers.
+func FilterTables(schemas []SchemaInfo, opts FetchOptions) ([]SchemaInfo, error) {
+	keep, err := opts.tableFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SchemaInfo, len(schemas))
+	for i, s := range schemas {
+		dropped := make(map[string]bool)
+		var tables []Table
+		for _, t := range s.Tables {
+			if keep(s.Name, t.Name) {
+				tables = append(tables, t)
+			} else {
+				dropped[t.Name] = true
+			}
+		}
+		var triggers []Trigger
+		for _, trig := range s.Triggers {
+			if !dropped[trig.Table] {
+				triggers = append(triggers, trig)
+			}
+		}
+		s.Tables, s.Triggers = tables, triggers
+		result[i] = s
+	}
+	return result, nil
+}
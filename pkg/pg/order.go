@@ -0,0 +1,163 @@
+package pg
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// sortSchema puts every collection in info into a fixed order, byte-wise
+// by name like the C collation, so output never depends on catalog
+// return order. Sequences whose order is meaningful keep it: columns go
+// by position, and enum labels, composite fields and multi-column index
+// or key columns are left as fetched.
+func sortSchema(info *SchemaInfo) {
+	slices.SortStableFunc(info.Tables, func(a, b Table) int { return cmp.Compare(a.Name, b.Name) })
+	for i := range info.Tables {
+		t := &info.Tables[i]
+		sortColumns(t.Columns)
+		slices.SortStableFunc(t.Indexes, func(a, b Index) int { return cmp.Compare(a.Name, b.Name) })
+		slices.SortStableFunc(t.Checks, func(a, b CheckConstraint) int { return cmp.Compare(a.Name, b.Name) })
+		slices.SortStableFunc(t.ForeignKeys, func(a, b ForeignKey) int { return cmp.Compare(a.Name, b.Name) })
+		slices.SortStableFunc(t.Policies, func(a, b Policy) int { return cmp.Compare(a.Name, b.Name) })
+		for j := range t.Policies {
+			slices.Sort(t.Policies[j].Roles)
+		}
+		slices.Sort(t.Publications)
+	}
+
+	slices.SortStableFunc(info.Views, func(a, b View) int { return cmp.Compare(a.Name, b.Name) })
+	for i := range info.Views {
+		sortColumns(info.Views[i].Columns)
+		slices.Sort(info.Views[i].DependsOn)
+	}
+
+	slices.SortStableFunc(info.MaterializedViews, func(a, b MaterializedView) int { return cmp.Compare(a.Name, b.Name) })
+	for i := range info.MaterializedViews {
+		sortColumns(info.MaterializedViews[i].Columns)
+		slices.Sort(info.MaterializedViews[i].DependsOn)
+	}
+
+	slices.SortStableFunc(info.Sequences, func(a, b Sequence) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortStableFunc(info.Triggers, func(a, b Trigger) int {
+		return cmp.Or(cmp.Compare(a.Table, b.Table), cmp.Compare(a.Name, b.Name))
+	})
+	slices.SortStableFunc(info.Functions, func(a, b Function) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Arguments, b.Arguments))
+	})
+	slices.SortStableFunc(info.Types, func(a, b CustomType) int {
+		return cmp.Or(cmp.Compare(typeKindRank(a.Kind), typeKindRank(b.Kind)), cmp.Compare(a.Name, b.Name))
+	})
+	orderTypeDependencies(info.Types)
+}
+
+func sortColumns(columns []Column) {
+	slices.SortStableFunc(columns, func(a, b Column) int { return cmp.Compare(a.Position, b.Position) })
+}
+
+// typeKindRank lists enums before composites, as the document always has.
+func typeKindRank(kind string) int {
+	switch kind {
+	case "enum":
+		return 0
+	case "composite":
+		return 1
+	}
+	return 2
+}
+
+// orderTypeDependencies moves each composite type after the composites
+// its attributes use, keeping name order where there is a choice. Enums
+// already come first, so they always precede the composites using them.
+func orderTypeDependencies(types []CustomType) {
+	position := make(map[string]int, len(types))
+	for i, t := range types {
+		position[t.Schema+"."+t.Name] = i
+	}
+
+	// dependents[i] lists the types waiting on types[i]; pending[i]
+	// counts the types types[i] still waits on.
+	dependents := make([][]int, len(types))
+	pending := make([]int, len(types))
+	for i, t := range types {
+		seen := make(map[int]bool)
+		for _, attr := range t.Attributes {
+			j, ok := position[ReferencedType(t.Schema, attr.Type)]
+			if !ok || j == i || seen[j] {
+				continue
+			}
+			seen[j] = true
+			dependents[j] = append(dependents[j], i)
+			pending[i]++
+		}
+	}
+
+	var order []int
+	placed := make([]bool, len(types))
+	for len(order) < len(types) {
+		next := -1
+		for i := range types {
+			if !placed[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			// A cycle cannot be created in PostgreSQL, but a snapshot can
+			// hold anything; keep the rest in name order.
+			for i := range types {
+				if !placed[i] {
+					order = append(order, i)
+				}
+			}
+			break
+		}
+		placed[next] = true
+		order = append(order, next)
+		for _, d := range dependents[next] {
+			pending[d]--
+		}
+	}
+
+	sorted := make([]CustomType, len(types))
+	for i, j := range order {
+		sorted[i] = types[j]
+	}
+	copy(types, sorted)
+}
+
+// ReferencedType names the type a column type refers to as
+// "schema.name", as format_type prints it: array brackets and quotes are
+// dropped, and an unqualified name is taken to be in schema. Built-in
+// types resolve to names no documented type has.
+func ReferencedType(schema, typ string) string {
+	typ = strings.TrimSpace(typ)
+	for strings.HasSuffix(typ, "[]") {
+		typ = strings.TrimSpace(strings.TrimSuffix(typ, "[]"))
+	}
+
+	var parts []string
+	var part strings.Builder
+	quoted := false
+	for i := 0; i < len(typ); i++ {
+		c := typ[i]
+		switch {
+		case c == '"' && quoted && i+1 < len(typ) && typ[i+1] == '"':
+			part.WriteByte('"')
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '.' && !quoted:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	parts = append(parts, part.String())
+
+	if len(parts) == 1 {
+		return schema + "." + parts[0]
+	}
+	return parts[len(parts)-2] + "." + parts[len(parts)-1]
+}
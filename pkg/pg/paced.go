@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Paced wraps conn so that every query first waits delay, spreading
+// introspection out on a busy server.
+func Paced(conn Querier, delay time.Duration) Querier {
+	return pacedQuerier{conn: conn, delay: delay}
+}
+
+type pacedQuerier struct {
+	conn  Querier
+	delay time.Duration
+}
+
+func (q pacedQuerier) unwrap() Querier {
+	return q.conn
+}
+
+func (q pacedQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := q.wait(ctx); err != nil {
+		return nil, err
+	}
+	return q.conn.Query(ctx, sql, args...)
+}
+
+func (q pacedQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := q.wait(ctx); err != nil {
+		return errRow{err}
+	}
+	return q.conn.QueryRow(ctx, sql, args...)
+}
+
+func (q pacedQuerier) wait(ctx context.Context) error {
+	t := time.NewTimer(q.delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,1499 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Querier runs catalog queries. It is satisfied by *pgx.Conn, pgx.Tx and
+// *pgxpool.Pool.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Column struct {
+	Name     string `json:"name"`
+	Position int    `json:"position,omitempty"`
+	Type     string `json:"type"`
+	// UDTName is the underlying type name, e.g. "_int4" for an integer
+	// array or the enum's name where Type only says USER-DEFINED.
+	UDTName  string `json:"udt_name,omitempty"`
+	Nullable bool   `json:"nullable"`
+	IsPK     bool   `json:"is_pk,omitempty"`
+	IsUnique bool   `json:"is_unique,omitempty"`
+	// FKRef is the "schema.table.column" this column references, paired
+	// by position within its foreign key constraint.
+	FKRef string `json:"fk_ref,omitempty"`
+	// FKOnDelete and FKOnUpdate are that foreign key's referential
+	// actions, left empty for the default NO ACTION.
+	FKOnDelete string `json:"fk_on_delete,omitempty"`
+	FKOnUpdate string `json:"fk_on_update,omitempty"`
+	Default    string `json:"default,omitempty"`
+	// Identity is "ALWAYS" or "BY DEFAULT" for GENERATED ... AS IDENTITY
+	// columns, which have no Default of their own.
+	Identity string `json:"identity,omitempty"`
+	// DerivedFrom lists the "schema.table.column"s a view or
+	// materialized view column is computed from.
+	DerivedFrom []string `json:"derived_from,omitempty"`
+	// Description is the COMMENT ON COLUMN text.
+	Description string `json:"description,omitempty"`
+	Terms       []Term `json:"terms,omitempty"`
+	// SecurityLabels maps label providers (e.g. pgsodium, sepgsql) to the
+	// column's SECURITY LABEL.
+	SecurityLabels map[string]string `json:"security_labels,omitempty"`
+	// Storage and Compression are only set when they differ from the
+	// type's default, e.g. "EXTERNAL" and "lz4".
+	Storage     string `json:"storage,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+// DeclaredType names the column's type the way it was declared where
+// information_schema's data_type only says USER-DEFINED or ARRAY: the
+// enum, composite or domain's name, or the element type followed by "[]".
+func (c Column) DeclaredType() string {
+	switch {
+	case c.Type == "USER-DEFINED" && c.UDTName != "":
+		return c.UDTName
+	case c.Type == "ARRAY" && strings.HasPrefix(c.UDTName, "_"):
+		return c.UDTName[1:] + "[]"
+	}
+	return c.Type
+}
+
+type Index struct {
+	Name string `json:"name"`
+	// Columns are the key columns, then any INCLUDE columns. An
+	// expression key is its text, as pg_get_indexdef prints it, such as
+	// lower((email)::text).
+	Columns   []string `json:"columns,omitempty"`
+	IsUnique  bool     `json:"is_unique,omitempty"`
+	IsPrimary bool     `json:"is_primary,omitempty"`
+	// Predicate is the WHERE clause of a partial index.
+	Predicate string `json:"predicate,omitempty"`
+}
+
+type Table struct {
+	Schema      string   `json:"schema"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Columns     []Column `json:"columns,omitempty"`
+	Indexes     []Index  `json:"indexes,omitempty"`
+	// ReplicaIdentity is one of default, full, nothing or index; for index
+	// the index is named in ReplicaIdentityIndex.
+	ReplicaIdentity      string            `json:"replica_identity,omitempty"`
+	ReplicaIdentityIndex string            `json:"replica_identity_index,omitempty"`
+	Publications         []string          `json:"publications,omitempty"`
+	Checks               []CheckConstraint `json:"checks,omitempty"`
+	ForeignKeys          []ForeignKey      `json:"foreign_keys,omitempty"`
+	// RowSecurity is set when row-level security is enabled, and
+	// ForceRowSecurity when it also applies to the table's owner.
+	// Policies only restrict rows while RowSecurity is set.
+	RowSecurity      bool     `json:"row_security,omitempty"`
+	ForceRowSecurity bool     `json:"force_row_security,omitempty"`
+	Policies         []Policy `json:"policies,omitempty"`
+	// PartitionStrategy (range, list or hash) and PartitionKey are set on
+	// declaratively partitioned tables, whose partitions are listed in
+	// Partitions rather than as tables of their own.
+	PartitionStrategy string      `json:"partition_strategy,omitempty"`
+	PartitionKey      string      `json:"partition_key,omitempty"`
+	Partitions        []Partition `json:"partitions,omitempty"`
+	// Grants are only fetched with FetchOptions.Grants.
+	Grants []Grant `json:"grants,omitempty"`
+	// Size is only fetched with FetchOptions.Sizes.
+	Size *TableSize `json:"size,omitempty"`
+	// Tags are the audiences the object is marked for with "@tag name"
+	// in its comment or in the config file.
+	Tags []string `json:"tags,omitempty"`
+	// Terms are glossary entries defined with "@term name: definition"
+	// lines in the comment.
+	Terms []Term `json:"terms,omitempty"`
+}
+
+// Partition is one partition of a partitioned table. Bound is the bound
+// as written in DDL, e.g. "FOR VALUES FROM ('2024-01-01') TO
+// ('2024-01-02')" or "DEFAULT". A partition that is itself partitioned
+// has its own strategy, key and partitions.
+type Partition struct {
+	Schema            string      `json:"schema"`
+	Name              string      `json:"name"`
+	Bound             string      `json:"bound"`
+	PartitionStrategy string      `json:"partition_strategy,omitempty"`
+	PartitionKey      string      `json:"partition_key,omitempty"`
+	Partitions        []Partition `json:"partitions,omitempty"`
+}
+
+// ForeignKey is a FOREIGN KEY constraint. Columns and RefColumns are in
+// constraint order, so Columns[i] references RefColumns[i].
+type ForeignKey struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns,omitempty"`
+	RefSchema  string   `json:"ref_schema,omitempty"`
+	RefTable   string   `json:"ref_table,omitempty"`
+	RefColumns []string `json:"ref_columns,omitempty"`
+	// OnDelete and OnUpdate are the referential actions, e.g. "CASCADE"
+	// or "NO ACTION".
+	OnDelete string `json:"on_delete,omitempty"`
+	OnUpdate string `json:"on_update,omitempty"`
+}
+
+// CheckConstraint is a CHECK constraint. Columns lists the columns it
+// references; a single entry usually means it was declared on the column.
+type CheckConstraint struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns,omitempty"`
+	Definition string   `json:"definition,omitempty"`
+}
+
+// Policy is a row-level security policy. Command is ALL, SELECT, INSERT,
+// UPDATE or DELETE, and Roles holds "public" when the policy applies to
+// every role. Using filters the rows a command sees and WithCheck the
+// rows it may write.
+type Policy struct {
+	Name        string   `json:"name"`
+	Command     string   `json:"command"`
+	Restrictive bool     `json:"restrictive,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Using       string   `json:"using,omitempty"`
+	WithCheck   string   `json:"with_check,omitempty"`
+}
+
+type View struct {
+	Schema      string   `json:"schema"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Columns     []Column `json:"columns,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+	// Definition is the SELECT from pg_get_viewdef.
+	Definition string   `json:"definition,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Terms      []Term   `json:"terms,omitempty"`
+}
+
+type Function struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	// Kind is "procedure" for procedures, which are run with CALL and
+	// have no return type, and empty for functions.
+	Kind       string `json:"kind,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+	ReturnType string `json:"return_type,omitempty"`
+	// Description is the COMMENT ON FUNCTION text.
+	Description string `json:"description,omitempty"`
+	// Args are the parameters that Arguments deparses, in declaration
+	// order, including OUT and TABLE columns.
+	Args []Argument `json:"args,omitempty"`
+	// ReturnsSet is true for SETOF and TABLE(...) functions.
+	ReturnsSet bool `json:"returns_set,omitempty"`
+	// ReturnColumns are the columns of each returned row: the OUT or
+	// TABLE parameters, or the attributes of a composite return type.
+	ReturnColumns []Column `json:"return_columns,omitempty"`
+	// Language is the implementation language, such as sql or plpgsql.
+	Language string `json:"language,omitempty"`
+	// Volatility is immutable, stable or volatile; empty for procedures.
+	Volatility string `json:"volatility,omitempty"`
+	// SecurityDefiner is true when fn runs with its owner's privileges
+	// rather than the caller's.
+	SecurityDefiner bool `json:"security_definer,omitempty"`
+	// Cost is the planner's estimate of one call, in units of
+	// cpu_operator_cost.
+	Cost float64 `json:"cost,omitempty"`
+	// Definition is the CREATE statement, as pg_get_functiondef prints
+	// it; only fetched with FetchOptions.FunctionBodies.
+	Definition string `json:"definition,omitempty"`
+	// Grants are only fetched with FetchOptions.Grants.
+	Grants []Grant  `json:"grants,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Terms  []Term   `json:"terms,omitempty"`
+}
+
+// ObjectType is the word SQL uses for fn in statements such as COMMENT
+// ON: "function" or "procedure".
+func (fn Function) ObjectType() string {
+	if fn.Kind == "procedure" {
+		return "procedure"
+	}
+	return "function"
+}
+
+// DefaultCost is the cost PostgreSQL gives a function in language that
+// declares none: 1 for C and internal functions, 100 for the rest.
+func DefaultCost(language string) float64 {
+	if language == "c" || language == "internal" {
+		return 1
+	}
+	return 100
+}
+
+// Argument is one function parameter. Mode is IN, OUT, INOUT, VARIADIC
+// or TABLE; Name is empty for unnamed parameters.
+type Argument struct {
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type"`
+	Mode    string `json:"mode"`
+	Default string `json:"default,omitempty"`
+}
+
+type CustomType struct {
+	Schema string   `json:"schema"`
+	Name   string   `json:"name"`
+	Kind   string   `json:"kind"`
+	Values []string `json:"values,omitempty"`
+	// Attributes are a composite type's fields, in declaration order.
+	Attributes  []Column `json:"attributes,omitempty"`
+	Description string   `json:"description,omitempty"`
+	// ValueDescriptions explains individual enum values, keyed by label.
+	ValueDescriptions map[string]string `json:"value_descriptions,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	Terms             []Term            `json:"terms,omitempty"`
+}
+
+type MaterializedView struct {
+	Schema      string   `json:"schema"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Columns     []Column `json:"columns,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+	Definition  string   `json:"definition,omitempty"`
+	// Refreshes are the pg_cron jobs and triggers found to refresh the
+	// view.
+	Refreshes []Refresh `json:"refreshes,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Terms     []Term    `json:"terms,omitempty"`
+}
+
+type Sequence struct {
+	Schema    string `json:"schema"`
+	Name      string `json:"name"`
+	DataType  string `json:"data_type,omitempty"`
+	Start     int64  `json:"start"`
+	Min       int64  `json:"min"`
+	Max       int64  `json:"max"`
+	Increment int64  `json:"increment"`
+	Cycle     bool   `json:"cycle,omitempty"`
+	Cache     int64  `json:"cache,omitempty"`
+	// LastValue is only read with FetchOptions.SequenceValues, and is nil
+	// for a sequence that has not been used yet.
+	LastValue *int64 `json:"last_value,omitempty"`
+	// OwnerTable and OwnerColumn name the column the sequence belongs to,
+	// through OWNED BY or as an identity column's implicit sequence
+	// (Identity).
+	OwnerTable  string `json:"owner_table,omitempty"`
+	OwnerColumn string `json:"owner_column,omitempty"`
+	Identity    bool   `json:"identity,omitempty"`
+	// Tags can only be set from the config file.
+	Tags []string `json:"tags,omitempty"`
+}
+
+type Trigger struct {
+	Schema   string `json:"schema"`
+	Table    string `json:"table"`
+	Name     string `json:"name"`
+	Event    string `json:"event,omitempty"`
+	Timing   string `json:"timing,omitempty"`
+	Function string `json:"function,omitempty"`
+	// FunctionSchema is the function's schema when it differs from the
+	// trigger's.
+	FunctionSchema string `json:"function_schema,omitempty"`
+	// Level is ROW or STATEMENT: whether the function runs once per
+	// affected row or once per statement.
+	Level string `json:"level,omitempty"`
+	// Columns restricts an UPDATE trigger to updates of these columns.
+	Columns []string `json:"columns,omitempty"`
+	// When is the WHEN condition, without its parentheses.
+	When string `json:"when,omitempty"`
+	// OldTable and NewTable name the transition tables an AFTER trigger
+	// declares, through which its function sees every affected row.
+	OldTable string `json:"old_table,omitempty"`
+	NewTable string `json:"new_table,omitempty"`
+	// Definition is the CREATE TRIGGER statement, as pg_get_triggerdef
+	// prints it.
+	Definition string `json:"definition,omitempty"`
+}
+
+// EventClause is the trigger's events as CREATE TRIGGER lists them, with
+// the columns of an UPDATE OF.
+func (t Trigger) EventClause() string {
+	if len(t.Columns) == 0 {
+		return t.Event
+	}
+	return strings.Replace(t.Event, "UPDATE", "UPDATE OF "+strings.Join(t.Columns, ", "), 1)
+}
+
+// Referencing is the trigger's REFERENCING clause, or "" when it declares
+// no transition tables.
+func (t Trigger) Referencing() string {
+	var parts []string
+	if t.OldTable != "" {
+		parts = append(parts, "OLD TABLE AS "+t.OldTable)
+	}
+	if t.NewTable != "" {
+		parts = append(parts, "NEW TABLE AS "+t.NewTable)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "REFERENCING " + strings.Join(parts, " ")
+}
+
+type SchemaInfo struct {
+	Name              string             `json:"name"`
+	Tables            []Table            `json:"tables,omitempty"`
+	Views             []View             `json:"views,omitempty"`
+	MaterializedViews []MaterializedView `json:"materialized_views,omitempty"`
+	Sequences         []Sequence         `json:"sequences,omitempty"`
+	Triggers          []Trigger          `json:"triggers,omitempty"`
+	Functions         []Function         `json:"functions,omitempty"`
+	Types             []CustomType       `json:"types,omitempty"`
+	// Warnings lists objects and features the fetch left out.
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// FetchSchemas introspects each of schemas over conn, in order, and
+// stops at the first query that fails.
+func FetchSchemas(ctx context.Context, conn Querier, schemas []string, opts FetchOptions) ([]SchemaInfo, error) {
+	return fetchSchemas(ctx, conn, schemas, opts, nil)
+}
+
+// FetchSchemasBestEffort is like FetchSchemas but leaves out whatever
+// section of a schema fails to load, returning one warning per failure.
+func FetchSchemasBestEffort(ctx context.Context, conn Querier, schemas []string, opts FetchOptions) ([]SchemaInfo, []string, error) {
+	var warnings []string
+	result, err := fetchSchemas(ctx, conn, schemas, opts, &warnings)
+	return result, warnings, err
+}
+
+// fetchSchemas aborts on the first error unless warnings is non-nil, in
+// which case query errors are appended to it and the section is left
+// empty.
+func fetchSchemas(ctx context.Context, conn Querier, schemas []string, opts FetchOptions, warnings *[]string) ([]SchemaInfo, error) {
+	keep, err := opts.tableFilter()
+	if err != nil {
+		return nil, err
+	}
+	if opts.ReadOnly {
+		conn = ReadOnly(conn)
+	}
+	if opts.QueryDelay > 0 {
+		conn = Paced(conn, opts.QueryDelay)
+	}
+	if opts.QueryComment != "" {
+		conn = Commented(conn, opts.QueryComment)
+	}
+
+	var result []SchemaInfo
+	// refreshes holds the database-wide sources of materialized view
+	// refreshes, fetched for the first schema that has a matview.
+	var refreshes *refreshSources
+
+	for _, schema := range schemas {
+		info := SchemaInfo{Name: schema}
+
+		sections := []struct {
+			name  string
+			fetch func() error
+		}{
+			{"tables", func() (err error) { info.Tables, err = fetchTables(ctx, conn, schema, keep, opts.Grants); return }},
+			{"views", func() (err error) { info.Views, err = fetchViews(ctx, conn, schema); return }},
+			{"materialized views", func() (err error) { info.MaterializedViews, err = fetchMaterializedViews(ctx, conn, schema); return }},
+			{"materialized view refreshes", func() (err error) {
+				if len(info.MaterializedViews) == 0 {
+					return nil
+				}
+				if refreshes == nil {
+					if refreshes, err = fetchRefreshSources(ctx, conn); err != nil {
+						return err
+					}
+				}
+				attachRefreshes(info.MaterializedViews, refreshes.jobs, refreshes.functions)
+				return nil
+			}},
+			{"sequences", func() (err error) {
+				info.Sequences, err = fetchSequences(ctx, conn, schema, opts.SequenceValues)
+				return
+			}},
+			{"triggers", func() (err error) { info.Triggers, err = fetchTriggers(ctx, conn, schema, keep); return }},
+			{"functions", func() (err error) {
+				info.Functions, err = fetchFunctions(ctx, conn, schema, opts.Grants, opts.FunctionBodies)
+				return
+			}},
+			{"types", func() (err error) { info.Types, err = fetchCustomTypes(ctx, conn, schema); return }},
+			{"warnings", func() (err error) {
+				info.Warnings, err = fetchWarnings(ctx, conn, schema, keep, opts.SequenceValues)
+				return
+			}},
+		}
+		if opts.Sizes {
+			sections = append(sections, struct {
+				name  string
+				fetch func() error
+			}{"sizes", func() error { return fetchTableSizes(ctx, conn, schema, info.Tables) }})
+		}
+
+		for _, section := range sections {
+			if err := section.fetch(); err != nil {
+				err = fmt.Errorf("fetching %s for schema %s: %w", section.name, schema, err)
+				if warnings == nil {
+					return nil, err
+				}
+				*warnings = append(*warnings, err.Error())
+			}
+		}
+
+		if refreshes != nil && refreshes.warning != nil && len(info.MaterializedViews) > 0 {
+			info.Warnings = append(info.Warnings, *refreshes.warning)
+		}
+
+		deriveColumnLineage(&info)
+		extractTerms(&info)
+		sortSchema(&info)
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+func fetchTables(ctx context.Context, conn Querier, schema string, keep func(schema, table string) bool, withGrants bool) ([]Table, error) {
+	query := `
+		SELECT
+			t.table_name,
+			COALESCE(obj_description(c.oid, 'pg_class'), '') as description,
+			CASE c.relreplident
+				WHEN 'd' THEN 'default'
+				WHEN 'f' THEN 'full'
+				WHEN 'n' THEN 'nothing'
+				WHEN 'i' THEN 'index'
+			END as replica_identity,
+			COALESCE(
+				(SELECT i.relname
+				 FROM pg_index x
+				 JOIN pg_class i ON i.oid = x.indexrelid
+				 WHERE x.indrelid = c.oid
+				   AND x.indisreplident), '') as replica_identity_index,
+			CASE pt.partstrat
+				WHEN 'r' THEN 'range'
+				WHEN 'l' THEN 'list'
+				WHEN 'h' THEN 'hash'
+				ELSE ''
+			END as partition_strategy,
+			COALESCE(substring(pg_get_partkeydef(c.oid) from '\((.*)\)$'), '') as partition_key,
+			c.relrowsecurity,
+			c.relforcerowsecurity
+		FROM information_schema.tables t
+		JOIN pg_class c ON c.oid = format('%I.%I', t.table_schema, t.table_name)::regclass
+		LEFT JOIN pg_partitioned_table pt ON pt.partrelid = c.oid
+		WHERE t.table_schema = $1
+		  AND t.table_type = 'BASE TABLE'
+		  AND NOT c.relispartition
+		ORDER BY t.table_name`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []Table
+	for rows.Next() {
+		t := Table{Schema: schema}
+		if err := rows.Scan(&t.Name, &t.Description, &t.ReplicaIdentity, &t.ReplicaIdentityIndex, &t.PartitionStrategy, &t.PartitionKey, &t.RowSecurity, &t.ForceRowSecurity); err != nil {
+			return nil, err
+		}
+		t.Description, t.Tags = parseTags(t.Description)
+		if keep(schema, t.Name) {
+			tables = append(tables, t)
+		}
+	}
+
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+
+	publications, err := fetchPublications(ctx, conn, schema, names)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := fetchCheckConstraints(ctx, conn, schema, names)
+	if err != nil {
+		return nil, err
+	}
+
+	foreignKeys, err := fetchForeignKeys(ctx, conn, schema, names)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := fetchPolicies(ctx, conn, schema, names)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := fetchColumns(ctx, conn, schema, names)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := fetchIndexes(ctx, conn, schema, names)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := fetchPartitions(ctx, conn, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var grants map[string][]Grant
+	if withGrants {
+		grants, err = fetchTableGrants(ctx, conn, schema, names)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range tables {
+		tables[i].Grants = grants[tables[i].Name]
+		tables[i].Columns = columns[tables[i].Name]
+		tables[i].ForeignKeys = foreignKeys[tables[i].Name]
+		setFKRefs(&tables[i])
+		tables[i].Indexes = indexes[tables[i].Name]
+		tables[i].Publications = publications[tables[i].Name]
+		tables[i].Checks = checks[tables[i].Name]
+		tables[i].Policies = policies[tables[i].Name]
+		if tables[i].PartitionStrategy != "" {
+			tables[i].Partitions = partitionTree(partitions, schema+"."+tables[i].Name)
+		}
+	}
+
+	return tables, nil
+}
+
+// fetchPartitions returns the partitions of every partitioned table in
+// schema, keyed by the parent's qualified name.
+func fetchPartitions(ctx context.Context, conn Querier, schema string) (map[string][]Partition, error) {
+	query := `
+		SELECT
+			pn.nspname as parent_schema,
+			p.relname as parent_name,
+			cn.nspname as partition_schema,
+			c.relname as partition_name,
+			COALESCE(pg_get_expr(c.relpartbound, c.oid), '') as bound,
+			CASE pt.partstrat
+				WHEN 'r' THEN 'range'
+				WHEN 'l' THEN 'list'
+				WHEN 'h' THEN 'hash'
+				ELSE ''
+			END as partition_strategy,
+			COALESCE(substring(pg_get_partkeydef(c.oid) from '\((.*)\)$'), '') as partition_key
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_namespace cn ON cn.oid = c.relnamespace
+		JOIN pg_class p ON p.oid = i.inhparent
+		JOIN pg_namespace pn ON pn.oid = p.relnamespace
+		LEFT JOIN pg_partitioned_table pt ON pt.partrelid = c.oid
+		WHERE pn.nspname = $1
+		  AND c.relispartition
+		ORDER BY p.relname, c.relname`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := make(map[string][]Partition)
+	for rows.Next() {
+		var parentSchema, parentName string
+		var part Partition
+		if err := rows.Scan(&parentSchema, &parentName, &part.Schema, &part.Name, &part.Bound, &part.PartitionStrategy, &part.PartitionKey); err != nil {
+			return nil, err
+		}
+		parent := parentSchema + "." + parentName
+		partitions[parent] = append(partitions[parent], part)
+	}
+
+	return partitions, nil
+}
+
+// partitionTree returns parent's partitions with their own partitions
+// attached, for multi-level partitioning.
+func partitionTree(partitions map[string][]Partition, parent string) []Partition {
+	children := partitions[parent]
+	for i := range children {
+		if children[i].PartitionStrategy != "" {
+			children[i].Partitions = partitionTree(partitions, children[i].Schema+"."+children[i].Name)
+		}
+	}
+	return children
+}
+
+func fetchCheckConstraints(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]CheckConstraint, error) {
+	query := `
+		SELECT
+			t.relname as table_name,
+			con.conname,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			) as columns,
+			pg_get_constraintdef(con.oid) as definition
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+		  AND t.relname = ANY($2)
+		  AND con.contype = 'c'
+		ORDER BY t.relname, con.conname`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string][]CheckConstraint)
+	for rows.Next() {
+		var table string
+		var check CheckConstraint
+		if err := rows.Scan(&table, &check.Name, &check.Columns, &check.Definition); err != nil {
+			return nil, err
+		}
+		checks[table] = append(checks[table], check)
+	}
+
+	return checks, nil
+}
+
+func fetchForeignKeys(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]ForeignKey, error) {
+	query := `
+		SELECT
+			t.relname as table_name,
+			con.conname,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			) as columns,
+			rn.nspname as ref_schema,
+			rt.relname as ref_table,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.confkey) WITH ORDINALITY k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			) as ref_columns,
+			` + fkActionExpr("con.confdeltype") + ` as on_delete,
+			` + fkActionExpr("con.confupdtype") + ` as on_update
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class rt ON rt.oid = con.confrelid
+		JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+		WHERE n.nspname = $1
+		  AND t.relname = ANY($2)
+		  AND con.contype = 'f'
+		ORDER BY t.relname, con.conname`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foreignKeys := make(map[string][]ForeignKey)
+	for rows.Next() {
+		var table string
+		var fk ForeignKey
+		if err := rows.Scan(&table, &fk.Name, &fk.Columns, &fk.RefSchema, &fk.RefTable, &fk.RefColumns, &fk.OnDelete, &fk.OnUpdate); err != nil {
+			return nil, err
+		}
+		foreignKeys[table] = append(foreignKeys[table], fk)
+	}
+
+	return foreignKeys, nil
+}
+
+// fetchPolicies reads the row-level security policies on tables.
+func fetchPolicies(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]Policy, error) {
+	query := `
+		SELECT
+			t.relname as table_name,
+			pol.polname,
+			CASE pol.polcmd
+				WHEN 'r' THEN 'SELECT'
+				WHEN 'a' THEN 'INSERT'
+				WHEN 'w' THEN 'UPDATE'
+				WHEN 'd' THEN 'DELETE'
+				ELSE 'ALL'
+			END as command,
+			NOT pol.polpermissive as restrictive,
+			ARRAY(
+				SELECT CASE WHEN r.oid = 0 THEN 'public' ELSE pg_get_userbyid(r.oid)::text END
+				FROM unnest(pol.polroles) r(oid)
+				ORDER BY 1
+			) as roles,
+			COALESCE(pg_get_expr(pol.polqual, pol.polrelid), '') as using_expr,
+			COALESCE(pg_get_expr(pol.polwithcheck, pol.polrelid), '') as with_check
+		FROM pg_policy pol
+		JOIN pg_class t ON t.oid = pol.polrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+		  AND t.relname = ANY($2)
+		ORDER BY t.relname, pol.polname`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make(map[string][]Policy)
+	for rows.Next() {
+		var table string
+		var pol Policy
+		if err := rows.Scan(&table, &pol.Name, &pol.Command, &pol.Restrictive, &pol.Roles, &pol.Using, &pol.WithCheck); err != nil {
+			return nil, err
+		}
+		policies[table] = append(policies[table], pol)
+	}
+
+	return policies, nil
+}
+
+func fkActionExpr(column string) string {
+	return `CASE ` + column + `
+				WHEN 'a' THEN 'NO ACTION'
+				WHEN 'r' THEN 'RESTRICT'
+				WHEN 'c' THEN 'CASCADE'
+				WHEN 'n' THEN 'SET NULL'
+				WHEN 'd' THEN 'SET DEFAULT'
+			END`
+}
+
+// setFKRefs fills each column's FKRef and referential actions from the
+// table's foreign keys. A column in several foreign keys keeps the first
+// one.
+func setFKRefs(table *Table) {
+	for _, fk := range table.ForeignKeys {
+		for i, name := range fk.Columns {
+			if i >= len(fk.RefColumns) {
+				break
+			}
+			for j := range table.Columns {
+				if table.Columns[j].Name == name && table.Columns[j].FKRef == "" {
+					table.Columns[j].FKRef = fk.RefSchema + "." + fk.RefTable + "." + fk.RefColumns[i]
+					table.Columns[j].FKOnDelete = referentialAction(fk.OnDelete)
+					table.Columns[j].FKOnUpdate = referentialAction(fk.OnUpdate)
+				}
+			}
+		}
+	}
+}
+
+// referentialAction drops the default NO ACTION so only actions worth
+// pointing out are kept.
+func referentialAction(action string) string {
+	if action == "NO ACTION" {
+		return ""
+	}
+	return action
+}
+
+// fetchPublications maps each table in schema to the publications that
+// include it, whether listed explicitly or via FOR ALL TABLES.
+func fetchPublications(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]string, error) {
+	query := `
+		SELECT tablename, pubname
+		FROM pg_publication_tables
+		WHERE schemaname = $1
+		  AND tablename = ANY($2)
+		ORDER BY tablename, pubname`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publications := make(map[string][]string)
+	for rows.Next() {
+		var table, pub string
+		if err := rows.Scan(&table, &pub); err != nil {
+			return nil, err
+		}
+		publications[table] = append(publications[table], pub)
+	}
+
+	return publications, nil
+}
+
+// fetchColumns returns the columns of every table in schema, keyed by
+// table name.
+func fetchColumns(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]Column, error) {
+	query := `
+		SELECT
+			c.table_name,
+			c.column_name,
+			c.ordinal_position::int,
+			c.data_type,
+			c.udt_name,
+			c.is_nullable,
+			c.column_default,
+			CASE a.attidentity WHEN 'a' THEN 'ALWAYS' WHEN 'd' THEN 'BY DEFAULT' ELSE '' END as identity,
+			EXISTS (
+				SELECT 1 FROM pg_constraint con
+				WHERE con.conrelid = a.attrelid
+				  AND con.contype = 'p'
+				  AND a.attnum = ANY(con.conkey)) as is_pk,
+			EXISTS (
+				SELECT 1 FROM pg_constraint con
+				WHERE con.conrelid = a.attrelid
+				  AND con.contype = 'u'
+				  AND a.attnum = ANY(con.conkey)) as is_unique,
+			CASE WHEN a.attstorage <> t.typstorage THEN
+				CASE a.attstorage
+					WHEN 'p' THEN 'PLAIN'
+					WHEN 'e' THEN 'EXTERNAL'
+					WHEN 'm' THEN 'MAIN'
+					WHEN 'x' THEN 'EXTENDED'
+				END
+			ELSE '' END as storage,
+			` + compressionExpr(ctx, conn) + ` as compression,
+			COALESCE(col_description(a.attrelid, a.attnum), '') as description,
+			(SELECT jsonb_object_agg(sl.provider, sl.label)
+			 FROM pg_seclabel sl
+			 WHERE sl.classoid = 'pg_class'::regclass
+			   AND sl.objoid = a.attrelid
+			   AND sl.objsubid = a.attnum) as security_labels
+		FROM information_schema.columns c
+		JOIN pg_attribute a
+		  ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass
+		 AND a.attname = c.column_name
+		JOIN pg_type t ON t.oid = a.atttypid
+		WHERE c.table_schema = $1
+		  AND c.table_name = ANY($2)
+		ORDER BY c.table_name, c.ordinal_position`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]Column)
+	for rows.Next() {
+		var table string
+		var col Column
+		var nullable string
+		var defaultVal *string
+
+		if err := rows.Scan(&table, &col.Name, &col.Position, &col.Type, &col.UDTName, &nullable, &defaultVal, &col.Identity, &col.IsPK, &col.IsUnique, &col.Storage, &col.Compression, &col.Description, &col.SecurityLabels); err != nil {
+			return nil, err
+		}
+
+		col.Nullable = nullable == "YES"
+		if defaultVal != nil {
+			col.Default = *defaultVal
+		}
+
+		columns[table] = append(columns[table], col)
+	}
+
+	return columns, nil
+}
+
+// compressionExpr selects the per-column compression method, which only
+// exists from PostgreSQL 14 on.
+func compressionExpr(ctx context.Context, conn Querier) string {
+	if serverMajorVersion(ctx, conn) < 14 {
+		return "''"
+	}
+	return `CASE a.attcompression WHEN 'p' THEN 'pglz' WHEN 'l' THEN 'lz4' ELSE '' END`
+}
+
+// serverMajorVersion reads the major version from the server_version
+// parameter reported at connection time, asking the server when conn is
+// not a single connection. Wrappers such as ReadOnly are looked through.
+// It returns 0 if unavailable.
+func serverMajorVersion(ctx context.Context, conn Querier) int {
+	inner := conn
+	for {
+		w, ok := inner.(interface{ unwrap() Querier })
+		if !ok {
+			break
+		}
+		inner = w.unwrap()
+	}
+	if c, ok := inner.(*pgx.Conn); ok {
+		return parseMajorVersion(c.PgConn().ParameterStatus("server_version"))
+	}
+	var version string
+	if err := conn.QueryRow(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return 0
+	}
+	return parseMajorVersion(version)
+}
+
+func parseMajorVersion(version string) int {
+	major := 0
+	for _, c := range version {
+		if c < '0' || c > '9' {
+			break
+		}
+		major = major*10 + int(c-'0')
+	}
+	return major
+}
+
+// fetchIndexes returns the indexes of every table in schema, keyed by
+// table name.
+func fetchIndexes(ctx context.Context, conn Querier, schema string, tables []string) (map[string][]Index, error) {
+	query := `
+		SELECT
+			t.relname as table_name,
+			i.relname as index_name,
+			ARRAY(
+				SELECT CASE WHEN k.attnum = 0 THEN pg_get_indexdef(ix.indexrelid, k.n::int, false) ELSE a.attname::text END
+				FROM unnest(ix.indkey::int2[]) WITH ORDINALITY k(attnum, n)
+				LEFT JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+				ORDER BY k.n
+			) as columns,
+			ix.indisunique as is_unique,
+			ix.indisprimary as is_primary,
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid), '') as predicate
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+		  AND t.relname = ANY($2)
+		ORDER BY t.relname, i.relname`
+
+	rows, err := conn.Query(ctx, query, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]Index)
+	for rows.Next() {
+		var table string
+		var idx Index
+		if err := rows.Scan(&table, &idx.Name, &idx.Columns, &idx.IsUnique, &idx.IsPrimary, &idx.Predicate); err != nil {
+			return nil, err
+		}
+		indexes[table] = append(indexes[table], idx)
+	}
+
+	return indexes, nil
+}
+
+func fetchViews(ctx context.Context, conn Querier, schema string) ([]View, error) {
+	query := `
+		SELECT
+			table_name,
+			COALESCE(obj_description(format('%I.%I', table_schema, table_name)::regclass, 'pg_class'), '') as description,
+			COALESCE(pg_get_viewdef(format('%I.%I', table_schema, table_name)::regclass, true), '') as definition
+		FROM information_schema.views
+		WHERE table_schema = $1
+		ORDER BY table_name`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []View
+	for rows.Next() {
+		v := View{Schema: schema}
+		if err := rows.Scan(&v.Name, &v.Description, &v.Definition); err != nil {
+			return nil, err
+		}
+		v.Description, v.Tags = parseTags(v.Description)
+		views = append(views, v)
+	}
+
+	deps, err := fetchViewDependencies(ctx, conn, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := fetchViewColumns(ctx, conn, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range views {
+		views[i].Columns = columns[views[i].Name]
+		views[i].DependsOn = deps[views[i].Name]
+	}
+
+	return views, nil
+}
+
+// fetchViewDependencies returns, per view or materialized view in schema,
+// the schema-qualified relations its query reads from.
+func fetchViewDependencies(ctx context.Context, conn Querier, schema string) (map[string][]string, error) {
+	query := `
+		SELECT DISTINCT
+			v.relname as view_name,
+			rn.nspname || '.' || r.relname as depends_on
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		JOIN pg_class r ON r.oid = d.refobjid
+		JOIN pg_namespace rn ON rn.oid = r.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass
+		  AND d.refclassid = 'pg_class'::regclass
+		  AND d.deptype = 'n'
+		  AND vn.nspname = $1
+		  AND r.oid <> v.oid
+		ORDER BY view_name, depends_on`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deps := make(map[string][]string)
+	for rows.Next() {
+		var view, dep string
+		if err := rows.Scan(&view, &dep); err != nil {
+			return nil, err
+		}
+		deps[view] = append(deps[view], dep)
+	}
+
+	return deps, nil
+}
+
+// fetchViewColumns returns the columns of every relation in schema, keyed
+// by name, without the table-only constraint and storage details.
+func fetchViewColumns(ctx context.Context, conn Querier, schema string) (map[string][]Column, error) {
+	query := `
+		SELECT
+			table_name,
+			column_name,
+			ordinal_position::int,
+			data_type,
+			udt_name,
+			is_nullable,
+			COALESCE(col_description(format('%I.%I', table_schema, table_name)::regclass, ordinal_position::int), '') as description
+		FROM information_schema.columns
+		WHERE table_schema = $1
+		ORDER BY table_name, ordinal_position`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]Column)
+	for rows.Next() {
+		var relation string
+		var col Column
+		var nullable string
+
+		if err := rows.Scan(&relation, &col.Name, &col.Position, &col.Type, &col.UDTName, &nullable, &col.Description); err != nil {
+			return nil, err
+		}
+
+		col.Nullable = nullable == "YES"
+		columns[relation] = append(columns[relation], col)
+	}
+
+	return columns, nil
+}
+
+func fetchFunctions(ctx context.Context, conn Querier, schema string, withGrants, withBodies bool) ([]Function, error) {
+	query := `
+		SELECT
+			p.proname as name,
+			CASE p.prokind WHEN 'p' THEN 'procedure' ELSE '' END as kind,
+			pg_get_function_arguments(p.oid) as arguments,
+			COALESCE(pg_get_function_result(p.oid), '') as return_type,
+			COALESCE(obj_description(p.oid, 'pg_proc'), '') as description,
+			COALESCE((
+				SELECT jsonb_agg(jsonb_build_object(
+					'name', COALESCE(p.proargnames[i], ''),
+					'type', format_type(COALESCE(p.proallargtypes[i], p.proargtypes[i - 1]), NULL),
+					'mode', CASE COALESCE(p.proargmodes[i], 'i')
+						WHEN 'i' THEN 'IN'
+						WHEN 'o' THEN 'OUT'
+						WHEN 'b' THEN 'INOUT'
+						WHEN 'v' THEN 'VARIADIC'
+						WHEN 't' THEN 'TABLE'
+					END,
+					'default', pg_get_function_arg_default(p.oid, i)
+				) ORDER BY i)
+				FROM generate_series(1, COALESCE(array_length(p.proallargtypes, 1), p.pronargs)) i
+			), '[]') as args,
+			p.proretset as returns_set,
+			COALESCE((
+				SELECT jsonb_agg(jsonb_build_object(
+					'name', a.attname,
+					'position', a.attnum,
+					'type', format_type(a.atttypid, a.atttypmod),
+					'nullable', true,
+					'description', COALESCE(col_description(rt.typrelid, a.attnum), '')
+				) ORDER BY a.attnum)
+				FROM pg_type rt
+				JOIN pg_attribute a ON a.attrelid = rt.typrelid AND a.attnum > 0 AND NOT a.attisdropped
+				WHERE rt.oid = p.prorettype
+			), '[]') as return_columns,
+			CASE WHEN $2 THEN (
+				SELECT jsonb_agg(jsonb_build_object('grantee', g.grantee, 'privileges', g.privileges) ORDER BY g.grantee)
+				FROM (
+					SELECT
+						CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee)::text END as grantee,
+						array_agg(a.privilege_type::text ORDER BY a.privilege_type) as privileges
+					FROM aclexplode(COALESCE(p.proacl, acldefault('f', p.proowner))) a
+					GROUP BY 1
+				) g
+			) END as grants,
+			l.lanname as language,
+			CASE WHEN p.prokind = 'p' THEN ''
+				ELSE CASE p.provolatile WHEN 'i' THEN 'immutable' WHEN 's' THEN 'stable' ELSE 'volatile' END
+			END as volatility,
+			p.prosecdef as security_definer,
+			p.procost::float8 as cost,
+			CASE WHEN $3 THEN pg_get_functiondef(p.oid) ELSE '' END as definition
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		JOIN pg_language l ON l.oid = p.prolang
+		WHERE n.nspname = $1
+		  AND p.prokind IN ('f', 'p')
+		ORDER BY p.proname, arguments`
+
+	rows, err := conn.Query(ctx, query, schema, withGrants, withBodies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var functions []Function
+	for rows.Next() {
+		var fn Function
+		fn.Schema = schema
+		if err := rows.Scan(&fn.Name, &fn.Kind, &fn.Arguments, &fn.ReturnType, &fn.Description, &fn.Args, &fn.ReturnsSet, &fn.ReturnColumns, &fn.Grants,
+			&fn.Language, &fn.Volatility, &fn.SecurityDefiner, &fn.Cost, &fn.Definition); err != nil {
+			return nil, err
+		}
+		fn.Description, fn.Tags = parseTags(fn.Description)
+		if len(fn.ReturnColumns) == 0 {
+			fn.ReturnColumns = outputColumns(fn.Args)
+		}
+		functions = append(functions, fn)
+	}
+
+	return functions, nil
+}
+
+// outputColumns returns the row a function with OUT or TABLE parameters
+// produces. A single OUT parameter yields a scalar, not a row.
+func outputColumns(args []Argument) []Column {
+	var columns []Column
+	for _, arg := range args {
+		if arg.Mode == "OUT" || arg.Mode == "INOUT" || arg.Mode == "TABLE" {
+			columns = append(columns, Column{Name: arg.Name, Position: len(columns) + 1, Type: arg.Type, Nullable: true})
+		}
+	}
+	if len(columns) < 2 {
+		return nil
+	}
+	return columns
+}
+
+func fetchCustomTypes(ctx context.Context, conn Querier, schema string) ([]CustomType, error) {
+	var types []CustomType
+
+	// Fetch enums
+	enumQuery := `
+		SELECT
+			t.typname,
+			array_agg(e.enumlabel ORDER BY e.enumsortorder),
+			COALESCE(obj_description(t.oid, 'pg_type'), '') as description
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		WHERE n.nspname = $1
+		GROUP BY t.oid, t.typname
+		ORDER BY t.typname`
+
+	rows, err := conn.Query(ctx, enumQuery, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ct CustomType
+		ct.Schema = schema
+		ct.Kind = "enum"
+		var comment string
+		if err := rows.Scan(&ct.Name, &ct.Values, &comment); err != nil {
+			return nil, err
+		}
+		comment, ct.Tags = parseTags(comment)
+		ct.Description, ct.ValueDescriptions = parseEnumComment(comment, ct.Values)
+		types = append(types, ct)
+	}
+
+	// Fetch composite types, one row per attribute
+	compositeQuery := `
+		SELECT
+			t.typname,
+			COALESCE(obj_description(t.oid, 'pg_type'), '') as description,
+			a.attname,
+			a.attnum::int,
+			pg_catalog.format_type(a.atttypid, a.atttypmod) as type,
+			COALESCE(col_description(c.oid, a.attnum), '') as attribute_description
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_class c ON c.oid = t.typrelid
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		WHERE n.nspname = $1
+		  AND t.typtype = 'c'
+		  AND c.relkind = 'c'
+		ORDER BY t.typname, a.attnum`
+
+	rows2, err := conn.Query(ctx, compositeQuery, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows2.Close()
+
+	for rows2.Next() {
+		var name, description string
+		var attr Column
+		if err := rows2.Scan(&name, &description, &attr.Name, &attr.Position, &attr.Type, &attr.Description); err != nil {
+			return nil, err
+		}
+		attr.Nullable = true
+		if len(types) == 0 || types[len(types)-1].Kind != "composite" || types[len(types)-1].Name != name {
+			description, tags := parseTags(description)
+			types = append(types, CustomType{Schema: schema, Name: name, Kind: "composite", Description: description, Tags: tags})
+		}
+		ct := &types[len(types)-1]
+		ct.Attributes = append(ct.Attributes, attr)
+		ct.Values = append(ct.Values, attr.Name+" "+attr.Type)
+	}
+
+	return types, nil
+}
+
+// parseEnumComment splits an enum's comment into the type description and
+// per-value annotations. A line of the form "value: text" (optionally
+// bulleted with "-" or "*") whose value is one of the enum's labels
+// describes that value; every other line belongs to the description.
+func parseEnumComment(comment string, values []string) (string, map[string]string) {
+	var descriptions map[string]string
+	var rest []string
+	for _, line := range strings.Split(comment, "\n") {
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "-* ")
+		label, text, ok := strings.Cut(trimmed, ":")
+		label = strings.Trim(strings.TrimSpace(label), "'`\"")
+		if ok && slices.Contains(values, label) {
+			if descriptions == nil {
+				descriptions = make(map[string]string)
+			}
+			descriptions[label] = strings.TrimSpace(text)
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return strings.TrimSpace(strings.Join(rest, "\n")), descriptions
+}
+
+func ParseSchemas(input string) []string {
+	parts := strings.Split(input, ",")
+	var schemas []string
+	for _, p := range parts {
+		s := strings.TrimSpace(p)
+		if s != "" {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+func fetchMaterializedViews(ctx context.Context, conn Querier, schema string) ([]MaterializedView, error) {
+	query := `
+		SELECT
+			matviewname,
+			COALESCE(obj_description(format('%I.%I', schemaname, matviewname)::regclass, 'pg_class'), '') as description,
+			COALESCE(pg_get_viewdef(format('%I.%I', schemaname, matviewname)::regclass, true), '') as definition
+		FROM pg_matviews
+		WHERE schemaname = $1
+		ORDER BY matviewname`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []MaterializedView
+	for rows.Next() {
+		mv := MaterializedView{Schema: schema}
+		if err := rows.Scan(&mv.Name, &mv.Description, &mv.Definition); err != nil {
+			return nil, err
+		}
+		mv.Description, mv.Tags = parseTags(mv.Description)
+		views = append(views, mv)
+	}
+
+	deps, err := fetchViewDependencies(ctx, conn, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := fetchViewColumns(ctx, conn, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range views {
+		views[i].Columns = columns[views[i].Name]
+		views[i].DependsOn = deps[views[i].Name]
+	}
+
+	return views, nil
+}
+
+func fetchSequences(ctx context.Context, conn Querier, schema string, withValues bool) ([]Sequence, error) {
+	// last_value is NULL without USAGE or SELECT on the sequence, as
+	// well as before its first nextval.
+	query := `
+		SELECT
+			s.sequencename,
+			s.data_type::text,
+			s.start_value,
+			s.min_value,
+			s.max_value,
+			s.increment_by,
+			s.cycle,
+			s.cache_size,
+			CASE WHEN $2 THEN s.last_value END as last_value,
+			COALESCE(o.table_name, '') as owner_table,
+			COALESCE(o.column_name, '') as owner_column,
+			COALESCE(o.is_identity, false) as is_identity
+		FROM pg_sequences s
+		LEFT JOIN LATERAL (
+			SELECT t.relname::text as table_name, a.attname::text as column_name, d.deptype = 'i' as is_identity
+			FROM pg_depend d
+			JOIN pg_class t ON t.oid = d.refobjid
+			JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+			WHERE d.classid = 'pg_class'::regclass
+			  AND d.objid = format('%I.%I', s.schemaname, s.sequencename)::regclass
+			  AND d.refclassid = 'pg_class'::regclass
+			  AND d.deptype IN ('a', 'i')
+			LIMIT 1
+		) o ON true
+		WHERE s.schemaname = $1
+		ORDER BY s.sequencename`
+
+	rows, err := conn.Query(ctx, query, schema, withValues)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sequences []Sequence
+	for rows.Next() {
+		var seq Sequence
+		seq.Schema = schema
+		if err := rows.Scan(&seq.Name, &seq.DataType, &seq.Start, &seq.Min, &seq.Max, &seq.Increment, &seq.Cycle, &seq.Cache, &seq.LastValue, &seq.OwnerTable, &seq.OwnerColumn, &seq.Identity); err != nil {
+			return nil, err
+		}
+		sequences = append(sequences, seq)
+	}
+
+	return sequences, nil
+}
+
+func fetchTriggers(ctx context.Context, conn Querier, schema string, keep func(schema, table string) bool) ([]Trigger, error) {
+	query := `
+		SELECT
+			c.relname as table_name,
+			t.tgname as trigger_name,
+			CASE
+				WHEN t.tgtype & 64 = 64 THEN 'INSTEAD OF'
+				WHEN t.tgtype & 2 = 2 THEN 'BEFORE'
+				ELSE 'AFTER'
+			END as timing,
+			array_to_string(ARRAY[
+				CASE WHEN t.tgtype & 4 = 4 THEN 'INSERT' END,
+				CASE WHEN t.tgtype & 8 = 8 THEN 'DELETE' END,
+				CASE WHEN t.tgtype & 16 = 16 THEN 'UPDATE' END,
+				CASE WHEN t.tgtype & 32 = 32 THEN 'TRUNCATE' END
+			]::text[], ' OR ') as event,
+			p.proname as function_name,
+			CASE WHEN p.pronamespace <> n.oid THEN p.pronamespace::regnamespace::text ELSE '' END as function_schema,
+			c.relkind IN ('r', 'p') as on_table,
+			COALESCE(t.tgoldtable::text, '') as old_table,
+			COALESCE(t.tgnewtable::text, '') as new_table,
+			CASE WHEN t.tgtype & 1 = 1 THEN 'ROW' ELSE 'STATEMENT' END as level,
+			COALESCE((
+				SELECT array_agg(a.attname::text ORDER BY k.i)
+				FROM unnest(t.tgattr) WITH ORDINALITY k(attnum, i)
+				JOIN pg_attribute a ON a.attrelid = t.tgrelid AND a.attnum = k.attnum
+			), '{}') as columns,
+			pg_get_triggerdef(t.oid) as definition
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_proc p ON p.oid = t.tgfoid
+		WHERE n.nspname = $1
+		  AND NOT t.tgisinternal
+		  AND NOT c.relispartition
+		ORDER BY c.relname, t.tgname`
+
+	rows, err := conn.Query(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []Trigger
+	for rows.Next() {
+		var trig Trigger
+		trig.Schema = schema
+		var onTable bool
+		if err := rows.Scan(&trig.Table, &trig.Name, &trig.Timing, &trig.Event, &trig.Function, &trig.FunctionSchema, &onTable, &trig.OldTable, &trig.NewTable,
+			&trig.Level, &trig.Columns, &trig.Definition); err != nil {
+			return nil, err
+		}
+		trig.When = triggerWhen(trig.Definition)
+		if !onTable || keep(schema, trig.Table) {
+			triggers = append(triggers, trig)
+		}
+	}
+
+	return triggers, nil
+}
+
+// triggerWhen extracts the WHEN condition from a pg_get_triggerdef
+// statement, which always parenthesizes it and ends with EXECUTE.
+func triggerWhen(def string) string {
+	start := strings.Index(def, " WHEN (")
+	end := strings.LastIndex(def, " EXECUTE ")
+	if start < 0 || end < start {
+		return ""
+	}
+	cond := strings.TrimSpace(def[start+len(" WHEN ") : end])
+	return strings.TrimSuffix(strings.TrimPrefix(cond, "("), ")")
+}
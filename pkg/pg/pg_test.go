@@ -0,0 +1,727 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestParseSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single schema",
+			input:    "public",
+			expected: []string{"public"},
+		},
+		{
+			name:     "multiple schemas",
+			input:    "public,auth,api",
+			expected: []string{"public", "auth", "api"},
+		},
+		{
+			name:     "with spaces",
+			input:    "public, auth , api",
+			expected: []string{"public", "auth", "api"},
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "only commas",
+			input:    ",,,",
+			expected: nil,
+		},
+		{
+			name:     "trailing comma",
+			input:    "public,auth,",
+			expected: []string{"public", "auth"},
+		},
+		{
+			name:     "leading comma",
+			input:    ",public,auth",
+			expected: []string{"public", "auth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseSchemas(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseSchemas(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMajorVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"16.2 (Debian 16.2-1.pgdg120+2)", 16},
+		{"9.6.24", 9},
+		{"14beta1", 14},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseMajorVersion(tt.input); got != tt.expected {
+			t.Errorf("parseMajorVersion(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSetFKRefs(t *testing.T) {
+	table := Table{
+		Columns: []Column{{Name: "order_id"}, {Name: "tenant_id"}, {Name: "note"}},
+		ForeignKeys: []ForeignKey{
+			{
+				Name:       "lines_order_fkey",
+				Columns:    []string{"tenant_id", "order_id"},
+				RefSchema:  "public",
+				RefTable:   "orders",
+				RefColumns: []string{"tenant_id", "id"},
+				OnDelete:   "CASCADE",
+				OnUpdate:   "NO ACTION",
+			},
+		},
+	}
+
+	setFKRefs(&table)
+
+	expected := []string{"public.orders.id", "public.orders.tenant_id", ""}
+	for i, col := range table.Columns {
+		if col.FKRef != expected[i] {
+			t.Errorf("%s.FKRef = %q, want %q", col.Name, col.FKRef, expected[i])
+		}
+	}
+	if got := table.Columns[0].FKOnDelete; got != "CASCADE" {
+		t.Errorf("FKOnDelete = %q, want CASCADE", got)
+	}
+	if got := table.Columns[0].FKOnUpdate; got != "" {
+		t.Errorf("FKOnUpdate = %q, want empty for NO ACTION", got)
+	}
+}
+
+func TestDatabaseHash(t *testing.T) {
+	db := Database{Schemas: []SchemaInfo{{
+		Name:      "public",
+		Tables:    []Table{{Schema: "public", Name: "users"}},
+		Sequences: []Sequence{{Schema: "public", Name: "users_id_seq"}},
+	}}}
+
+	first, err := db.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != len("sha256:")+64 {
+		t.Errorf("unexpected hash format %q", first)
+	}
+
+	db.Storage = &Storage{LargeObjects: 3}
+	if again, _ := db.Hash(); again != first {
+		t.Error("storage statistics should not affect the hash")
+	}
+
+	lastValue := int64(42)
+	db.Schemas[0].Sequences[0].LastValue = &lastValue
+	if again, _ := db.Hash(); again != first {
+		t.Error("sequence values should not affect the hash")
+	}
+	if db.Schemas[0].Sequences[0].LastValue == nil {
+		t.Error("Hash should not modify the database")
+	}
+
+	db.Schemas[0].Warnings = []Warning{{Kind: WarningPermission, Object: "users_id_seq", Message: "last_value is not readable"}}
+	if again, _ := db.Hash(); again != first {
+		t.Error("warnings should not affect the hash")
+	}
+
+	db.Schemas[0].Tables[0].Size = &TableSize{TotalBytes: 8192, TableBytes: 8192, EstimatedRows: 10}
+	if again, _ := db.Hash(); again != first {
+		t.Error("table sizes should not affect the hash")
+	}
+	if db.Schemas[0].Tables[0].Size == nil {
+		t.Error("Hash should not modify the tables")
+	}
+
+	db.Schemas[0].Tables[0].Name = "accounts"
+	if changed, _ := db.Hash(); changed == first {
+		t.Error("hash should change with the schema")
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	db := Database{Schemas: []SchemaInfo{{
+		Name: "public",
+		Tables: []Table{{
+			Schema:  "public",
+			Name:    "users",
+			Columns: []Column{{Name: "id", Position: 1, Type: "uuid", IsPK: true}},
+		}},
+	}}}
+
+	data, err := MarshalDocument(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"format_version": 1`, `"is_pk": true`, `"nullable": false`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in document:\n%s", want, data)
+		}
+	}
+
+	got, err := UnmarshalDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, db) {
+		t.Errorf("round trip = %+v, want %+v", got, db)
+	}
+
+	if _, err := UnmarshalDocument([]byte(`{"format_version": 99}`)); err == nil {
+		t.Error("expected error for unknown format version")
+	}
+}
+
+func TestMarshalSnapshotCanonical(t *testing.T) {
+	lastValue := int64(42)
+	schemas := []SchemaInfo{
+		{
+			Name:      "public",
+			Tables:    []Table{{Schema: "public", Name: "users"}, {Schema: "public", Name: "accounts"}},
+			Sequences: []Sequence{{Schema: "public", Name: "users_id_seq", LastValue: &lastValue}},
+			Warnings:  []Warning{{Kind: WarningSkipped, Object: "remote", Message: "foreign table is not documented"}},
+		},
+		{Name: "auth"},
+	}
+
+	data, err := MarshalSnapshot(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	if strings.Index(text, `"auth"`) > strings.Index(text, `"public"`) {
+		t.Error("schemas should be sorted by name")
+	}
+	if strings.Index(text, `"accounts"`) > strings.Index(text, `"users"`) {
+		t.Error("tables should be sorted by name")
+	}
+	for _, unwanted := range []string{"last_value", "warnings"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("snapshot should leave out %s:\n%s", unwanted, text)
+		}
+	}
+	if schemas[0].Tables[0].Name != "users" || schemas[0].Sequences[0].LastValue == nil {
+		t.Error("MarshalSnapshot should not modify its input")
+	}
+
+	schemas[0].Tables[0], schemas[0].Tables[1] = schemas[0].Tables[1], schemas[0].Tables[0]
+	again, err := MarshalSnapshot(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != text {
+		t.Error("catalog order should not change the snapshot")
+	}
+}
+
+func TestSchemaGaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		exists   bool
+		usage    bool
+		hidden   []string
+		expected []string
+	}{
+		{"visible", true, true, nil, nil},
+		{"missing", false, false, nil, []string{"schema app does not exist"}},
+		{"no usage", true, false, nil, []string{"no USAGE privilege on schema app; its objects cannot be listed"}},
+		{"hidden tables", true, true, []string{"payroll", "salaries"}, []string{"no privileges on 2 relation(s) in schema app, which will be missing: payroll, salaries"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaGaps("app", tt.exists, tt.usage, tt.hidden); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("schemaGaps() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortSchema(t *testing.T) {
+	info := SchemaInfo{
+		Tables: []Table{
+			{
+				Name:         "users",
+				Columns:      []Column{{Name: "email", Position: 2}, {Name: "id", Position: 1}},
+				Indexes:      []Index{{Name: "users_pkey", Columns: []string{"tenant_id", "id"}}, {Name: "users_email_key"}},
+				Publications: []string{"wal", "audit"},
+			},
+			{Name: "Accounts"},
+		},
+		Triggers: []Trigger{{Table: "users", Name: "b"}, {Table: "orders", Name: "z"}, {Table: "users", Name: "a"}},
+		Functions: []Function{
+			{Name: "touch", Arguments: "id integer"},
+			{Name: "touch", Arguments: ""},
+		},
+		Types: []CustomType{
+			{Name: "address", Kind: "composite", Values: []string{"street text", "city text"}},
+			{Name: "status", Kind: "enum", Values: []string{"pending", "active"}},
+		},
+	}
+
+	sortSchema(&info)
+
+	if info.Tables[0].Name != "Accounts" {
+		t.Errorf("expected byte-wise table order, got %s first", info.Tables[0].Name)
+	}
+	users := info.Tables[1]
+	if users.Columns[0].Name != "id" || users.Indexes[0].Name != "users_email_key" || users.Publications[0] != "audit" {
+		t.Errorf("table collections not sorted: %+v", users)
+	}
+	if !reflect.DeepEqual(users.Indexes[1].Columns, []string{"tenant_id", "id"}) {
+		t.Error("index column order must be preserved")
+	}
+	if info.Triggers[0].Table != "orders" || info.Triggers[1].Name != "a" {
+		t.Errorf("triggers not sorted: %+v", info.Triggers)
+	}
+	if info.Functions[0].Arguments != "" {
+		t.Errorf("overloads not sorted by arguments: %+v", info.Functions)
+	}
+	if info.Types[0].Name != "status" || !reflect.DeepEqual(info.Types[0].Values, []string{"pending", "active"}) {
+		t.Errorf("expected enums first with label order preserved: %+v", info.Types)
+	}
+	if !reflect.DeepEqual(info.Types[1].Values, []string{"street text", "city text"}) {
+		t.Error("composite field order must be preserved")
+	}
+}
+
+func TestSortSchemaTypeDependencies(t *testing.T) {
+	info := SchemaInfo{
+		Name: "public",
+		Types: []CustomType{
+			{Schema: "public", Name: "address", Kind: "composite", Attributes: []Column{
+				{Name: "street", Type: "text"},
+				{Name: "point", Type: "geo.\"Lat Lng\""},
+			}},
+			{Schema: "public", Name: "customer", Kind: "composite", Attributes: []Column{
+				{Name: "home", Type: "zz_location"},
+				{Name: "tier", Type: "tier"},
+			}},
+			{Schema: "public", Name: "tier", Kind: "enum", Values: []string{"free", "paid"}},
+			{Schema: "public", Name: "zz_location", Kind: "composite", Attributes: []Column{
+				{Name: "addresses", Type: "address[]"},
+			}},
+			{Schema: "public", Name: "zz_loop_a", Kind: "composite", Attributes: []Column{{Name: "b", Type: "zz_loop_b"}}},
+			{Schema: "public", Name: "zz_loop_b", Kind: "composite", Attributes: []Column{{Name: "a", Type: "public.zz_loop_a"}}},
+		},
+	}
+
+	sortSchema(&info)
+
+	var names []string
+	for _, ct := range info.Types {
+		names = append(names, ct.Name)
+	}
+	expected := []string{"tier", "address", "zz_location", "customer", "zz_loop_a", "zz_loop_b"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("type order = %v, want %v", names, expected)
+	}
+}
+
+func TestReferencedType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		expected string
+	}{
+		{"address", "public.address"},
+		{"address[]", "public.address"},
+		{"geo.point", "geo.point"},
+		{`geo."Lat ""Lng"""[]`, `geo.Lat "Lng"`},
+		{`"my.type"`, "public.my.type"},
+	}
+
+	for _, tt := range tests {
+		if got := ReferencedType("public", tt.typ); got != tt.expected {
+			t.Errorf("ReferencedType(%q) = %q, want %q", tt.typ, got, tt.expected)
+		}
+	}
+}
+
+func TestTriggerWhen(t *testing.T) {
+	tests := []struct {
+		def      string
+		expected string
+	}{
+		{"CREATE TRIGGER t BEFORE UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION touch()", ""},
+		{"CREATE TRIGGER t BEFORE UPDATE ON public.users FOR EACH ROW WHEN ((old.* IS DISTINCT FROM new.*)) EXECUTE FUNCTION touch()", "(old.* IS DISTINCT FROM new.*)"},
+		{"CREATE TRIGGER t AFTER INSERT ON public.log FOR EACH ROW WHEN ((new.msg = ' EXECUTE ')) EXECUTE FUNCTION f()", "(new.msg = ' EXECUTE ')"},
+	}
+
+	for _, tt := range tests {
+		if got := triggerWhen(tt.def); got != tt.expected {
+			t.Errorf("triggerWhen(%q) = %q, want %q", tt.def, got, tt.expected)
+		}
+	}
+}
+
+func TestAttachRefreshes(t *testing.T) {
+	matviews := []MaterializedView{
+		{Schema: "public", Name: "totals"},
+		{Schema: "reports", Name: "Daily"},
+		{Schema: "public", Name: "stale"},
+	}
+	jobs := []cronJob{
+		{name: "totals", schedule: "*/15 * * * *", command: "REFRESH MATERIALIZED VIEW CONCURRENTLY totals"},
+		{name: "nightly", schedule: "0 3 * * *", command: "SELECT reports.refresh_all()"},
+	}
+	functions := []refreshFunction{{
+		schema:   "reports",
+		name:     "refresh_all",
+		source:   `BEGIN REFRESH MATERIALIZED VIEW "Daily"; refresh materialized view public.totals; END`,
+		triggers: []string{"public.orders.orders_refresh"},
+	}}
+	attachRefreshes(matviews, jobs, functions)
+
+	want := [][]Refresh{
+		{
+			{Kind: "cron", Name: "nightly", Schedule: "0 3 * * *", Function: "reports.refresh_all"},
+			{Kind: "cron", Name: "totals", Schedule: "*/15 * * * *", Concurrently: true},
+			{Kind: "trigger", Name: "public.orders.orders_refresh", Function: "reports.refresh_all"},
+		},
+		{
+			{Kind: "cron", Name: "nightly", Schedule: "0 3 * * *", Function: "reports.refresh_all"},
+			{Kind: "trigger", Name: "public.orders.orders_refresh", Function: "reports.refresh_all"},
+		},
+		nil,
+	}
+	for i, mv := range matviews {
+		if !reflect.DeepEqual(mv.Refreshes, want[i]) {
+			t.Errorf("%s refreshes = %+v, want %+v", mv.Name, mv.Refreshes, want[i])
+		}
+	}
+}
+
+func TestFetchOptionsTableFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     FetchOptions
+		schema   string
+		table    string
+		expected bool
+	}{
+		{"no patterns", FetchOptions{}, "public", "users", true},
+		{"glob exclude", FetchOptions{ExcludeTables: []string{"log_*"}}, "public", "log_2024", false},
+		{"glob exclude miss", FetchOptions{ExcludeTables: []string{"log_*"}}, "public", "users", true},
+		{"include miss", FetchOptions{IncludeTables: []string{"user*"}}, "public", "orders", false},
+		{"include then exclude", FetchOptions{IncludeTables: []string{"user*"}, ExcludeTables: []string{"users_archive"}}, "public", "users_archive", false},
+		{"regex", FetchOptions{ExcludeTables: []string{`re:_p\d{8}$`}}, "public", "events_p20240101", false},
+		{"qualified glob", FetchOptions{ExcludeTables: []string{"audit.*"}}, "audit", "users", false},
+		{"qualified glob other schema", FetchOptions{ExcludeTables: []string{"audit.*"}}, "public", "users", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, err := tt.opts.tableFilter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := keep(tt.schema, tt.table); got != tt.expected {
+				t.Errorf("keep(%s.%s) = %v, want %v", tt.schema, tt.table, got, tt.expected)
+			}
+		})
+	}
+
+	for _, bad := range []string{"re:(", "[a-"} {
+		if err := (FetchOptions{IncludeTables: []string{bad}}).Validate(); err == nil {
+			t.Errorf("expected error for pattern %q", bad)
+		}
+	}
+	for _, bad := range []FetchOptions{{MaxConcurrency: -1}, {QueryDelay: -time.Second}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
+
+func TestFilterTables(t *testing.T) {
+	schemas := []SchemaInfo{{
+		Name:     "public",
+		Tables:   []Table{{Name: "users"}, {Name: "log_2024"}},
+		Triggers: []Trigger{{Table: "users", Name: "a"}, {Table: "log_2024", Name: "b"}},
+	}}
+
+	got, err := FilterTables(schemas, FetchOptions{ExcludeTables: []string{"log_*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got[0].Tables) != 1 || got[0].Tables[0].Name != "users" {
+		t.Errorf("unexpected tables %+v", got[0].Tables)
+	}
+	if len(got[0].Triggers) != 1 || got[0].Triggers[0].Name != "a" {
+		t.Errorf("unexpected triggers %+v", got[0].Triggers)
+	}
+	if len(schemas[0].Tables) != 2 {
+		t.Error("input should not be modified")
+	}
+}
+
+func TestParseEnumComment(t *testing.T) {
+	values := []string{"pending", "paid", "refunded"}
+	comment := "Order lifecycle.\n\n- pending: awaiting payment\npaid: payment captured\nSee billing docs: /docs/billing"
+
+	description, annotations := parseEnumComment(comment, values)
+
+	if description != "Order lifecycle.\n\nSee billing docs: /docs/billing" {
+		t.Errorf("description = %q", description)
+	}
+	expected := map[string]string{"pending": "awaiting payment", "paid": "payment captured"}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("annotations = %v, want %v", annotations, expected)
+	}
+
+	if _, annotations := parseEnumComment("Plain comment", values); annotations != nil {
+		t.Errorf("expected no annotations, got %v", annotations)
+	}
+}
+
+func TestOutputColumns(t *testing.T) {
+	args := []Argument{
+		{Name: "q", Type: "text", Mode: "IN"},
+		{Name: "id", Type: "bigint", Mode: "TABLE"},
+		{Name: "title", Type: "text", Mode: "TABLE"},
+	}
+	expected := []Column{
+		{Name: "id", Position: 1, Type: "bigint", Nullable: true},
+		{Name: "title", Position: 2, Type: "text", Nullable: true},
+	}
+	if got := outputColumns(args); !reflect.DeepEqual(got, expected) {
+		t.Errorf("outputColumns() = %+v, want %+v", got, expected)
+	}
+
+	single := []Argument{{Name: "x", Type: "integer", Mode: "IN"}, {Name: "total", Type: "bigint", Mode: "OUT"}}
+	if got := outputColumns(single); got != nil {
+		t.Errorf("expected a single OUT parameter to be a scalar, got %+v", got)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		comment     string
+		description string
+		tags        []string
+	}{
+		{"Active users. @tag api", "Active users.", []string{"api"}},
+		{"@tags api, billing\nInvoices per customer", "Invoices per customer", []string{"api", "billing"}},
+		{"@tag api @tag api", "", []string{"api"}},
+		{"Contact support@example.com", "Contact support@example.com", nil},
+	}
+
+	for _, tt := range tests {
+		description, tags := parseTags(tt.comment)
+		if description != tt.description || !reflect.DeepEqual(tags, tt.tags) {
+			t.Errorf("parseTags(%q) = %q, %v; want %q, %v", tt.comment, description, tags, tt.description, tt.tags)
+		}
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	tests := []struct {
+		comment     string
+		description string
+		terms       []Term
+	}{
+		{"Products for sale.\n@term SKU: stock keeping unit", "Products for sale.", []Term{{"SKU", "stock keeping unit"}}},
+		{"@term MRR : monthly recurring revenue\n@term ARR: annual recurring revenue", "", []Term{{"MRR", "monthly recurring revenue"}, {"ARR", "annual recurring revenue"}}},
+		{"Ratio: 1:2", "Ratio: 1:2", nil},
+	}
+
+	for _, tt := range tests {
+		description, terms := parseTerms(tt.comment)
+		if description != tt.description || !reflect.DeepEqual(terms, tt.terms) {
+			t.Errorf("parseTerms(%q) = %q, %v; want %q, %v", tt.comment, description, terms, tt.description, tt.terms)
+		}
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	schemas := []SchemaInfo{{
+		Name: "public",
+		Tables: []Table{
+			{Schema: "public", Name: "users", Tags: []string{"api"}},
+			{Schema: "public", Name: "audit_log"},
+		},
+		Views:     []View{{Schema: "public", Name: "active_users", Tags: []string{"internal", "api"}}},
+		Sequences: []Sequence{{Schema: "public", Name: "users_id_seq"}},
+		Triggers: []Trigger{
+			{Schema: "public", Table: "users", Name: "users_touch"},
+			{Schema: "public", Table: "audit_log", Name: "audit_log_touch"},
+		},
+		Functions: []Function{{Schema: "public", Name: "purge", Tags: []string{"internal"}}},
+	}}
+
+	got := FilterByTag(schemas, []string{"api"})[0]
+
+	if len(got.Tables) != 1 || got.Tables[0].Name != "users" {
+		t.Errorf("Tables = %+v", got.Tables)
+	}
+	if len(got.Views) != 1 {
+		t.Errorf("Views = %+v", got.Views)
+	}
+	if len(got.Triggers) != 1 || got.Triggers[0].Name != "users_touch" {
+		t.Errorf("Triggers = %+v", got.Triggers)
+	}
+	if len(got.Sequences) != 0 || len(got.Functions) != 0 {
+		t.Errorf("expected untagged sequence and internal function to be dropped, got %+v", got)
+	}
+}
+
+func TestFilterExternal(t *testing.T) {
+	schemas := []SchemaInfo{{
+		Name: "public",
+		Tables: []Table{
+			{Schema: "public", Name: "products", Tags: []string{"external"}},
+			{Schema: "public", Name: "audit_log"},
+		},
+		Views:             []View{{Schema: "public", Name: "catalog"}},
+		MaterializedViews: []MaterializedView{{Schema: "public", Name: "sales_by_day"}},
+		Functions:         []Function{{Schema: "public", Name: "purge"}},
+	}}
+
+	got := FilterExternal(schemas, []string{"external"})[0]
+
+	if len(got.Tables) != 1 || got.Tables[0].Name != "products" {
+		t.Errorf("Tables = %+v", got.Tables)
+	}
+	if len(got.Views) != 1 || len(got.MaterializedViews) != 1 {
+		t.Errorf("expected all views to be kept, got %+v, %+v", got.Views, got.MaterializedViews)
+	}
+	if len(got.Functions) != 0 {
+		t.Errorf("expected untagged function to be dropped, got %+v", got.Functions)
+	}
+	if len(schemas[0].Tables) != 2 {
+		t.Error("FilterExternal should not modify its input")
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		sql     string
+		wantErr string
+	}{
+		{"\n\t\tSELECT n.nspname\n\t\tFROM pg_namespace n", ""},
+		{"-- leading comment\nWITH t AS (SELECT 1) SELECT * FROM t;", ""},
+		{"select 1 -- ; not a second statement", ""},
+		{"SHOW server_version", ""},
+		{"/* hint */ DELETE FROM users", "refusing DELETE statement"},
+		{"SET default_transaction_read_only = off", "refusing SET statement"},
+		{"SELECT 1; DROP TABLE users", "multi-statement"},
+		{"SELECT '--'; DELETE FROM t", "multi-statement"},
+		{"SELECT '/*'; DELETE FROM t; -- */", "multi-statement"},
+		{"SELECT E'\\''; DELETE FROM t; --'", "multi-statement"},
+		{"SELECT $$--$$; DELETE FROM t", "multi-statement"},
+		{"SELECT ';' AS semicolon, \"a;b\" FROM t", ""},
+		{"SELECT $tag$; DELETE FROM t$tag$", ""},
+		{"SELECT 1 /* outer /* inner */ ; DELETE */", ""},
+		{"SELECT 1;;", ""},
+		{"  ", "empty statement"},
+	}
+	for _, tt := range tests {
+		err := checkReadOnly(tt.sql)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("checkReadOnly(%q) = %v, want nil", tt.sql, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("checkReadOnly(%q) = %v, want error containing %q", tt.sql, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReadOnlyConfig(t *testing.T) {
+	config, err := ReadOnlyConfig("postgres://user@localhost/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := config.RuntimeParams["default_transaction_read_only"]; got != "on" {
+		t.Errorf("default_transaction_read_only = %q, want on", got)
+	}
+}
+
+func TestFetcherPoolConfig(t *testing.T) {
+	for _, readOnly := range []bool{false, true} {
+		config, err := poolConfig("postgres://user@localhost/app", FetchOptions{ReadOnly: readOnly})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := config.ConnConfig.RuntimeParams["default_transaction_read_only"]
+		if want := map[bool]string{true: "on"}[readOnly]; got != want {
+			t.Errorf("ReadOnly %v: default_transaction_read_only = %q, want %q", readOnly, got, want)
+		}
+	}
+}
+
+type stubQuerier struct {
+	queries int
+	last    string
+}
+
+func (q *stubQuerier) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	q.queries++
+	q.last = sql
+	return nil, nil
+}
+
+func (q *stubQuerier) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	q.queries++
+	q.last = sql
+	return nil
+}
+
+func TestPaced(t *testing.T) {
+	stub := &stubQuerier{}
+	conn := Paced(stub, 20*time.Millisecond)
+
+	start := time.Now()
+	conn.Query(context.Background(), "SELECT 1")
+	conn.QueryRow(context.Background(), "SELECT 1")
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("two queries took %v, want at least 40ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := conn.Query(ctx, "SELECT 1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Query() error = %v, want context.Canceled", err)
+	}
+	if stub.queries != 2 {
+		t.Errorf("queries = %d, want 2", stub.queries)
+	}
+}
+
+func TestCommented(t *testing.T) {
+	stub := &stubQuerier{}
+	conn := Commented(stub, "pgmd/1.2.0 */ DROP")
+
+	conn.Query(context.Background(), "SELECT 1")
+	if want := "/* pgmd/1.2.0 * / DROP */\nSELECT 1"; stub.last != want {
+		t.Errorf("query = %q, want %q", stub.last, want)
+	}
+	if err := checkReadOnly(stub.last); err != nil {
+		t.Errorf("commented query rejected in read-only mode: %v", err)
+	}
+}
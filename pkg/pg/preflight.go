@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Preflight checks what the connected role can see in each schema and
+// describes every gap that would silently shrink the document, such as
+// a missing USAGE grant or tables hidden from information_schema.
+func Preflight(ctx context.Context, conn Querier, schemas []string) ([]string, error) {
+	query := `
+		SELECT
+			s.name,
+			n.oid IS NOT NULL as exists,
+			COALESCE(has_schema_privilege(n.oid, 'USAGE'), false) as usage,
+			ARRAY(
+				SELECT c.relname::text
+				FROM pg_class c
+				WHERE c.relnamespace = n.oid
+				  AND c.relkind IN ('r', 'p', 'v', 'f')
+				  AND NOT pg_has_role(c.relowner, 'USAGE')
+				  AND NOT has_table_privilege(c.oid, 'SELECT, INSERT, UPDATE, DELETE, TRUNCATE, REFERENCES, TRIGGER')
+				  AND NOT has_any_column_privilege(c.oid, 'SELECT, INSERT, UPDATE, REFERENCES')
+				ORDER BY c.relname
+			) as hidden
+		FROM unnest($1::text[]) WITH ORDINALITY s(name, ord)
+		LEFT JOIN pg_namespace n ON n.nspname = s.name
+		ORDER BY s.ord`
+
+	rows, err := conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gaps []string
+	for rows.Next() {
+		var name string
+		var exists, usage bool
+		var hidden []string
+		if err := rows.Scan(&name, &exists, &usage, &hidden); err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, schemaGaps(name, exists, usage, hidden)...)
+	}
+
+	return gaps, nil
+}
+
+func schemaGaps(schema string, exists, usage bool, hidden []string) []string {
+	switch {
+	case !exists:
+		return []string{fmt.Sprintf("schema %s does not exist", schema)}
+	case !usage:
+		return []string{fmt.Sprintf("no USAGE privilege on schema %s; its objects cannot be listed", schema)}
+	case len(hidden) > 0:
+		return []string{fmt.Sprintf("no privileges on %d relation(s) in schema %s, which will be missing: %s", len(hidden), schema, strings.Join(hidden, ", "))}
+	}
+	return nil
+}
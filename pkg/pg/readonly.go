@@ -0,0 +1,90 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sotirismorf/pgmd/internal/sqltext"
+)
+
+// ReadOnly wraps conn so that any statement other than a single SELECT,
+// WITH ... SELECT or SHOW fails before reaching the server. Pair it with a
+// session opened through ReadOnlyConfig, which makes PostgreSQL itself
+// reject writes, including data-modifying CTEs this check lets through.
+func ReadOnly(conn Querier) Querier {
+	return readOnlyQuerier{conn}
+}
+
+// ReadOnlyConfig parses connString and sets default_transaction_read_only
+// for every session opened with it.
+func ReadOnlyConfig(connString string) (*pgx.ConnConfig, error) {
+	config, err := pgx.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	SetReadOnlyParams(config.RuntimeParams)
+	return config, nil
+}
+
+// SetReadOnlyParams sets the runtime parameters of a read-only session on
+// params, such as a pool config's ConnConfig.RuntimeParams: every
+// transaction is read-only, and standard_conforming_strings is pinned so
+// the server reads literals the way ReadOnly does.
+func SetReadOnlyParams(params map[string]string) {
+	params["default_transaction_read_only"] = "on"
+	params["standard_conforming_strings"] = "on"
+}
+
+type readOnlyQuerier struct {
+	conn Querier
+}
+
+func (q readOnlyQuerier) unwrap() Querier {
+	return q.conn
+}
+
+func (q readOnlyQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := checkReadOnly(sql); err != nil {
+		return nil, err
+	}
+	return q.conn.Query(ctx, sql, args...)
+}
+
+func (q readOnlyQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := checkReadOnly(sql); err != nil {
+		return errRow{err}
+	}
+	return q.conn.QueryRow(ctx, sql, args...)
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// checkReadOnly rejects sql unless it is one statement whose first
+// keyword is SELECT, WITH or SHOW. Statements are split outside string
+// literals, quoted identifiers and comments, so a "--" or ";" inside a
+// literal cannot hide a second statement.
+func checkReadOnly(sql string) error {
+	statements := sqltext.Statements(sql)
+	switch len(statements) {
+	case 0:
+		return fmt.Errorf("read-only mode: refusing empty statement")
+	case 1:
+	default:
+		return fmt.Errorf("read-only mode: refusing multi-statement query")
+	}
+	fields := strings.Fields(statements[0])
+	keyword := strings.ToUpper(fields[0])
+	if keyword != "SELECT" && keyword != "WITH" && keyword != "SHOW" {
+		return fmt.Errorf("read-only mode: refusing %s statement", keyword)
+	}
+	return nil
+}
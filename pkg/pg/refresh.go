@@ -0,0 +1,211 @@
+package pg
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sotirismorf/pgmd/internal/sqltext"
+)
+
+// Refresh is a way a materialized view is kept up to date: a pg_cron job
+// or a trigger that runs REFRESH MATERIALIZED VIEW, directly or through a
+// function. Under pg_cron's row-level security a role other than a
+// superuser sees only its own jobs, so the list may be incomplete.
+type Refresh struct {
+	// Kind is "cron" or "trigger".
+	Kind string `json:"kind"`
+	// Name is the cron job's name, or the trigger's as "schema.table.name".
+	Name string `json:"name"`
+	// Schedule is a cron job's schedule, such as "*/15 * * * *".
+	Schedule string `json:"schedule,omitempty"`
+	// Function is the schema-qualified function that runs the refresh,
+	// when a job or trigger does not run it itself.
+	Function     string `json:"function,omitempty"`
+	Concurrently bool   `json:"concurrently,omitempty"`
+}
+
+// cronJob is a pg_cron job scheduled in the current database.
+type cronJob struct {
+	name, schedule, command string
+}
+
+// refreshFunction is a function whose body refreshes materialized views,
+// with the triggers that run it as "schema.table.name".
+type refreshFunction struct {
+	schema, name, source string
+	triggers             []string
+}
+
+var refreshPattern = regexp.MustCompile(`(?i)\brefresh\s+materialized\s+view\s+(concurrently\s+)?((?:"[^"]+"|[a-z_][\w$]*)(?:\s*\.\s*(?:"[^"]+"|[a-z_][\w$]*))?)`)
+
+// refreshTarget is a materialized view that a command refreshes.
+type refreshTarget struct {
+	view         string
+	concurrently bool
+}
+
+// refreshedViews lists the materialized views sql refreshes, as
+// "schema.name". Unqualified names are taken to be in schema.
+func refreshedViews(sql, schema string) []refreshTarget {
+	var targets []refreshTarget
+	for _, m := range refreshPattern.FindAllStringSubmatch(sql, -1) {
+		parts := strings.SplitN(m[2], ".", 2)
+		view := schema + "." + sqltext.Identifier(parts[0])
+		if len(parts) == 2 {
+			view = sqltext.Identifier(parts[0]) + "." + sqltext.Identifier(parts[1])
+		}
+		targets = append(targets, refreshTarget{view: view, concurrently: m[1] != ""})
+	}
+	return targets
+}
+
+// callsFunction reports whether sql calls fn by name.
+func callsFunction(sql string, fn refreshFunction) bool {
+	pattern := `(?i)(^|[^\w$."])(` + regexp.QuoteMeta(fn.schema) + `\s*\.\s*)?"?` + regexp.QuoteMeta(fn.name) + `"?\s*\(`
+	return regexp.MustCompile(pattern).MatchString(sql)
+}
+
+// attachRefreshes sets Refreshes on each of matviews from the cron jobs
+// and refreshing functions found. A job's command counts when it
+// refreshes a view itself or calls one of functions; cron commands run
+// with the default search_path, so their unqualified names are taken to
+// be in public.
+func attachRefreshes(matviews []MaterializedView, jobs []cronJob, functions []refreshFunction) {
+	refreshes := make(map[string][]Refresh)
+	add := func(targets []refreshTarget, r Refresh) {
+		for _, t := range targets {
+			r.Concurrently = t.concurrently
+			if !slices.Contains(refreshes[t.view], r) {
+				refreshes[t.view] = append(refreshes[t.view], r)
+			}
+		}
+	}
+
+	for _, job := range jobs {
+		add(refreshedViews(job.command, "public"), Refresh{Kind: "cron", Name: job.name, Schedule: job.schedule})
+		for _, fn := range functions {
+			if callsFunction(job.command, fn) {
+				add(refreshedViews(fn.source, fn.schema), Refresh{Kind: "cron", Name: job.name, Schedule: job.schedule, Function: fn.schema + "." + fn.name})
+			}
+		}
+	}
+	for _, fn := range functions {
+		for _, trig := range fn.triggers {
+			add(refreshedViews(fn.source, fn.schema), Refresh{Kind: "trigger", Name: trig, Function: fn.schema + "." + fn.name})
+		}
+	}
+
+	for i := range matviews {
+		list := refreshes[matviews[i].Schema+"."+matviews[i].Name]
+		sort.SliceStable(list, func(a, b int) bool {
+			if list[a].Kind != list[b].Kind {
+				return list[a].Kind < list[b].Kind
+			}
+			return list[a].Name < list[b].Name
+		})
+		matviews[i].Refreshes = list
+	}
+}
+
+// refreshSources are the pg_cron jobs and refreshing functions of the
+// database, fetched once for all schemas.
+type refreshSources struct {
+	jobs      []cronJob
+	functions []refreshFunction
+	// warning is set when the connected role cannot read cron.job.
+	warning *Warning
+}
+
+// fetchRefreshSources reads the pg_cron jobs of the current database,
+// when the extension is installed and cron.job is readable, and the
+// functions that refresh a materialized view with the triggers that run
+// them. pg_cron puts row-level security on cron.job, so a role other than
+// a superuser sees only its own jobs and refreshes scheduled by other
+// roles are missing.
+func fetchRefreshSources(ctx context.Context, conn Querier) (*refreshSources, error) {
+	sources := &refreshSources{}
+
+	// Look cron.job up by name in the catalogs: to_regclass and the
+	// privilege functions fail outright on a schema the role cannot use.
+	rows, err := conn.Query(ctx, `
+		SELECT has_schema_privilege(n.oid, 'USAGE'), has_table_privilege(c.oid, 'SELECT')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'cron'
+		  AND c.relname = 'job'`)
+	if err != nil {
+		return nil, err
+	}
+	var hasCron, readable bool
+	for rows.Next() {
+		var usage, selectable bool
+		if err := rows.Scan(&usage, &selectable); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		hasCron, readable = true, usage && selectable
+	}
+	rows.Close()
+
+	switch {
+	case hasCron && !readable:
+		sources.warning = &Warning{
+			Kind:    WarningPermission,
+			Object:  "cron.job",
+			Message: "pg_cron jobs are not readable, so the materialized view refreshes they schedule are not listed",
+		}
+	case hasCron:
+		rows, err := conn.Query(ctx, `
+			SELECT COALESCE(jobname, jobid::text), schedule, command
+			FROM cron.job
+			WHERE database = current_database()
+			ORDER BY jobid`)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var job cronJob
+			if err := rows.Scan(&job.name, &job.schedule, &job.command); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			sources.jobs = append(sources.jobs, job)
+		}
+		rows.Close()
+	}
+
+	query := `
+		SELECT
+			n.nspname,
+			p.proname,
+			p.prosrc,
+			COALESCE(array_agg(tn.nspname || '.' || tc.relname || '.' || t.tgname ORDER BY tn.nspname, tc.relname, t.tgname)
+				FILTER (WHERE t.oid IS NOT NULL), '{}') as triggers
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		LEFT JOIN pg_trigger t ON t.tgfoid = p.oid AND NOT t.tgisinternal
+		LEFT JOIN pg_class tc ON tc.oid = t.tgrelid
+		LEFT JOIN pg_namespace tn ON tn.oid = tc.relnamespace
+		WHERE p.prosrc ~* 'refresh\s+materialized\s+view'
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		GROUP BY n.nspname, p.proname, p.prosrc
+		ORDER BY n.nspname, p.proname`
+
+	rows, err = conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fn refreshFunction
+		if err := rows.Scan(&fn.schema, &fn.name, &fn.source, &fn.triggers); err != nil {
+			return nil, err
+		}
+		sources.functions = append(sources.functions, fn)
+	}
+	return sources, nil
+}
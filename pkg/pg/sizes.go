@@ -0,0 +1,61 @@
+package pg
+
+import "context"
+
+// TableSize is a table's on-disk footprint and the planner's row
+// estimate. Partitioned tables add up their partitions. The figures are
+// only as fresh as the last VACUUM or ANALYZE and are left out of
+// Database.Hash.
+type TableSize struct {
+	// TotalBytes includes TOAST data and indexes; TableBytes is the main
+	// data only.
+	TotalBytes    int64 `json:"total_bytes"`
+	TableBytes    int64 `json:"table_bytes"`
+	IndexBytes    int64 `json:"index_bytes"`
+	EstimatedRows int64 `json:"estimated_rows"`
+}
+
+// fetchTableSizes sets Size on each of tables.
+func fetchTableSizes(ctx context.Context, conn Querier, schema string, tables []Table) error {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+
+	query := `
+		SELECT
+			c.relname,
+			COALESCE(sum(pg_total_relation_size(p.relid)), 0)::bigint as total_bytes,
+			COALESCE(sum(pg_relation_size(p.relid)), 0)::bigint as table_bytes,
+			COALESCE(sum(pg_indexes_size(p.relid)), 0)::bigint as index_bytes,
+			COALESCE(sum(GREATEST(pc.reltuples, 0)) FILTER (WHERE p.isleaf), 0)::bigint as estimated_rows
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN LATERAL pg_partition_tree(c.oid) p
+		JOIN pg_class pc ON pc.oid = p.relid
+		WHERE n.nspname = $1
+		  AND c.relname = ANY($2)
+		  AND c.relkind IN ('r', 'p')
+		GROUP BY c.relname`
+
+	rows, err := conn.Query(ctx, query, schema, names)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]*TableSize)
+	for rows.Next() {
+		var name string
+		var size TableSize
+		if err := rows.Scan(&name, &size.TotalBytes, &size.TableBytes, &size.IndexBytes, &size.EstimatedRows); err != nil {
+			return err
+		}
+		sizes[name] = &size
+	}
+
+	for i := range tables {
+		tables[i].Size = sizes[tables[i].Name]
+	}
+	return nil
+}
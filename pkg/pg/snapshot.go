@@ -0,0 +1,51 @@
+package pg
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+)
+
+// MarshalSnapshot encodes schemas as a canonical document meant to be
+// committed and compared byte for byte: schemas and their collections are
+// put in a fixed order, and sequence values and warnings are left out, so
+// the same schema always encodes to the same bytes.
+func MarshalSnapshot(schemas []SchemaInfo) ([]byte, error) {
+	// Round-trip through JSON for a deep copy, since sorting works in place.
+	data, err := json.Marshal(schemas)
+	if err != nil {
+		return nil, err
+	}
+	var canonical []SchemaInfo
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(canonical, func(a, b SchemaInfo) int { return cmp.Compare(a.Name, b.Name) })
+	for i := range canonical {
+		sortSchema(&canonical[i])
+		stripVolatile(&canonical[i])
+	}
+
+	out, err := MarshalDocument(Database{Schemas: canonical})
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// stripVolatile drops what drifts with the data or the connected role
+// rather than the schema: sequence values, table sizes and fetch warnings.
+func stripVolatile(info *SchemaInfo) {
+	seqs := slices.Clone(info.Sequences)
+	for i := range seqs {
+		seqs[i].LastValue = nil
+	}
+	info.Sequences = seqs
+	tables := slices.Clone(info.Tables)
+	for i := range tables {
+		tables[i].Size = nil
+	}
+	info.Tables = tables
+	info.Warnings = nil
+}
@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// tagPattern matches "@tag api" or "@tag api, billing" in a comment.
+var tagPattern = regexp.MustCompile(`@tags?\s+([\w-]+(?:\s*,\s*[\w-]+)*)`)
+
+// parseTags strips @tag markers from a comment, returning the remaining
+// text and the tags in order of appearance.
+func parseTags(comment string) (string, []string) {
+	var tags []string
+	for _, m := range tagPattern.FindAllStringSubmatch(comment, -1) {
+		for _, tag := range strings.Split(m[1], ",") {
+			if tag = strings.TrimSpace(tag); !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if tags == nil {
+		return comment, nil
+	}
+	return strings.TrimSpace(tagPattern.ReplaceAllString(comment, "")), tags
+}
+
+// FilterByTag keeps the tables, views, materialized views, sequences,
+// functions and types carrying at least one of tags, plus the triggers
+// of kept tables. Schemas left empty are kept so the document still
+// shows which schemas were inspected.
+func FilterByTag(schemas []SchemaInfo, tags []string) []SchemaInfo {
+	tagged := func(objectTags []string) bool {
+		return slices.ContainsFunc(objectTags, func(t string) bool { return slices.Contains(tags, t) })
+	}
+
+	result := make([]SchemaInfo, len(schemas))
+	for i, s := range schemas {
+		kept := SchemaInfo{Name: s.Name}
+		tables := make(map[string]bool)
+		for _, t := range s.Tables {
+			if tagged(t.Tags) {
+				kept.Tables = append(kept.Tables, t)
+				tables[t.Name] = true
+			}
+		}
+		for _, v := range s.Views {
+			if tagged(v.Tags) {
+				kept.Views = append(kept.Views, v)
+			}
+		}
+		for _, mv := range s.MaterializedViews {
+			if tagged(mv.Tags) {
+				kept.MaterializedViews = append(kept.MaterializedViews, mv)
+			}
+		}
+		for _, seq := range s.Sequences {
+			if tagged(seq.Tags) {
+				kept.Sequences = append(kept.Sequences, seq)
+			}
+		}
+		for _, trig := range s.Triggers {
+			if tables[trig.Table] {
+				kept.Triggers = append(kept.Triggers, trig)
+			}
+		}
+		for _, fn := range s.Functions {
+			if tagged(fn.Tags) {
+				kept.Functions = append(kept.Functions, fn)
+			}
+		}
+		for _, t := range s.Types {
+			if tagged(t.Tags) {
+				kept.Types = append(kept.Types, t)
+			}
+		}
+		result[i] = kept
+	}
+	return result
+}
+
+// FilterExternal selects what an external-facing document shows: every
+// view and materialized view, plus the objects FilterByTag keeps for tags.
+func FilterExternal(schemas []SchemaInfo, tags []string) []SchemaInfo {
+	result := FilterByTag(schemas, tags)
+	for i := range result {
+		result[i].Views = schemas[i].Views
+		result[i].MaterializedViews = schemas[i].MaterializedViews
+	}
+	return result
+}
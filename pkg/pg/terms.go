@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"regexp"
+	"strings"
+)
+
+// termPattern matches a "@term SKU: stock keeping unit" line in a comment.
+var termPattern = regexp.MustCompile(`@term[ \t]+([^:\n]+?)[ \t]*:[ \t]*([^\n]*)`)
+
+// Term is a glossary entry defined in a comment.
+type Term struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+// parseTerms strips @term lines from a comment, returning the remaining
+// text and the terms in order of appearance.
+func parseTerms(comment string) (string, []Term) {
+	var terms []Term
+	for _, m := range termPattern.FindAllStringSubmatch(comment, -1) {
+		terms = append(terms, Term{Name: strings.TrimSpace(m[1]), Definition: strings.TrimSpace(m[2])})
+	}
+	if terms == nil {
+		return comment, nil
+	}
+	return strings.TrimSpace(termPattern.ReplaceAllString(comment, "")), terms
+}
+
+// extractTerms moves @term definitions out of every comment in info into
+// the owning object's Terms.
+func extractTerms(info *SchemaInfo) {
+	for i := range info.Tables {
+		t := &info.Tables[i]
+		t.Description, t.Terms = parseTerms(t.Description)
+		extractColumnTerms(t.Columns)
+	}
+	for i := range info.Views {
+		v := &info.Views[i]
+		v.Description, v.Terms = parseTerms(v.Description)
+		extractColumnTerms(v.Columns)
+	}
+	for i := range info.MaterializedViews {
+		mv := &info.MaterializedViews[i]
+		mv.Description, mv.Terms = parseTerms(mv.Description)
+		extractColumnTerms(mv.Columns)
+	}
+	for i := range info.Functions {
+		fn := &info.Functions[i]
+		fn.Description, fn.Terms = parseTerms(fn.Description)
+	}
+	for i := range info.Types {
+		t := &info.Types[i]
+		t.Description, t.Terms = parseTerms(t.Description)
+		extractColumnTerms(t.Attributes)
+	}
+}
+
+func extractColumnTerms(columns []Column) {
+	for i := range columns {
+		columns[i].Description, columns[i].Terms = parseTerms(columns[i].Description)
+	}
+}
@@ -0,0 +1,101 @@
+package pg
+
+import "context"
+
+// Warning kinds.
+const (
+	// WarningSkipped marks an object pgmd does not document, such as a
+	// foreign table or an aggregate.
+	WarningSkipped = "skipped"
+	// WarningUnsupported marks a feature in use that the document does
+	// not show, such as table inheritance or rules.
+	WarningUnsupported = "unsupported"
+	// WarningPermission marks details the connected role cannot read.
+	WarningPermission = "permission"
+)
+
+// Warning records information a schema's document leaves out.
+type Warning struct {
+	Kind string `json:"kind"`
+	// Object is the affected object's name within the schema.
+	Object  string `json:"object"`
+	Message string `json:"message"`
+}
+
+// fetchWarnings lists what the rest of the fetch silently leaves out of
+// schema. Relations dropped by keep are not reported, and neither are
+// extension members, which belong to the extension rather than the
+// schema's design.
+func fetchWarnings(ctx context.Context, conn Querier, schema string, keep func(schema, table string) bool, withValues bool) ([]Warning, error) {
+	query := `
+		SELECT kind, object, is_relation, message FROM (
+			SELECT 'skipped' as kind, c.relname::text as object, true as is_relation,
+				'foreign table is not documented' as message
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1
+			  AND c.relkind = 'f'
+			UNION ALL
+			SELECT 'skipped', p.proname::text, false,
+				CASE p.prokind
+					WHEN 'a' THEN 'aggregate'
+					ELSE 'window function'
+				END || ' is not documented'
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1
+			  AND p.prokind NOT IN ('f', 'p')
+			  AND NOT EXISTS (
+				SELECT 1 FROM pg_depend d
+				WHERE d.classid = 'pg_proc'::regclass
+				  AND d.objid = p.oid
+				  AND d.deptype = 'e')
+			UNION ALL
+			SELECT 'unsupported', c.relname::text, true,
+				'inherits from ' || string_agg(i.inhparent::regclass::text, ', ' ORDER BY i.inhseqno) ||
+				'; table inheritance is not documented'
+			FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1
+			  AND NOT c.relispartition
+			GROUP BY c.relname
+			UNION ALL
+			SELECT 'unsupported', c.relname::text, true,
+				'rule ' || r.rulename || ' is not documented'
+			FROM pg_rewrite r
+			JOIN pg_class c ON c.oid = r.ev_class
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1
+			  AND r.rulename <> '_RETURN'
+			UNION ALL
+			SELECT 'permission', s.sequencename::text, false,
+				'last_value is not readable without SELECT or USAGE on the sequence'
+			FROM pg_sequences s
+			WHERE $2
+			  AND s.schemaname = $1
+			  AND NOT has_sequence_privilege(format('%I.%I', s.schemaname, s.sequencename), 'SELECT, USAGE')
+		) w
+		ORDER BY kind, object, message`
+
+	rows, err := conn.Query(ctx, query, schema, withValues)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warnings []Warning
+	for rows.Next() {
+		var w Warning
+		var isRelation bool
+		if err := rows.Scan(&w.Kind, &w.Object, &isRelation, &w.Message); err != nil {
+			return nil, err
+		}
+		if isRelation && !keep(schema, w.Object) {
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,127 @@
+// Package render defines the interface output formats implement and a
+// registry of them by name, which the -format flag selects from.
+//
+// The markdown, html and json formats are registered by this package.
+// Programs embedding pgmd can add their own with Register.
+package render
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sotirismorf/pgmd/pkg/html"
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+// Renderer writes a schema model in one output format. Options carry the
+// document settings from flags and the config file; formats are free to
+// ignore those that do not apply to them.
+type Renderer interface {
+	Render(w io.Writer, db pg.Database, opts markdown.Options) error
+}
+
+// RendererFunc adapts a function to the Renderer interface.
+type RendererFunc func(w io.Writer, db pg.Database, opts markdown.Options) error
+
+func (f RendererFunc) Render(w io.Writer, db pg.Database, opts markdown.Options) error {
+	return f(w, db, opts)
+}
+
+var (
+	mu        sync.RWMutex
+	renderers = make(map[string]Renderer)
+)
+
+func init() {
+	Register("markdown", RendererFunc(func(w io.Writer, db pg.Database, opts markdown.Options) error {
+		_, err := io.WriteString(w, markdown.RenderDatabase(db, opts))
+		return err
+	}))
+	Register("html", RendererFunc(func(w io.Writer, db pg.Database, opts markdown.Options) error {
+		page, err := html.Render(db, opts)
+		if err != nil {
+			return fmt.Errorf("rendering HTML: %w", err)
+		}
+		_, err = io.WriteString(w, page)
+		return err
+	}))
+	Register("json", RendererFunc(func(w io.Writer, db pg.Database, _ markdown.Options) error {
+		data, err := pg.MarshalDocument(db)
+		if err != nil {
+			return fmt.Errorf("encoding schema: %w", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}))
+}
+
+// Register makes r available as the format name. It panics if name is
+// empty or already registered, like database/sql.Register.
+func Register(name string, r Renderer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" || r == nil {
+		panic("render: Register needs a name and a renderer")
+	}
+	if _, dup := renderers[name]; dup {
+		panic("render: format " + name + " registered twice")
+	}
+	renderers[name] = r
+}
+
+// Lookup returns the renderer registered as name.
+func Lookup(name string) (Renderer, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := renderers[name]
+	return r, ok
+}
+
+// Formats lists the registered format names in sorted order.
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Encoding is how a rendered document is written out: its line endings
+// and whether it starts with a UTF-8 byte order mark, which some
+// Windows-centric wikis and editors expect.
+type Encoding struct {
+	CRLF bool
+	BOM  bool
+}
+
+// ParseNewline validates a -newline flag value, "lf" or "crlf".
+func ParseNewline(input string) (crlf bool, err error) {
+	switch input {
+	case "lf":
+		return false, nil
+	case "crlf":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown newline style %q (want lf or crlf)", input)
+}
+
+// Apply converts doc to e. Line endings already in CRLF are left as is,
+// so applying an encoding twice is harmless.
+func (e Encoding) Apply(doc string) string {
+	if e.CRLF {
+		doc = strings.ReplaceAll(strings.ReplaceAll(doc, "\r\n", "\n"), "\n", "\r\n")
+	}
+	if e.BOM && !strings.HasPrefix(doc, bom) {
+		doc = bom + doc
+	}
+	return doc
+}
+
+const bom = "\uFEFF"
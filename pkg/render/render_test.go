@@ -0,0 +1,113 @@
+package render
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sotirismorf/pgmd/pkg/markdown"
+	"github.com/sotirismorf/pgmd/pkg/pg"
+)
+
+func TestBuiltinFormats(t *testing.T) {
+	db := pg.Database{Schemas: []pg.SchemaInfo{{
+		Name:   "public",
+		Tables: []pg.Table{{Schema: "public", Name: "users", Columns: []pg.Column{{Name: "id", Type: "uuid"}}}},
+	}}}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"markdown", "#### users\n"},
+		{"html", "<!DOCTYPE html>"},
+		{"json", "\"format_version\": 1"},
+	}
+
+	for _, tt := range tests {
+		r, ok := Lookup(tt.format)
+		if !ok {
+			t.Errorf("format %q not registered", tt.format)
+			continue
+		}
+		var sb strings.Builder
+		if err := r.Render(&sb, db, markdown.Options{}); err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+			continue
+		}
+		if !strings.Contains(sb.String(), tt.expected) {
+			t.Errorf("%s output missing %q:\n%s", tt.format, tt.expected, sb.String())
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("names", RendererFunc(func(w io.Writer, db pg.Database, _ markdown.Options) error {
+		for _, s := range db.Schemas {
+			for _, table := range s.Tables {
+				io.WriteString(w, s.Name+"."+table.Name+"\n")
+			}
+		}
+		return nil
+	}))
+
+	if !slices.Contains(Formats(), "names") {
+		t.Errorf("Formats() = %v, want names included", Formats())
+	}
+	if !slices.IsSorted(Formats()) {
+		t.Errorf("Formats() = %v, want sorted", Formats())
+	}
+
+	r, ok := Lookup("names")
+	if !ok {
+		t.Fatal("registered format not found")
+	}
+	var sb strings.Builder
+	db := pg.Database{Schemas: []pg.SchemaInfo{{Name: "public", Tables: []pg.Table{{Schema: "public", Name: "users"}}}}}
+	if err := r.Render(&sb, db, markdown.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "public.users\n" {
+		t.Errorf("Render() = %q", sb.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a format twice did not panic")
+		}
+	}()
+	Register("markdown", r)
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		enc      Encoding
+		input    string
+		expected string
+	}{
+		{Encoding{}, "a\nb\n", "a\nb\n"},
+		{Encoding{CRLF: true}, "a\nb\n", "a\r\nb\r\n"},
+		{Encoding{CRLF: true}, "a\r\nb\n", "a\r\nb\r\n"},
+		{Encoding{BOM: true}, "a\n", "\uFEFFa\n"},
+		{Encoding{CRLF: true, BOM: true}, "\uFEFFa\n", "\uFEFFa\r\n"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.enc.Apply(tt.input); got != tt.expected {
+			t.Errorf("%+v.Apply(%q) = %q, want %q", tt.enc, tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseNewline(t *testing.T) {
+	if crlf, err := ParseNewline("crlf"); err != nil || !crlf {
+		t.Errorf("ParseNewline(crlf) = %v, %v", crlf, err)
+	}
+	if crlf, err := ParseNewline("lf"); err != nil || crlf {
+		t.Errorf("ParseNewline(lf) = %v, %v", crlf, err)
+	}
+	if _, err := ParseNewline("cr"); err == nil {
+		t.Error("ParseNewline(cr) accepted")
+	}
+}